@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type bundleCmd struct {
+	cmd    *cobra.Command
+	output string
+}
+
+func newBundleCmd(root *rootCmd) *bundleCmd {
+	result := &bundleCmd{}
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Transfer all referenced charts to air-gapped environments",
+	}
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write all charts referenced below dir together with their resolved versions into a single tar file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			file, err := os.Create(result.output)
+			if err != nil {
+				return fmt.Errorf("unable to export bundle: %w", err)
+			}
+			index, err := internal.ExportBundle(root.dir, *opts, file)
+			if closeErr := file.Close(); err == nil && closeErr != nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(result.output)
+				return fmt.Errorf("unable to export bundle: %w", err)
+			}
+			for _, chart := range index.Charts {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s (%s)\n", chart.ResolvedChart, chart.ResolvedVersion, chart.Registry)
+			}
+			return nil
+		},
+	}
+	exportCmd.Flags().StringVar(&result.output, "output", "bundle.tar", "file to write the bundle to")
+	_ = exportCmd.MarkFlagFilename("output", "tar")
+
+	importCmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Seed the cache directory with the charts of a bundle, so they are used instead of their registries",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("unable to import bundle: %w", err)
+			}
+			defer file.Close()
+			index, err := internal.ImportBundle(file, *opts)
+			if err != nil {
+				return fmt.Errorf("unable to import bundle: %w", err)
+			}
+			for _, chart := range index.Charts {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s (%s)\n", chart.ResolvedChart, chart.ResolvedVersion, chart.Registry)
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(exportCmd)
+	cmd.AddCommand(importCmd)
+	result.cmd = cmd
+	return result
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type checkCmd struct {
+	cmd   *cobra.Command
+	diff  diffFormatFlags
+	sarif string
+}
+
+func newCheckCmd(root *rootCmd) *checkCmd {
+	result := &checkCmd{}
+	cmd := &cobra.Command{
+		Use:   "check [dir...]",
+		Short: "Fail if the committed output differs from a fresh render",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := result.diff.validate()
+			if err != nil {
+				return err
+			}
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			if result.sarif != "" {
+				opts.Findings = internal.NewValidationFindings()
+			}
+			dirs := args
+			if len(dirs) == 0 {
+				dirs = []string{root.dir}
+			}
+			out := cmd.OutOrStdout()
+			drifted := 0
+			for _, dir := range dirs {
+				changes, err := internal.CompareSnapshot(dir, false, *opts)
+				if err != nil {
+					if result.sarif != "" {
+						if err := writeSarifFile(result.sarif, opts.Findings, root.version.Version); err != nil {
+							return err
+						}
+					}
+					return fmt.Errorf("unable to check %s: %w", dir, err)
+				}
+				if len(changes) > 0 {
+					drifted++
+				}
+				if result.diff.format == "fields" {
+					fmt.Fprint(out, internal.FormatFileChangesFields(dir, changes, result.diff.useColor(out)))
+				} else {
+					fmt.Fprint(out, internal.FormatFileChanges(dir, changes))
+				}
+				if internal.GithubActionsEnabled() {
+					fmt.Fprint(out, internal.GithubDriftAnnotations(dir, changes))
+				}
+			}
+			if result.sarif != "" {
+				if err := writeSarifFile(result.sarif, opts.Findings, root.version.Version); err != nil {
+					return err
+				}
+			}
+			if drifted > 0 {
+				return internal.ClassifiedError{Class: internal.ErrorClassDrift, Err: fmt.Errorf("%d of %d directories differ from their configuration, regenerate them", drifted, len(dirs))}
+			}
+			return nil
+		},
+	}
+
+	result.diff.addFlags(cmd, "fields")
+	cmd.Flags().StringVar(&result.sarif, "sarif", "", "write validation and policy findings as sarif to this file")
+
+	result.cmd = cmd
+	return result
+}
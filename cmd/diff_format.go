@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+type diffFormatFlags struct {
+	format string
+	color  string
+}
+
+func (f *diffFormatFlags) addFlags(cmd *cobra.Command, format string) {
+	cmd.Flags().StringVar(&f.format, "diff-format", format, "diff format (fields for per resource field changes, unified for line diffs)")
+	cmd.Flags().StringVar(&f.color, "color", "auto", "colorize diffs (auto, always or never)")
+	_ = cmd.RegisterFlagCompletionFunc("diff-format", cobra.FixedCompletions([]string{"fields", "unified"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("color", cobra.FixedCompletions([]string{"auto", "always", "never"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func (f *diffFormatFlags) validate() error {
+	if f.format != "fields" && f.format != "unified" {
+		return fmt.Errorf("unsupported diff format %s", f.format)
+	}
+	if f.color != "auto" && f.color != "always" && f.color != "never" {
+		return fmt.Errorf("unsupported color mode %s", f.color)
+	}
+	return nil
+}
+
+func (f *diffFormatFlags) useColor(out io.Writer) bool {
+	switch f.color {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
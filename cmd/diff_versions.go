@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type diffVersionsCmd struct {
+	cmd  *cobra.Command
+	from string
+	diff diffFormatFlags
+}
+
+func newDiffVersionsCmd(root *rootCmd) *diffVersionsCmd {
+	result := &diffVersionsCmd{}
+	cmd := &cobra.Command{
+		Use:   "diff-versions <candidate-version>",
+		Short: "Show how the generated resources change when upgrading the chart to another version",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			opts, err := root.runOptions(cmd)
+			if err != nil || len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return internal.CompleteConfiguredChartVersions(root.dir, *opts), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := result.diff.validate()
+			if err != nil {
+				return err
+			}
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			diffs, err := internal.DiffVersions(root.dir, result.from, args[0], *opts)
+			if err != nil {
+				return fmt.Errorf("unable to diff versions: %w", err)
+			}
+			out := cmd.OutOrStdout()
+			if result.diff.format == "fields" {
+				fmt.Fprint(out, internal.FormatResourceDiffs(diffs, result.diff.useColor(out)))
+			} else {
+				for _, diff := range diffs {
+					fmt.Fprintf(out, "=== %s (%s)\n%s", diff.Resource, diff.Change, diff.Diff)
+				}
+			}
+			if len(diffs) == 0 {
+				fmt.Fprintln(out, "no changes")
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&result.from, "from", "", "version to compare against (defaults to the configured version)")
+
+	result.diff.addFlags(cmd, "fields")
+
+	result.cmd = cmd
+	return result
+}
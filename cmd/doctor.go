@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type doctorCmd struct {
+	cmd *cobra.Command
+}
+
+func newDoctorCmd(root *rootCmd) *doctorCmd {
+	result := &doctorCmd{}
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose helm, registry access, credentials, cache and permissions for all configurations below dir",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			findings, err := internal.Doctor(root.dir, *opts)
+			if err != nil {
+				return fmt.Errorf("unable to diagnose %s: %w", root.dir, err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), internal.FormatDoctorFindings(findings))
+			if errors := internal.CountDoctorFindings(findings, internal.DoctorStatusError); errors > 0 {
+				return internal.ClassifiedError{Class: internal.ErrorClassValidation, Err: fmt.Errorf("%d problems found", errors)}
+			}
+			return nil
+		},
+	}
+
+	result.cmd = cmd
+	return result
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type explainValuesCmd struct {
+	cmd *cobra.Command
+}
+
+func newExplainValuesCmd(root *rootCmd) *explainValuesCmd {
+	result := &explainValuesCmd{}
+	cmd := &cobra.Command{
+		Use:   "explain-values [key...]",
+		Short: "Show which source supplied each effective value of the configured charts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			charts, err := internal.ExplainValues(root.dir, *opts)
+			if err != nil {
+				return fmt.Errorf("unable to explain values for %s: %w", root.dir, err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), internal.FormatValueOrigins(internal.FilterValueOrigins(charts, args)))
+			return nil
+		},
+	}
+
+	result.cmd = cmd
+	return result
+}
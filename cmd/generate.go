@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type generateCmd struct {
+	cmd         *cobra.Command
+	output      string
+	recursive   bool
+	gitCommit   bool
+	metrics     string
+	summary     bool
+	stats       bool
+	interval    time.Duration
+	metricsAddr string
+	sarif       string
+	keepGoing   bool
+}
+
+func newGenerateCmd(root *rootCmd) *generateCmd {
+	result := &generateCmd{}
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate the kustomization (default command)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return result.run(root, cmd)
+		},
+	}
+	result.addFlags(cmd)
+
+	result.cmd = cmd
+	return result
+}
+
+func (g *generateCmd) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&g.output, "output", "", "write all rendered resources as one yaml stream to stdout instead of the dir (use -)")
+	cmd.Flags().BoolVar(&g.gitCommit, "git-commit", false, "stage the changed output and commit it with a message describing the chart version changes")
+	cmd.Flags().BoolVar(&g.recursive, "recursive", false, "regenerate every configuration found below dir")
+	cmd.Flags().BoolVar(&g.keepGoing, "keep-going", false, "with --recursive, continue after a configuration failed and report the status of every configuration at the end")
+	cmd.Flags().StringVar(&g.metrics, "metrics-file", "", "write per phase timings as json to this file")
+	cmd.Flags().BoolVar(&g.summary, "summary", false, "print a table of the generation durations per dir to stderr")
+	cmd.Flags().BoolVar(&g.stats, "stats", false, "print a table of the rendered resources and changes per chart to stderr")
+	cmd.Flags().StringVar(&g.sarif, "sarif", "", "write validation and policy findings as sarif to this file")
+	cmd.Flags().DurationVar(&g.interval, "interval", 0, "keep running and regenerate with this interval until interrupted")
+	cmd.Flags().StringVar(&g.metricsAddr, "metrics-addr", "", "serve prometheus metrics on this address (like :9090) while running with --interval")
+}
+
+func (g *generateCmd) run(root *rootCmd, cmd *cobra.Command) error {
+	opts, err := root.runOptions(cmd)
+	if err != nil {
+		return err
+	}
+	opts.Output = g.output
+	opts.Stdout = cmd.OutOrStdout()
+	opts.GitCommit = g.gitCommit
+	if g.metrics != "" || g.summary {
+		opts.Metrics = internal.NewMetrics()
+	}
+	if g.stats {
+		opts.Stats = internal.NewOutputStats()
+	}
+	if g.sarif != "" {
+		opts.Findings = internal.NewValidationFindings()
+	}
+
+	findDirs := func() ([]string, error) {
+		if !g.recursive {
+			return []string{root.dir}, nil
+		}
+		dirs, err := internal.FindConfigDirs(root.dir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find configurations: %w", err)
+		}
+		return dirs, nil
+	}
+	if g.interval > 0 {
+		return g.watch(cmd, *opts, findDirs, root.version.Version)
+	}
+	if g.metricsAddr != "" {
+		return fmt.Errorf("--metrics-addr requires --interval")
+	}
+	if g.keepGoing && !g.recursive {
+		return fmt.Errorf("--keep-going requires --recursive")
+	}
+	dirs, err := findDirs()
+	if err != nil {
+		return err
+	}
+	results, err := internal.RunBatch(dirs, *opts, g.keepGoing)
+	if g.keepGoing {
+		internal.WriteBatchResults(cmd.ErrOrStderr(), results)
+	}
+	if err := g.writeReports(cmd, *opts, root.version.Version); err != nil {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("unable to run: %w", err)
+	}
+	return nil
+}
+
+func (g *generateCmd) writeReports(cmd *cobra.Command, opts internal.RunOptions, version string) error {
+	if g.sarif != "" {
+		if err := writeSarifFile(g.sarif, opts.Findings, version); err != nil {
+			return err
+		}
+	}
+	if g.metrics != "" {
+		if err := opts.Metrics.WriteFile(g.metrics); err != nil {
+			return fmt.Errorf("unable to write metrics: %w", err)
+		}
+	}
+	if g.summary {
+		w := tabwriter.NewWriter(cmd.ErrOrStderr(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DIR\tDURATION\tSLOWEST PHASE")
+		for _, dir := range opts.Metrics.SlowestDirs() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", dir.Dir, time.Duration(dir.Duration*float64(time.Second)).Round(time.Millisecond), dir.SlowestPhase)
+		}
+		w.Flush()
+	}
+	if g.stats {
+		opts.Stats.Write(cmd.ErrOrStderr())
+	}
+	return nil
+}
+
+func (g *generateCmd) watch(cmd *cobra.Command, opts internal.RunOptions, findDirs func() ([]string, error), version string) error {
+	opts.Generations = internal.NewGenerationMetrics()
+	if g.metricsAddr != "" {
+		listener, err := net.Listen("tcp", g.metricsAddr)
+		if err != nil {
+			return fmt.Errorf("unable to serve metrics: %w", err)
+		}
+		server := &http.Server{Handler: opts.Generations, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			_ = server.Serve(listener)
+		}()
+		defer server.Close()
+		opts.Logger.Info("serving metrics", "addr", listener.Addr().String())
+	}
+	for {
+		opts.IndexCache = internal.NewHelmIndexCache()
+		if opts.Metrics != nil {
+			opts.Metrics = internal.NewMetrics()
+		}
+		if opts.Stats != nil {
+			opts.Stats = internal.NewOutputStats()
+		}
+		if opts.Findings != nil {
+			opts.Findings = internal.NewValidationFindings()
+		}
+		dirs, err := findDirs()
+		if err != nil {
+			opts.Logger.Error("finding configurations failed", "error", err)
+		}
+		for _, dir := range dirs {
+			if cmd.Context().Err() != nil {
+				break
+			}
+			if err := internal.Run(dir, opts); err != nil {
+				opts.Logger.Error("generation failed", "dir", dir, "error", err)
+			}
+		}
+		if err := g.writeReports(cmd, opts, version); err != nil {
+			opts.Logger.Error("writing reports failed", "error", err)
+		}
+		select {
+		case <-cmd.Context().Done():
+			return nil
+		case <-time.After(g.interval):
+		}
+	}
+}
+
+func writeSarifFile(file string, findings *internal.ValidationFindings, version string) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("unable to write sarif: %w", err)
+	}
+	err = findings.WriteSarif(out, version)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write sarif: %w", err)
+	}
+	return nil
+}
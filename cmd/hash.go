@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type hashCmd struct {
+	cmd *cobra.Command
+}
+
+func newHashCmd(root *rootCmd) *hashCmd {
+	result := &hashCmd{}
+	cmd := &cobra.Command{
+		Use:   "hash [dir...]",
+		Short: "Print a deterministic digest of the generated output",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dirs := args
+			if len(dirs) == 0 {
+				dirs = []string{root.dir}
+			}
+			out := cmd.OutOrStdout()
+			for _, dir := range dirs {
+				digest, err := internal.HashDir(dir)
+				if err != nil {
+					return fmt.Errorf("unable to hash %s: %w", dir, err)
+				}
+				fmt.Fprintf(out, "%s  %s\n", digest, dir)
+			}
+			return nil
+		},
+	}
+
+	result.cmd = cmd
+	return result
+}
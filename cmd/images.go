@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type imagesCmd struct {
+	cmd    *cobra.Command
+	report string
+}
+
+func newImagesCmd(root *rootCmd) *imagesCmd {
+	result := &imagesCmd{}
+	cmd := &cobra.Command{
+		Use:   "images",
+		Short: "List all container images referenced by the generated resources",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			images, err := internal.ListImages(root.dir, *opts)
+			if err != nil {
+				return fmt.Errorf("unable to list images: %w", err)
+			}
+			if result.report != "" {
+				bytes, err := yaml.Marshal(images)
+				if err != nil {
+					return fmt.Errorf("unable to write image report: %w", err)
+				}
+				err = os.WriteFile(result.report, bytes, 0o644)
+				if err != nil {
+					return fmt.Errorf("unable to write image report: %w", err)
+				}
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DIR\tCHART\tREPOSITORY\tTAG\tDIGEST")
+			for _, image := range images {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", image.Dir, image.Chart, image.Repository, image.Tag, image.Digest)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&result.report, "report", "", "additionally write the image inventory as yaml to this file")
+
+	result.cmd = cmd
+	return result
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type initCmd struct {
+	cmd      *cobra.Command
+	options  internal.InitOptions
+	generate bool
+}
+
+func newInitCmd(root *rootCmd) *initCmd {
+	result := &initCmd{}
+	cmd := &cobra.Command{
+		Use:   "init [dir]",
+		Short: "Scaffold a helm generator configuration with the chart's default values",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := root.dir
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			if result.options.Chart == "" {
+				err := promptInitOptions(cmd.InOrStdin(), cmd.ErrOrStderr(), &result.options)
+				if err != nil {
+					return err
+				}
+			}
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			file, err := internal.InitHelm(dir, result.options, *opts)
+			if err != nil {
+				return fmt.Errorf("unable to initialize %s: %w", dir, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s written\n", file)
+			if !result.generate {
+				return nil
+			}
+			return internal.Run(dir, *opts)
+		},
+	}
+	cmd.Flags().StringVar(&result.options.Registry, "registry", "", "chart registry (https://, oci://, s3://, gs:// or a repository alias)")
+	cmd.Flags().StringVar(&result.options.Chart, "chart", "", "chart name")
+	cmd.Flags().StringVar(&result.options.Version, "version", "", "chart version or constraint (defaults to the newest stable version)")
+	cmd.Flags().StringVar(&result.options.Name, "name", "", "release name (defaults to the chart name)")
+	cmd.Flags().StringVar(&result.options.Namespace, "namespace", "", "release namespace")
+	cmd.Flags().BoolVar(&result.options.Force, "force", false, "overwrite an existing configuration")
+	cmd.Flags().BoolVar(&result.generate, "generate", true, "generate the kustomization right away")
+	_ = cmd.RegisterFlagCompletionFunc("chart", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		opts, err := root.runOptions(cmd)
+		if err != nil || result.options.Registry == "" {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return internal.CompleteChartNames(root.dir, *opts, result.options.Registry), cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("version", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		opts, err := root.runOptions(cmd)
+		if err != nil || result.options.Chart == "" {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return internal.CompleteChartVersions(root.dir, *opts, result.options.Registry, result.options.Chart), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	})
+
+	result.cmd = cmd
+	return result
+}
+
+func promptInitOptions(in io.Reader, out io.Writer, options *internal.InitOptions) error {
+	reader := bufio.NewReader(in)
+	prompts := []struct {
+		label    string
+		value    *string
+		required bool
+	}{
+		{"Registry (empty for a local chart)", &options.Registry, false},
+		{"Chart", &options.Chart, true},
+		{"Version (empty for newest)", &options.Version, false},
+		{"Release name (empty for chart name)", &options.Name, false},
+		{"Namespace", &options.Namespace, false},
+	}
+	for _, prompt := range prompts {
+		if *prompt.value != "" {
+			continue
+		}
+		fmt.Fprintf(out, "%s: ", prompt.label)
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		*prompt.value = strings.TrimSpace(line)
+		if prompt.required && *prompt.value == "" {
+			return fmt.Errorf("%s missing", strings.ToLower(prompt.label))
+		}
+	}
+	return nil
+}
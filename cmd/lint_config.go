@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type lintConfigCmd struct {
+	cmd *cobra.Command
+}
+
+func newLintConfigCmd(root *rootCmd) *lintConfigCmd {
+	result := &lintConfigCmd{}
+	cmd := &cobra.Command{
+		Use:   "lint-config [dir...]",
+		Short: "Strictly check generator configurations without rendering them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			dirs := args
+			if len(dirs) == 0 {
+				dirs, err = internal.FindConfigDirs(root.dir)
+				if err != nil {
+					return fmt.Errorf("unable to find configurations in %s: %w", root.dir, err)
+				}
+			}
+			problems := 0
+			for _, dir := range dirs {
+				findings, err := internal.LintConfig(dir, *opts)
+				if err != nil {
+					return fmt.Errorf("unable to lint %s: %w", dir, err)
+				}
+				for _, finding := range findings {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", dir, finding)
+				}
+				problems += len(findings)
+			}
+			if problems > 0 {
+				return internal.ClassifiedError{Class: internal.ErrorClassConfig, Err: fmt.Errorf("%d problems found", problems)}
+			}
+			return nil
+		},
+	}
+
+	result.cmd = cmd
+	return result
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type reportCmd struct {
+	cmd    *cobra.Command
+	output string
+}
+
+func newReportCmd(root *rootCmd) *reportCmd {
+	result := &reportCmd{}
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "List which charts and versions are generated where below dir, without any network calls",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := internal.BuildUsageReport(root.dir)
+			if err != nil {
+				return fmt.Errorf("unable to build usage report: %w", err)
+			}
+			if result.output != "" {
+				bytes, err := yaml.Marshal(report)
+				if err != nil {
+					return fmt.Errorf("unable to write usage report: %w", err)
+				}
+				err = os.WriteFile(result.output, bytes, 0o644)
+				if err != nil {
+					return fmt.Errorf("unable to write usage report: %w", err)
+				}
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHART\tVERSION\tREGISTRY\tDIRS")
+			for _, chart := range report.Charts {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", chart.Chart, chart.Version, chart.Registry, strings.Join(chart.Dirs, ", "))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&result.output, "output", "", "additionally write the usage report as yaml to this file")
+
+	result.cmd = cmd
+	return result
+}
@@ -1,47 +1,162 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"runtime/debug"
+	"syscall"
 
 	"github.com/airfocusio/kustomization-generator/internal"
 	"github.com/spf13/cobra"
 )
 
 type rootCmd struct {
-	cmd *cobra.Command
-	dir string
+	cmd          *cobra.Command
+	dir          string
+	logFormat    string
+	verbose      bool
+	progress     bool
+	helmBin      string
+	cacheDir     string
+	tempDir      string
+	verifyImages bool
+	renderCache  bool
+	bundled      bool
+	strict       bool
+	configFile   string
+	repositories string
+	environment  string
+	version      FullVersion
 }
 
 func newRootCmd(version FullVersion) *rootCmd {
 	result := &rootCmd{}
+	generate := newGenerateCmd(result)
 	cmd := &cobra.Command{
 		Version:      version.Version,
 		Use:          "kustomization-generator",
 		Short:        "An converter from helm charts to kustomizations",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			dir := (*result).dir
-			if dir == "" {
-				return fmt.Errorf("dir missing")
-			}
-			err := internal.Run(dir)
-			if err != nil {
-				return fmt.Errorf("unable to run: %v", err)
-			}
-			return nil
+			return generate.run(result, cmd)
 		},
 	}
 
 	cmd.PersistentFlags().StringVar(&result.dir, "dir", ".", "dir")
+	generate.addFlags(cmd)
+	cmd.PersistentFlags().StringVar(&result.configFile, "config", "", "configuration file to use instead of the one in dir (use - to read from stdin)")
+	cmd.PersistentFlags().StringVar(&result.repositories, "repositories", "", "repositories file defining registry aliases (defaults to the nearest repositories.yaml up to the git root)")
+	cmd.PersistentFlags().StringVar(&result.environment, "environment", "", "environment overlay from the configuration to render with")
+	cmd.PersistentFlags().StringVar(&result.logFormat, "log-format", "text", "log format (text or json)")
+	cmd.PersistentFlags().BoolVarP(&result.verbose, "verbose", "v", false, "log progress events in addition to warnings and errors")
+	cmd.PersistentFlags().StringVar(&result.helmBin, "helm-bin", "", "helm executable to use (defaults to helm from PATH)")
+	cmd.PersistentFlags().StringVar(&result.cacheDir, "cache-dir", "", "cache directory (defaults to the user cache directory)")
+	cmd.PersistentFlags().StringVar(&result.tempDir, "temp-dir", os.Getenv("KUSTOMIZATION_GENERATOR_TEMP_DIR"), "directory for temporary values files and charts (defaults to $KUSTOMIZATION_GENERATOR_TEMP_DIR or the system temp directory)")
+	cmd.PersistentFlags().BoolVar(&result.renderCache, "render-cache", false, "restore helm renders with unchanged inputs from the cache directory")
+	cmd.PersistentFlags().BoolVar(&result.bundled, "bundled-charts", false, "use charts imported with bundle import instead of contacting their registry")
+	cmd.PersistentFlags().BoolVar(&result.strict, "strict", false, "fail instead of warning about deprecated charts")
+	cmd.PersistentFlags().BoolVar(&result.verifyImages, "verify-images", false, "fail if any referenced container image does not exist in its registry")
+	cmd.PersistentFlags().BoolVar(&result.progress, "progress", false, "show per phase progress with timings")
 
+	_ = cmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("environment", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		opts, err := result.runOptions(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return internal.CompleteEnvironments(result.dir, *opts), cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.MarkPersistentFlagDirname("dir")
+	_ = cmd.MarkPersistentFlagDirname("cache-dir")
+	_ = cmd.MarkPersistentFlagDirname("temp-dir")
+
+	result.version = version
 	result.cmd = cmd
+	cmd.AddCommand(generate.cmd)
+	cmd.AddCommand(newImagesCmd(result).cmd)
+	cmd.AddCommand(newDiffVersionsCmd(result).cmd)
+	cmd.AddCommand(newTestCmd(result).cmd)
+	cmd.AddCommand(newCheckCmd(result).cmd)
+	cmd.AddCommand(newHashCmd(result).cmd)
+	cmd.AddCommand(newVerifyCmd(result).cmd)
+	cmd.AddCommand(newInitCmd(result).cmd)
+	cmd.AddCommand(newSelfUpdateCmd(result).cmd)
+	cmd.AddCommand(newDoctorCmd(result).cmd)
+	cmd.AddCommand(newLintConfigCmd(result).cmd)
+	cmd.AddCommand(newValuesDocsCmd(result).cmd)
+	cmd.AddCommand(newExplainValuesCmd(result).cmd)
+	cmd.AddCommand(newValuesDiffCmd(result).cmd)
+	cmd.AddCommand(newReportCmd(result).cmd)
+	cmd.AddCommand(newBundleCmd(result).cmd)
 	return result
 }
 
+func (r *rootCmd) runOptions(cmd *cobra.Command) (*internal.RunOptions, error) {
+	if r.dir == "" {
+		return nil, fmt.Errorf("dir missing")
+	}
+	logger, err := internal.NewLogger(os.Stderr, r.logFormat, r.verbose || cmd.Flags().Changed("log-format"))
+	if err != nil {
+		return nil, err
+	}
+	userConfig := &internal.UserConfig{}
+	if userConfigFile, err := internal.DefaultUserConfigFile(); err == nil {
+		userConfig, err = internal.LoadUserConfig(userConfigFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	helmBin := r.helmBin
+	if helmBin == "" {
+		helmBin = userConfig.HelmBin
+	}
+	cacheDir := r.cacheDir
+	if cacheDir == "" {
+		cacheDir = userConfig.CacheDir
+	}
+	tempDir := r.tempDir
+	if tempDir == "" {
+		tempDir = userConfig.TempDir
+	}
+	if tempDir != "" {
+		tempDir, err = filepath.Abs(tempDir)
+		if err == nil {
+			err = os.MkdirAll(tempDir, 0o700)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to prepare temp dir: %w", err)
+		}
+	}
+	opts := internal.RunOptions{Context: cmd.Context(), Logger: logger, HelmBin: helmBin, CacheDir: cacheDir, TempDir: tempDir, UserConfig: userConfig, ToolVersion: r.version.Version, VerifyImages: r.verifyImages, ConfigFile: r.configFile, RepositoriesFile: r.repositories, Environment: r.environment, Stdin: cmd.InOrStdin(), RenderCache: r.renderCache, BundledCharts: r.bundled, Strict: r.strict, IndexCache: internal.NewHelmIndexCache()}
+	if r.progress {
+		opts.Progress = internal.NewProgressReporter(os.Stderr)
+	}
+	return &opts, nil
+}
+
+func ExitCode(err error) int {
+	return internal.ExitCode(err)
+}
+
 func Execute(version FullVersion) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 	rootCmd := newRootCmd(version)
-	return rootCmd.cmd.Execute()
+	err := rootCmd.cmd.ExecuteContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		err = internal.ClassifiedError{Class: internal.ErrorClassInterrupted, Err: err}
+	}
+	if err != nil && internal.GithubActionsEnabled() {
+		fmt.Fprint(rootCmd.cmd.OutOrStdout(), internal.GithubErrorAnnotation(err))
+	}
+	return err
 }
 
 type FullVersion struct {
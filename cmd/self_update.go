@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type selfUpdateCmd struct {
+	cmd     *cobra.Command
+	options internal.SelfUpdateOptions
+}
+
+func newSelfUpdateCmd(root *rootCmd) *selfUpdateCmd {
+	result := &selfUpdateCmd{}
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Replace the running executable with the latest release",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("unable to locate executable: %w", err)
+			}
+			executable, err = filepath.EvalSymlinks(executable)
+			if err != nil {
+				return fmt.Errorf("unable to locate executable: %w", err)
+			}
+			result.options.CurrentVersion = root.version.Version
+			result.options.Executable = executable
+			update, err := internal.SelfUpdate(result.options, *opts)
+			if err != nil {
+				return fmt.Errorf("unable to update: %w", err)
+			}
+			out := cmd.OutOrStdout()
+			switch {
+			case update.Updated:
+				fmt.Fprintf(out, "updated to %s\n", update.LatestVersion)
+			case update.Available:
+				fmt.Fprintf(out, "%s is available (current %s)\n", update.LatestVersion, root.version.Version)
+			default:
+				fmt.Fprintf(out, "already up to date (%s)\n", root.version.Version)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&result.options.CheckOnly, "check", false, "only check whether a newer release is available")
+	cmd.Flags().BoolVar(&result.options.Force, "force", false, "update even if the current version is not older than the latest release")
+	cmd.Flags().StringVar(&result.options.Key, "key", "", "cosign public key to verify the signature of the release checksums with")
+
+	result.cmd = cmd
+	return result
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type testCmd struct {
+	cmd    *cobra.Command
+	update bool
+}
+
+func newTestCmd(root *rootCmd) *testCmd {
+	result := &testCmd{}
+	cmd := &cobra.Command{
+		Use:   "test [dir...]",
+		Short: "Render configurations and compare them against the committed output",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			dirs := args
+			if len(dirs) == 0 {
+				dirs = []string{root.dir}
+			}
+			out := cmd.OutOrStdout()
+			failed := []string{}
+			for _, dir := range dirs {
+				changes, err := internal.CompareSnapshot(dir, result.update, *opts)
+				if err != nil {
+					return fmt.Errorf("unable to test %s: %w", dir, err)
+				}
+				if len(changes) == 0 {
+					fmt.Fprintf(out, "ok      %s\n", dir)
+					continue
+				}
+				if result.update {
+					fmt.Fprintf(out, "updated %s\n", dir)
+					continue
+				}
+				fmt.Fprintf(out, "FAIL    %s\n", dir)
+				for _, change := range changes {
+					fmt.Fprintf(out, "        %s %s\n", change.Change, change.File)
+				}
+				failed = append(failed, dir)
+			}
+			if len(failed) > 0 {
+				return internal.ClassifiedError{Class: internal.ErrorClassValidation, Err: fmt.Errorf("%d of %d snapshots do not match, rerun with --update to refresh them", len(failed), len(dirs))}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&result.update, "update", false, "update the committed output instead of failing")
+
+	result.cmd = cmd
+	return result
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type valuesDiffCmd struct {
+	cmd *cobra.Command
+}
+
+func newValuesDiffCmd(root *rootCmd) *valuesDiffCmd {
+	result := &valuesDiffCmd{}
+	cmd := &cobra.Command{
+		Use:   "values-diff",
+		Short: "Show which configured values differ from or are missing in the chart's default values",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			charts, err := internal.ValuesDiff(root.dir, *opts)
+			if err != nil {
+				return fmt.Errorf("unable to diff values for %s: %w", root.dir, err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), internal.FormatValuesDiff(charts))
+			return nil
+		},
+	}
+
+	result.cmd = cmd
+	return result
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type valuesDocsCmd struct {
+	cmd   *cobra.Command
+	write bool
+}
+
+func newValuesDocsCmd(root *rootCmd) *valuesDocsCmd {
+	result := &valuesDocsCmd{}
+	cmd := &cobra.Command{
+		Use:   "values-docs",
+		Short: "Show the default values and documented parameters of the configured charts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			docs, err := internal.ValuesDocs(root.dir, *opts)
+			if err != nil {
+				return fmt.Errorf("unable to extract values docs for %s: %w", root.dir, err)
+			}
+			if !result.write {
+				fmt.Fprint(cmd.OutOrStdout(), internal.FormatValuesDocs(docs))
+				return nil
+			}
+			file, err := internal.WriteValuesDocs(root.dir, docs)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s written\n", file)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&result.write, "write", false, "write the docs to VALUES.md beside the configuration instead of printing them")
+
+	result.cmd = cmd
+	return result
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+	"github.com/spf13/cobra"
+)
+
+type verifyCmd struct {
+	cmd    *cobra.Command
+	digest string
+}
+
+func newVerifyCmd(root *rootCmd) *verifyCmd {
+	result := &verifyCmd{}
+	cmd := &cobra.Command{
+		Use:   "verify [dir...]",
+		Short: "Fail if a fresh render does not reproduce the digest of the generated output",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := root.runOptions(cmd)
+			if err != nil {
+				return err
+			}
+			dirs := args
+			if len(dirs) == 0 {
+				dirs = []string{root.dir}
+			}
+			if result.digest != "" && len(dirs) > 1 {
+				return fmt.Errorf("--digest can only be used with a single directory")
+			}
+			out := cmd.OutOrStdout()
+			for _, dir := range dirs {
+				digest, err := internal.VerifyDir(dir, result.digest, *opts)
+				if err != nil {
+					return fmt.Errorf("unable to verify %s: %w", dir, err)
+				}
+				fmt.Fprintf(out, "%s  %s\n", digest, dir)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&result.digest, "digest", "", "expected digest (defaults to the digest of the committed output)")
+
+	result.cmd = cmd
+	return result
+}
@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+
+	"github.com/airfocusio/kustomization-generator/internal"
+)
+
+type Resource = internal.GeneratorResource
+
+type Kustomization = internal.Kustomization
+
+type Spec struct {
+	Dir         string
+	Config      []byte
+	Environment string
+	HelmBin     string
+	CacheDir    string
+	Logger      *slog.Logger
+}
+
+func Generate(ctx context.Context, spec Spec) ([]Resource, Kustomization, error) {
+	dir := spec.Dir
+	if dir == "" {
+		dir = "."
+	}
+	opts := internal.RunOptions{
+		Context:     ctx,
+		Logger:      spec.Logger,
+		HelmBin:     spec.HelmBin,
+		CacheDir:    spec.CacheDir,
+		Environment: spec.Environment,
+	}
+	if spec.Config != nil {
+		opts.ConfigFile = "-"
+		opts.Stdin = bytes.NewReader(spec.Config)
+	}
+	resources, kustomization, err := internal.GenerateInMemory(dir, opts)
+	if err != nil {
+		return nil, Kustomization{}, err
+	}
+	return resources, *kustomization, nil
+}
@@ -4,6 +4,7 @@ go 1.21
 
 require (
 	github.com/airfocusio/go-expandenv v0.1.0
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/spf13/cobra v1.7.0
 	github.com/stretchr/testify v1.7.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+func GithubActionsEnabled() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+func GithubErrorAnnotation(err error) string {
+	classified := ClassifiedError{}
+	if errors.As(err, &classified) && classified.File != "" && classified.File != "-" {
+		return formatGithubAnnotation("error", filepath.ToSlash(classified.File), err.Error())
+	}
+	return formatGithubAnnotation("error", "", err.Error())
+}
+
+func GithubDriftAnnotations(dir string, changes []FileChange) string {
+	result := strings.Builder{}
+	for _, change := range changes {
+		file := path.Join(filepath.ToSlash(dir), change.File)
+		result.WriteString(formatGithubAnnotation("error", file, fmt.Sprintf("File is %s compared to a fresh render of its configuration, regenerate it", map[string]string{
+			"added":   "missing",
+			"removed": "stale",
+			"changed": "outdated",
+		}[change.Change])))
+	}
+	return result.String()
+}
+
+func formatGithubAnnotation(level string, file string, message string) string {
+	escapeData := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	escapeProperty := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	if file == "" {
+		return fmt.Sprintf("::%s::%s\n", level, escapeData.Replace(message))
+	}
+	return fmt.Sprintf("::%s file=%s::%s\n", level, escapeProperty.Replace(file), escapeData.Replace(message))
+}
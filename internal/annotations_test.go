@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGithubErrorAnnotation(t *testing.T) {
+	assert.Equal(t, "::error::executing helm failed%0Adetails\n", GithubErrorAnnotation(executionErrorf("executing helm failed\ndetails")))
+	err := fmt.Errorf("unable to run: %w", ClassifiedError{Class: ErrorClassConfig, File: "vendors/app/kustomization-generator.yaml", Err: fmt.Errorf("100%% broken")})
+	assert.Equal(t, "::error file=vendors/app/kustomization-generator.yaml::unable to run: 100%25 broken\n", GithubErrorAnnotation(err))
+}
+
+func TestGithubDriftAnnotations(t *testing.T) {
+	assert.Equal(t,
+		"::error file=vendors/app/kustomization.yaml::File is outdated compared to a fresh render of its configuration, regenerate it\n"+
+			"::error file=vendors/app/stale.yaml::File is stale compared to a fresh render of its configuration, regenerate it\n",
+		GithubDriftAnnotations("vendors/app", []FileChange{
+			{File: "kustomization.yaml", Change: "changed"},
+			{File: "stale.yaml", Change: "removed"},
+		}))
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type BatchResult struct {
+	Dir      string
+	Charts   string
+	Duration time.Duration
+	Err      error
+}
+
+type BatchError struct {
+	Results []BatchResult
+}
+
+func (e BatchError) failed() []BatchResult {
+	result := []BatchResult{}
+	for _, r := range e.Results {
+		if r.Err != nil {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func (e BatchError) Error() string {
+	failed := e.failed()
+	lines := []string{fmt.Sprintf("%d of %d configurations failed", len(failed), len(e.Results))}
+	for _, r := range failed {
+		lines = append(lines, fmt.Sprintf("%s: %v", r.Dir, r.Err))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e BatchError) Unwrap() []error {
+	result := []error{}
+	for _, r := range e.failed() {
+		result = append(result, r.Err)
+	}
+	return result
+}
+
+func RunBatch(dirs []string, opts RunOptions, keepGoing bool) ([]BatchResult, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = discardLogger()
+	}
+	results := []BatchResult{}
+	failed := false
+	for _, dir := range dirs {
+		start := time.Now()
+		err := Run(dir, opts)
+		results = append(results, BatchResult{Dir: dir, Charts: strings.Join(configuredChartNames(dir, opts), ","), Duration: time.Since(start), Err: err})
+		if err == nil {
+			continue
+		}
+		if !keepGoing {
+			return results, err
+		}
+		failed = true
+		if ExitCode(err) == 130 || (opts.Context != nil && opts.Context.Err() != nil) {
+			break
+		}
+		logger.Error("generation failed, continuing", "dir", dir, "error", err)
+	}
+	if failed {
+		return results, BatchError{Results: results}
+	}
+	return results, nil
+}
+
+func WriteBatchResults(w io.Writer, results []BatchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIR\tCHARTS\tSTATUS\tDURATION\tERROR")
+	for _, r := range results {
+		charts := r.Charts
+		if charts == "" {
+			charts = "-"
+		}
+		status := "ok"
+		message := "-"
+		if r.Err != nil {
+			status = "failed"
+			message = strings.SplitN(r.Err.Error(), "\n", 2)[0]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Dir, charts, status, r.Duration.Round(time.Millisecond), message)
+	}
+	return tw.Flush()
+}
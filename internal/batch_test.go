@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunBatchKeepGoing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mockResource("ConfigMap", "app")))
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	dirs := []string{filepath.Join(root, "a"), filepath.Join(root, "b"), filepath.Join(root, "c")}
+	configs := []string{
+		fmt.Sprintf("type: download\nurl: %s\n", server.URL),
+		"type: unknown\n",
+		fmt.Sprintf("type: download\nurl: %s\n", server.URL),
+	}
+	for i, dir := range dirs {
+		assert.NoError(t, os.MkdirAll(dir, 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte(configs[i]), 0o644))
+	}
+
+	results, err := RunBatch(dirs, RunOptions{}, false)
+	assert.Error(t, err)
+	assert.Len(t, results, 2)
+	assert.NoFileExists(t, filepath.Join(dirs[2], "kustomization.yaml"))
+
+	results, err = RunBatch(dirs, RunOptions{}, true)
+	if assert.Error(t, err) {
+		assert.Equal(t, 2, ExitCode(err))
+		assert.Contains(t, err.Error(), "1 of 3 configurations failed")
+		assert.Contains(t, err.Error(), dirs[1]+": ")
+	}
+	if assert.Len(t, results, 3) {
+		assert.NoError(t, results[0].Err)
+		assert.Error(t, results[1].Err)
+		assert.NoError(t, results[2].Err)
+	}
+	assert.FileExists(t, filepath.Join(dirs[2], "kustomization.yaml"))
+
+	output := bytes.Buffer{}
+	assert.NoError(t, WriteBatchResults(&output, results))
+	assert.Regexp(t, `(?m)^.*/b\s+-\s+failed\s+`, output.String())
+	assert.Regexp(t, `(?m)^.*/c\s+-\s+ok\s+\S+\s+-$`, output.String())
+}
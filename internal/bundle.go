@@ -0,0 +1,278 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const bundleIndexFile = "bundle.yaml"
+
+type BundleIndex struct {
+	Charts []BundleChart `yaml:"charts"`
+}
+
+type BundleChart struct {
+	Key             string `yaml:"key"`
+	Registry        string `yaml:"registry"`
+	Chart           string `yaml:"chart,omitempty"`
+	Version         string `yaml:"version,omitempty"`
+	Devel           bool   `yaml:"devel,omitempty"`
+	ResolvedChart   string `yaml:"resolvedChart"`
+	ResolvedVersion string `yaml:"resolvedVersion"`
+	AppVersion      string `yaml:"appVersion,omitempty"`
+	Digest          string `yaml:"digest,omitempty"`
+	Sha256          string `yaml:"sha256"`
+}
+
+func (c BundleChart) source() GeneratorSource {
+	return GeneratorSource{Chart: c.ResolvedChart, Version: c.ResolvedVersion, AppVersion: c.AppVersion, Digest: c.Digest}
+}
+
+func bundleChartKey(registry string, chart string, version string, devel bool) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{registry, chart, version, fmt.Sprint(devel)}, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+func bundleArchiveFile(key string) string {
+	return path.Join("charts", key+".tgz")
+}
+
+func ExportBundle(root string, opts RunOptions, w io.Writer) (*BundleIndex, error) {
+	dirs, err := FindConfigDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	index := BundleIndex{Charts: []BundleChart{}}
+	archives := map[string][]byte{}
+	for _, dir := range dirs {
+		config, err := loadRunConfig(dir, opts)
+		if err != nil {
+			return nil, err
+		}
+		ctx, err := newGeneratorContext(dir, *config, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, generator := range collectHelmGenerators(config.Generator) {
+			if generator.Registry == "" {
+				continue
+			}
+			repository, err := ctx.resolveRepository(generator.Registry)
+			if err != nil {
+				return nil, err
+			}
+			key := bundleChartKey(repository.Url, generator.Chart, generator.Version, generator.Devel)
+			if _, exists := archives[key]; exists {
+				continue
+			}
+			err = generator.withChartDir(ctx, func(chartDir string, source GeneratorSource) error {
+				archive, err := createTarGz(chartDir)
+				if err != nil {
+					return fmt.Errorf("archiving chart %s failed: %v", source.Chart, err)
+				}
+				hash := sha256.Sum256(archive)
+				archives[key] = archive
+				index.Charts = append(index.Charts, BundleChart{
+					Key:             key,
+					Registry:        repository.Url,
+					Chart:           generator.Chart,
+					Version:         generator.Version,
+					Devel:           generator.Devel,
+					ResolvedChart:   source.Chart,
+					ResolvedVersion: source.Version,
+					AppVersion:      source.AppVersion,
+					Digest:          source.Digest,
+					Sha256:          hex.EncodeToString(hash[:]),
+				})
+				ctx.log().Info("chart bundled", "chart", source.Chart, "version", source.Version)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Slice(index.Charts, func(i, j int) bool {
+		return index.Charts[i].Key < index.Charts[j].Key
+	})
+
+	indexBytes, err := yaml.Marshal(index)
+	if err != nil {
+		return nil, fmt.Errorf("writing bundle failed: %v", err)
+	}
+	tarWriter := tar.NewWriter(w)
+	writeEntry := func(name string, content []byte) error {
+		err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			return err
+		}
+		_, err = tarWriter.Write(content)
+		return err
+	}
+	err = writeEntry(bundleIndexFile, indexBytes)
+	for _, chart := range index.Charts {
+		if err == nil {
+			err = writeEntry(bundleArchiveFile(chart.Key), archives[chart.Key])
+		}
+	}
+	if err == nil {
+		err = tarWriter.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("writing bundle failed: %v", err)
+	}
+	return &index, nil
+}
+
+func ImportBundle(r io.Reader, opts RunOptions) (*BundleIndex, error) {
+	ctx, err := newGeneratorContext(".", Config{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	cacheDir, err := ctx.cacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("importing bundle failed: %v", err)
+	}
+	entries := map[string][]byte{}
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, validationErrorf("reading bundle failed: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, validationErrorf("reading bundle failed: %v", err)
+		}
+		entries[header.Name] = content
+	}
+	indexBytes, ok := entries[bundleIndexFile]
+	if !ok {
+		return nil, validationErrorf("reading bundle failed: %s is missing", bundleIndexFile)
+	}
+	index := BundleIndex{}
+	err = yaml.Unmarshal(indexBytes, &index)
+	if err != nil {
+		return nil, validationErrorf("reading bundle failed: %v", err)
+	}
+	for _, chart := range index.Charts {
+		if chart.Key != bundleChartKey(chart.Registry, chart.Chart, chart.Version, chart.Devel) {
+			return nil, validationErrorf("reading bundle failed: chart %s %s has an invalid key", chart.ResolvedChart, chart.ResolvedVersion)
+		}
+		archive, ok := entries[bundleArchiveFile(chart.Key)]
+		if !ok {
+			return nil, validationErrorf("reading bundle failed: archive of chart %s %s is missing", chart.ResolvedChart, chart.ResolvedVersion)
+		}
+		hash := sha256.Sum256(archive)
+		if hex.EncodeToString(hash[:]) != chart.Sha256 {
+			return nil, validationErrorf("reading bundle failed: archive of chart %s %s does not match its checksum", chart.ResolvedChart, chart.ResolvedVersion)
+		}
+	}
+	for _, chart := range index.Charts {
+		metadata, err := yaml.Marshal(chart)
+		if err != nil {
+			return nil, fmt.Errorf("importing bundle failed: %v", err)
+		}
+		file := filepath.Join(cacheDir, filepath.FromSlash(bundleArchiveFile(chart.Key)))
+		err = os.MkdirAll(filepath.Dir(file), 0o755)
+		if err == nil {
+			err = os.WriteFile(file, entries[bundleArchiveFile(chart.Key)], 0o644)
+		}
+		if err == nil {
+			err = os.WriteFile(strings.TrimSuffix(file, ".tgz")+".yaml", metadata, 0o644)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("importing bundle failed: %v", err)
+		}
+		ctx.log().Info("chart imported", "chart", chart.ResolvedChart, "version", chart.ResolvedVersion)
+	}
+	return &index, nil
+}
+
+func loadBundledChart(ctx GeneratorContext, registry string, chart string, version string, devel bool) (*BundleChart, []byte, error) {
+	cacheDir, err := ctx.cacheDir()
+	if err != nil {
+		return nil, nil, nil
+	}
+	file := filepath.Join(cacheDir, filepath.FromSlash(bundleArchiveFile(bundleChartKey(registry, chart, version, devel))))
+	metadata := BundleChart{}
+	err = readYamlFile(strings.TrimSuffix(file, ".tgz")+".yaml", &metadata)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading bundled chart failed: %v", err)
+	}
+	archive, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading bundled chart failed: %v", err)
+	}
+	hash := sha256.Sum256(archive)
+	if hex.EncodeToString(hash[:]) != metadata.Sha256 {
+		return nil, nil, validationErrorf("bundled chart %s %s does not match its checksum", metadata.ResolvedChart, metadata.ResolvedVersion)
+	}
+	return &metadata, archive, nil
+}
+
+func createTarGz(dir string) ([]byte, error) {
+	files := []string{}
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	archive := bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		name, err := filepath.Rel(filepath.Dir(dir), file)
+		if err != nil {
+			return nil, err
+		}
+		err = tarWriter.WriteHeader(&tar.Header{Name: filepath.ToSlash(name), Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			return nil, err
+		}
+		_, err = tarWriter.Write(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tarWriter.Close()
+	if err == nil {
+		err = gzipWriter.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return archive.Bytes(), nil
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportImportBundle(t *testing.T) {
+	helm := fakeHelm(t, `if [ "$1" = pull ]; then
+  while [ $# -gt 0 ]; do
+    if [ "$1" = --untardir ]; then dir="$2"; fi
+    shift
+  done
+  mkdir -p "$dir/app/templates"
+  printf 'name: app\nversion: 1.2.3\n' > "$dir/app/Chart.yaml"
+  printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n' > "$dir/app/templates/configmap.yaml"
+  exit 0
+fi
+for arg in "$@"; do
+  case "$arg" in
+    oci://*) echo "registry not reachable" >&2; exit 1 ;;
+  esac
+done
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --values) chart="$3"; shift ;;
+  esac
+  shift
+done
+cat "$chart/templates/configmap.yaml"
+`)
+	root := t.TempDir()
+	configYaml := "type: helm\nregistry: oci://ghcr.io/org/charts/app\nversion: 1.2.3\nname: app\n"
+	for _, dir := range []string{"a", "b"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, dir, configFile), []byte(configYaml), 0o644))
+	}
+
+	bundle := bytes.Buffer{}
+	index, err := ExportBundle(root, RunOptions{HelmBin: helm, CacheDir: t.TempDir()}, &bundle)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, index.Charts, 1) {
+		assert.Equal(t, "oci://ghcr.io/org/charts/app", index.Charts[0].Registry)
+		assert.Equal(t, "app", index.Charts[0].ResolvedChart)
+		assert.Equal(t, "1.2.3", index.Charts[0].ResolvedVersion)
+	}
+
+	config, err := parseConfig([]byte(configYaml))
+	if !assert.NoError(t, err) {
+		return
+	}
+	cacheDir := t.TempDir()
+	_, err = config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm, CacheDir: cacheDir})
+	assert.Error(t, err)
+
+	_, err = ImportBundle(bytes.NewReader(bundle.Bytes()), RunOptions{CacheDir: cacheDir})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm, CacheDir: cacheDir})
+	assert.Error(t, err)
+	result, err := config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm, CacheDir: cacheDir, BundledCharts: true})
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Resources, 1)
+		assert.Equal(t, "1.2.3", result.Source.Version)
+	}
+
+	signed, err := parseConfig([]byte(configYaml + "cosign:\n  key: cosign.pub\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = signed.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm, CacheDir: cacheDir, BundledCharts: true})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "cannot be used with cosign verification")
+	}
+}
+
+func TestImportBundleRejectsTamperedArchives(t *testing.T) {
+	bundle := bytes.Buffer{}
+	key := bundleChartKey("oci://ghcr.io/org/charts/app", "", "1.2.3", false)
+	tarWriter := tar.NewWriter(&bundle)
+	for name, content := range map[string]string{
+		bundleIndexFile:        "charts:\n- key: " + key + "\n  registry: oci://ghcr.io/org/charts/app\n  version: 1.2.3\n  resolvedChart: app\n  resolvedVersion: 1.2.3\n  sha256: \"0000\"\n",
+		bundleArchiveFile(key): "tampered",
+	} {
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, _ = tarWriter.Write([]byte(content))
+	}
+	assert.NoError(t, tarWriter.Close())
+	_, err := ImportBundle(&bundle, RunOptions{CacheDir: t.TempDir()})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not match its checksum")
+	}
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"os/exec"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+var chartVariableRegex = regexp.MustCompile(`\{\{-?\s*\.Chart\.(Name|Version|AppVersion)\s*-?\}\}`)
+
+func substituteChartVariables(value interface{}, source GeneratorSource) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		result := map[string]interface{}{}
+		for key, nested := range v {
+			result[key] = substituteChartVariables(nested, source)
+		}
+		return result
+	case []interface{}:
+		result := []interface{}{}
+		for _, nested := range v {
+			result = append(result, substituteChartVariables(nested, source))
+		}
+		return result
+	case string:
+		return chartVariableRegex.ReplaceAllStringFunc(v, func(match string) string {
+			switch chartVariableRegex.FindStringSubmatch(match)[1] {
+			case "Name":
+				return source.Chart
+			case "Version":
+				return source.Version
+			default:
+				return source.AppVersion
+			}
+		})
+	}
+	return value
+}
+
+func resolveHelmChartAppVersion(ctx GeneratorContext, helmPath string, chart resolvedHelmChart) (string, error) {
+	if chart.LocalDir != "" {
+		localChart, err := readHelmLocalChart(chart.LocalDir)
+		if err != nil {
+			return "", configErrorf("reading chart %s failed: %v", chart.LocalDir, err)
+		}
+		return localChart.AppVersion, nil
+	}
+	stdout, stderr, err := ctx.runCommand(*exec.Command(helmPath, append([]string{"show", "chart"}, chart.Args...)...))
+	if err != nil {
+		return "", executionErrorf("executing helm failed: %v\n%s", err, string(stderr))
+	}
+	localChart := helmLocalChart{}
+	err = yaml.Unmarshal(stdout, &localChart)
+	if err != nil {
+		return "", executionErrorf("parsing chart metadata failed: %v", err)
+	}
+	return localChart.AppVersion, nil
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubstituteChartVariables(t *testing.T) {
+	source := GeneratorSource{Chart: "app", Version: "1.2.3", AppVersion: "v2.0.0"}
+	assert.Equal(t, map[string]interface{}{
+		"image": map[string]interface{}{"tag": "v2.0.0", "repository": "example.com/app"},
+		"labels": []interface{}{
+			"chart=app-1.2.3",
+			"{{ .Chart.Unknown }}",
+			"v2.0.0",
+		},
+		"replicas": 1,
+	}, substituteChartVariables(map[string]interface{}{
+		"image": map[string]interface{}{"tag": "{{ .Chart.AppVersion }}", "repository": "example.com/app"},
+		"labels": []interface{}{
+			"chart={{.Chart.Name}}-{{ .Chart.Version }}",
+			"{{ .Chart.Unknown }}",
+			"{{- .Chart.AppVersion -}}",
+		},
+		"replicas": 1,
+	}, source))
+}
+
+func TestHelmGeneratorChartVariables(t *testing.T) {
+	helm := fakeHelm(t, `while [ $# -gt 0 ]; do
+  case "$1" in
+    --values) values="$2"; shift ;;
+  esac
+  shift
+done
+printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n%s\n' "$(sed 's/^/  /' "$values")"
+`)
+	chartDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: app\nversion: 1.2.3\nappVersion: v2.0.0\n"), 0o644))
+
+	for chartVariables, tag := range map[bool]string{true: "v2.0.0", false: "'{{ .Chart.AppVersion }}'"} {
+		g := HelmGenerator{Chart: chartDir, Name: "app", ChartVariables: chartVariables, Values: map[string]interface{}{"tag": "{{ .Chart.AppVersion }}"}}
+		result, err := g.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  tag: "+tag+"\n", result.Resources[0].Content)
+		}
+	}
+}
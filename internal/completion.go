@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func cachedHelmRegistryIndexFile(cacheDir string, url string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSuffix(url, "/")))
+	return filepath.Join(cacheDir, "indexes", hex.EncodeToString(hash[:])+".yaml.gz")
+}
+
+func storeCachedHelmRegistryIndex(ctx GeneratorContext, url string, content []byte) {
+	cacheDir, err := ctx.cacheDir()
+	if err != nil {
+		return
+	}
+	file := cachedHelmRegistryIndexFile(cacheDir, url)
+	compressed := bytes.Buffer{}
+	writer := gzip.NewWriter(&compressed)
+	_, err = writer.Write(content)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(file), 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(file, compressed.Bytes(), 0o644)
+	}
+	if err != nil {
+		ctx.log().Debug("caching registry index failed", "url", url, "error", err)
+	}
+}
+
+func loadCachedHelmRegistryIndex(dir string, opts RunOptions, registry string) (*helmRegistryIndex, error) {
+	ctx, err := newGeneratorContext(dir, Config{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	repository, err := ctx.resolveRepository(registry)
+	if err != nil {
+		return nil, err
+	}
+	cacheDir, err := ctx.cacheDir()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(cachedHelmRegistryIndexFile(cacheDir, repository.Url))
+	if err != nil {
+		return nil, err
+	}
+	reader, err := decompressHelmRegistryIndex(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	index := helmRegistryIndex{}
+	err = yaml.Unmarshal(content, &index)
+	if err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+func CompleteChartNames(dir string, opts RunOptions, registry string) []string {
+	index, err := loadCachedHelmRegistryIndex(dir, opts, registry)
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for name := range index.Entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func CompleteChartVersions(dir string, opts RunOptions, registry string, chart string) []string {
+	index, err := loadCachedHelmRegistryIndex(dir, opts, registry)
+	if err != nil {
+		return nil
+	}
+	type version struct {
+		raw    string
+		parsed *semver
+	}
+	versions := []version{}
+	for _, entry := range index.Entries[chart] {
+		parsed, err := parseSemver(entry.Version)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version{raw: entry.Version, parsed: parsed})
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].parsed.Compare(*versions[j].parsed) > 0
+	})
+	result := []string{}
+	for _, v := range versions {
+		result = append(result, v.raw)
+	}
+	return result
+}
+
+func CompleteConfiguredChartVersions(dir string, opts RunOptions) []string {
+	config, err := loadRunConfig(dir, opts)
+	if err != nil {
+		return nil
+	}
+	generator, ok := config.Generator.(HelmGenerator)
+	if !ok {
+		return nil
+	}
+	return CompleteChartVersions(dir, opts, generator.Registry, generator.Chart)
+}
+
+func CompleteEnvironments(dir string, opts RunOptions) []string {
+	opts.Environment = ""
+	config, err := loadRunConfig(dir, opts)
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for name := range config.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
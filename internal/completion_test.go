@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteChartsFromCachedIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("entries:\n  app:\n  - version: 1.2.0\n  - version: 1.10.0\n  - version: 1.9.0\n  other:\n  - version: 0.1.0\n"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	opts := RunOptions{CacheDir: t.TempDir()}
+	assert.Nil(t, CompleteChartNames(dir, opts, server.URL))
+
+	_, _, _, err := retrieveHelmRegistryIndexVersions(GeneratorContext{CacheDir: opts.CacheDir}, Repository{Url: server.URL}, "app")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app", "other"}, CompleteChartNames(dir, opts, server.URL))
+	assert.Equal(t, []string{"1.10.0", "1.9.0", "1.2.0"}, CompleteChartVersions(dir, opts, server.URL+"/", "app"))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte("type: helm\nregistry: "+server.URL+"\nchart: other\nenvironments:\n  prod: {}\n  dev: {}\n"), 0o644))
+	assert.Equal(t, []string{"0.1.0"}, CompleteConfiguredChartVersions(dir, opts))
+	assert.Equal(t, []string{"dev", "prod"}, CompleteEnvironments(dir, opts))
+}
@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type resourceIdentity struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (id resourceIdentity) String() string {
+	kind := id.Kind
+	if id.Group != "" {
+		kind = kind + "." + id.Group
+	}
+	if id.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", kind, id.Namespace, id.Name)
+	}
+	return fmt.Sprintf("%s %s", kind, id.Name)
+}
+
+func identifyResource(resource GeneratorResource) (*resourceIdentity, error) {
+	kubernetesResource := KubernetesResource{}
+	err := yaml.Unmarshal([]byte(resource.Content), &kubernetesResource)
+	if err != nil {
+		return nil, err
+	}
+	group := ""
+	if i := strings.LastIndex(kubernetesResource.ApiVersion, "/"); i >= 0 {
+		group = kubernetesResource.ApiVersion[:i]
+	}
+	return &resourceIdentity{
+		Group:     group,
+		Kind:      kubernetesResource.Kind,
+		Namespace: kubernetesResource.Metadata.Namespace,
+		Name:      kubernetesResource.Metadata.Name,
+	}, nil
+}
+
+func resolveConflicts(result GeneratorResult, strategy string) (*GeneratorResult, error) {
+	if strategy == "" {
+		strategy = "error"
+	}
+	if strategy != "error" && strategy != "first" && strategy != "last" {
+		return nil, configErrorf("unsupported conflict strategy %s", strategy)
+	}
+
+	type location struct {
+		dir   string
+		index int
+	}
+	owners := map[resourceIdentity]location{}
+	dropped := map[location]bool{}
+	var collect func(dir string, result GeneratorResult) error
+	collect = func(dir string, result GeneratorResult) error {
+		for i, resource := range result.Resources {
+			id, err := identifyResource(resource)
+			if err != nil {
+				return fmt.Errorf("identifying resource %s failed: %v", resource.File, err)
+			}
+			current := location{dir: dir, index: i}
+			previous, exists := owners[*id]
+			if !exists {
+				owners[*id] = current
+				continue
+			}
+			switch strategy {
+			case "first":
+				dropped[current] = true
+			case "last":
+				dropped[previous] = true
+				owners[*id] = current
+			default:
+				return configErrorf("resource %s is generated multiple times (in %s and %s)", id.String(), displayDir(previous.dir), displayDir(dir))
+			}
+		}
+		for _, child := range result.Children {
+			err := collect(path.Join(dir, child.Dir), child.Result)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err := collect("", result)
+	if err != nil {
+		return nil, err
+	}
+
+	var rebuild func(dir string, result GeneratorResult) GeneratorResult
+	rebuild = func(dir string, result GeneratorResult) GeneratorResult {
+		rebuilt := result
+		rebuilt.Resources = nil
+		rebuilt.Children = nil
+		for i, resource := range result.Resources {
+			if !dropped[location{dir: dir, index: i}] {
+				rebuilt.Resources = append(rebuilt.Resources, resource)
+			}
+		}
+		for _, child := range result.Children {
+			child.Result = rebuild(path.Join(dir, child.Dir), child.Result)
+			rebuilt.Children = append(rebuilt.Children, child)
+		}
+		return rebuilt
+	}
+	rebuilt := rebuild("", result)
+	return &rebuilt, nil
+}
+
+func displayDir(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveConflicts(t *testing.T) {
+	a := GeneratorResource{File: "database-secret.yaml", Content: mockResource("Secret", "database")}
+	b := GeneratorResource{File: "database-secret.yaml", Content: mockResource("Secret", "database") + "data: {}\n"}
+	c := GeneratorResource{File: "other-secret.yaml", Content: mockResource("Secret", "other")}
+	input := GeneratorResult{
+		Children: []GeneratorResultChild{
+			{Dir: "app", Result: GeneratorResult{Resources: []GeneratorResource{a, c}}},
+			{Dir: "operator", Result: GeneratorResult{Resources: []GeneratorResource{b}}},
+		},
+	}
+
+	_, err := resolveConflicts(input, "")
+	if assert.Error(t, err) {
+		assert.Equal(t, "resource Secret database is generated multiple times (in app and operator)", err.Error())
+	}
+
+	actual, err := resolveConflicts(input, "first")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []GeneratorResource{a, c}, actual.Children[0].Result.Resources)
+		assert.Nil(t, actual.Children[1].Result.Resources)
+	}
+
+	actual, err = resolveConflicts(input, "last")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []GeneratorResource{c}, actual.Children[0].Result.Resources)
+		assert.Equal(t, []GeneratorResource{b}, actual.Children[1].Result.Resources)
+	}
+
+	_, err = resolveConflicts(input, "random")
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHttpTimeout    = 2 * time.Minute
+	defaultCommandTimeout = 10 * time.Minute
+	maxRateLimitRetries   = 5
+	maxRetryAfter         = time.Minute
+)
+
+type TimeoutsConfig struct {
+	Http    time.Duration `yaml:"http"`
+	Command time.Duration `yaml:"command"`
+}
+
+func (ctx GeneratorContext) context() context.Context {
+	if ctx.Context == nil {
+		return context.Background()
+	}
+	return ctx.Context
+}
+
+func (ctx GeneratorContext) httpTimeout() time.Duration {
+	if ctx.Timeouts.Http > 0 {
+		return ctx.Timeouts.Http
+	}
+	return defaultHttpTimeout
+}
+
+func (ctx GeneratorContext) commandTimeout() time.Duration {
+	if ctx.Timeouts.Command > 0 {
+		return ctx.Timeouts.Command
+	}
+	return defaultCommandTimeout
+}
+
+func (ctx GeneratorContext) httpGet(url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx.context(), "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.httpDo(req)
+}
+
+func (ctx GeneratorContext) httpDo(req *http.Request) (*http.Response, error) {
+	client := &http.Client{Timeout: ctx.httpTimeout(), Transport: ctx.Transport, CheckRedirect: stripCrossHostCredentials}
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRateLimitRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		wait := parseRetryAfter(resp.Header.Get("Retry-After"), attempt, time.Now())
+		resp.Body.Close()
+		ctx.log().Warn("rate limited, retrying", "url", req.URL.String(), "wait", wait)
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+func parseRetryAfter(value string, attempt int, now time.Time) time.Duration {
+	wait := time.Second << attempt
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds >= 0 {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		wait = date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+	}
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait
+}
+
+func (ctx GeneratorContext) runCommand(cmd exec.Cmd) ([]byte, []byte, error) {
+	timeout := ctx.commandTimeout()
+	cmdCtx, cancel := context.WithTimeout(ctx.context(), timeout)
+	defer cancel()
+
+	cmdWithContext := exec.CommandContext(cmdCtx, cmd.Path, cmd.Args[1:]...)
+	cmdWithContext.Dir = cmd.Dir
+	cmdWithContext.Env = cmd.Env
+	if ctx.Proxy != "" {
+		if cmdWithContext.Env == nil {
+			cmdWithContext.Env = os.Environ()
+		}
+		cmdWithContext.Env = append(cmdWithContext.Env, "HTTP_PROXY="+ctx.Proxy, "HTTPS_PROXY="+ctx.Proxy)
+	}
+	if ctx.TempDir != "" {
+		if cmdWithContext.Env == nil {
+			cmdWithContext.Env = os.Environ()
+		}
+		cmdWithContext.Env = append(cmdWithContext.Env, "TMPDIR="+ctx.TempDir)
+	}
+	cmdWithContext.Stdin = cmd.Stdin
+	stdout := limitedBuffer{limit: ctx.MaxOutput, cancel: cancel}
+	stderr := limitedBuffer{limit: ctx.MaxOutput, cancel: cancel}
+	cmdWithContext.Stdout = &stdout
+	cmdWithContext.Stderr = &stderr
+	err := cmdWithContext.Run()
+	if stdout.exceeded || stderr.exceeded {
+		return stdout.Bytes(), stderr.Bytes(), fmt.Errorf("output exceeded limit of %d bytes", ctx.MaxOutput)
+	}
+	if err != nil && errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+		return stdout.Bytes(), stderr.Bytes(), fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil && cmdCtx.Err() != nil {
+		return stdout.Bytes(), stderr.Bytes(), cmdCtx.Err()
+	}
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+type limitedBuffer struct {
+	buffer   bytes.Buffer
+	limit    int64
+	exceeded bool
+	cancel   func()
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && int64(b.buffer.Len()+len(p)) > b.limit {
+		b.exceeded = true
+		b.cancel()
+		return 0, errors.New("output limit exceeded")
+	}
+	return b.buffer.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buffer.Bytes()
+}
+
+func stripCrossHostCredentials(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if req.URL.Host != via[0].URL.Host {
+		req.Header.Del("Authorization")
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratorContextRunCommandTimeout(t *testing.T) {
+	sleepPath, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep executable not found")
+	}
+	ctx := GeneratorContext{Timeouts: TimeoutsConfig{Command: 50 * time.Millisecond}}
+	_, _, err = ctx.runCommand(*exec.Command(sleepPath, "5"))
+	assert.EqualError(t, err, "timed out after 50ms")
+}
+
+func TestGeneratorContextRunCommandTempDir(t *testing.T) {
+	shPath, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh executable not found")
+	}
+	tempDir := t.TempDir()
+	stdout, _, err := GeneratorContext{TempDir: tempDir}.runCommand(*exec.Command(shPath, "-c", "echo $TMPDIR"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, tempDir+"\n", string(stdout))
+	}
+}
+
+func TestGeneratorContextHttpGetTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	ctx := GeneratorContext{Timeouts: TimeoutsConfig{Http: 50 * time.Millisecond}}
+	_, err := ctx.httpGet(server.URL)
+	assert.Error(t, err)
+}
+
+func TestGeneratorContextHttpDoStripsCredentialsOnCrossHostRedirect(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		assert.False(t, ok)
+		_, _ = w.Write([]byte("entries:\n  app:\n  - version: 1.0.0\n    urls: [charts/app-1.0.0.tgz]\n"))
+	}))
+	defer other.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		assert.True(t, ok)
+		http.Redirect(w, r, other.URL+"/pages/index.yaml", http.StatusFound)
+	}))
+	defer server.Close()
+
+	entry, urls, err := retrieveHelmChartArchive(GeneratorContext{}, Repository{Url: server.URL, Username: "user", Password: "secret"}, "app", "1.0.0", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.0.0", entry.Version)
+		assert.Equal(t, []string{other.URL + "/pages/charts/app-1.0.0.tgz"}, urls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", 0, now))
+	assert.Equal(t, 10*time.Second, parseRetryAfter(now.Add(10*time.Second).Format(http.TimeFormat), 0, now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-10*time.Second).Format(http.TimeFormat), 0, now))
+	assert.Equal(t, 4*time.Second, parseRetryAfter("", 2, now))
+	assert.Equal(t, maxRetryAfter, parseRetryAfter("3600", 0, now))
+}
+
+func TestGeneratorContextHttpGetRetriesRateLimit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := GeneratorContext{}.httpGet(server.URL)
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 2, requests)
+	}
+}
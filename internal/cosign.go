@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+type HelmCosignConfig struct {
+	Key            string `yaml:"key"`
+	Identity       string `yaml:"identity"`
+	IdentityRegexp string `yaml:"identityRegexp"`
+	Issuer         string `yaml:"issuer"`
+	IssuerRegexp   string `yaml:"issuerRegexp"`
+	Attestation    string `yaml:"attestation"`
+}
+
+func validateHelmCosign(config *HelmCosignConfig) error {
+	if config == nil {
+		return nil
+	}
+	keyless := config.Identity != "" || config.IdentityRegexp != "" || config.Issuer != "" || config.IssuerRegexp != ""
+	if config.Key != "" && keyless {
+		return configErrorf("cosign key and identity are mutually exclusive")
+	}
+	if config.Key == "" && !keyless {
+		return configErrorf("cosign is missing key or identity")
+	}
+	if keyless {
+		if (config.Identity == "") == (config.IdentityRegexp == "") {
+			return configErrorf("cosign requires exactly one of identity and identityRegexp")
+		}
+		if (config.Issuer == "") == (config.IssuerRegexp == "") {
+			return configErrorf("cosign requires exactly one of issuer and issuerRegexp")
+		}
+	}
+	return nil
+}
+
+var helmPullDigestRegex = regexp.MustCompile(`(?m)^Digest:\s*(sha256:[0-9a-f]{64})\s*$`)
+
+func cosignReference(registry string, digest string) string {
+	return strings.TrimPrefix(registry, "oci://") + "@" + digest
+}
+
+func pullVerifiedHelmChart(ctx GeneratorContext, helmPath string, config HelmCosignConfig, registry string, chartArgs []string) (string, func(), error) {
+	done := ctx.Phase("chart pull")
+	chartDir, output, cleanup, err := pullHelmChartOutput(ctx, helmPath, chartArgs)
+	done()
+	if err != nil {
+		return "", nil, err
+	}
+	match := helmPullDigestRegex.FindSubmatch(output)
+	if match == nil {
+		cleanup()
+		return "", nil, executionErrorf("resolving digest of %s failed: helm pull did not report a digest", registry)
+	}
+	err = verifyCosignSignature(ctx, config, cosignReference(registry, string(match[1])))
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return chartDir, cleanup, nil
+}
+
+func (config HelmCosignConfig) args(ref string) []string {
+	args := []string{"verify"}
+	if config.Attestation != "" {
+		args = []string{"verify-attestation", "--type", config.Attestation}
+	}
+	if config.Key != "" {
+		args = append(args, "--key", config.Key)
+	}
+	if config.Identity != "" {
+		args = append(args, "--certificate-identity", config.Identity)
+	}
+	if config.IdentityRegexp != "" {
+		args = append(args, "--certificate-identity-regexp", config.IdentityRegexp)
+	}
+	if config.Issuer != "" {
+		args = append(args, "--certificate-oidc-issuer", config.Issuer)
+	}
+	if config.IssuerRegexp != "" {
+		args = append(args, "--certificate-oidc-issuer-regexp", config.IssuerRegexp)
+	}
+	return append(args, "--output", "text", ref)
+}
+
+func verifyCosignSignature(ctx GeneratorContext, config HelmCosignConfig, ref string) error {
+	cosignPath, err := exec.LookPath("cosign")
+	if err != nil {
+		return executionErrorf("executing cosign failed: executable not found")
+	}
+	done := ctx.Phase("signature verification")
+	_, stderr, err := ctx.runCommand(*exec.Command(cosignPath, config.args(ref)...))
+	done()
+	if err != nil {
+		what := "signature"
+		if config.Attestation != "" {
+			what = "attestation"
+		}
+		return validationErrorf("verifying cosign %s of %s failed: %v\n%s", what, ref, err, strings.TrimSpace(string(stderr)))
+	}
+	ctx.log().Info("chart signature verified", "ref", ref)
+	return nil
+}
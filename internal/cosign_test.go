@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateHelmCosign(t *testing.T) {
+	assert.NoError(t, validateHelmCosign(nil))
+	assert.NoError(t, validateHelmCosign(&HelmCosignConfig{Key: "cosign.pub"}))
+	assert.NoError(t, validateHelmCosign(&HelmCosignConfig{Identity: "https://github.com/org/repo/.github/workflows/release.yaml@refs/heads/main", Issuer: "https://token.actions.githubusercontent.com"}))
+	assert.NoError(t, validateHelmCosign(&HelmCosignConfig{IdentityRegexp: "^https://github.com/org/", Issuer: "https://token.actions.githubusercontent.com"}))
+	assert.Error(t, validateHelmCosign(&HelmCosignConfig{}))
+	assert.Error(t, validateHelmCosign(&HelmCosignConfig{Key: "cosign.pub", Identity: "someone"}))
+	assert.Error(t, validateHelmCosign(&HelmCosignConfig{Identity: "someone"}))
+	assert.Error(t, validateHelmCosign(&HelmCosignConfig{Identity: "someone", IdentityRegexp: "some.*", Issuer: "issuer"}))
+}
+
+func TestCosignReference(t *testing.T) {
+	assert.Equal(t, "ghcr.io/org/charts/app@sha256:abc", cosignReference("oci://ghcr.io/org/charts/app", "sha256:abc"))
+}
+
+func TestHelmGeneratorCosign(t *testing.T) {
+	bin := t.TempDir()
+	helm := filepath.Join(bin, "helm")
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	templateArgsFile := filepath.Join(bin, "helm-template-args")
+	assert.NoError(t, os.WriteFile(helm, []byte(`#!/bin/sh
+if [ "$1" = version ]; then echo v3.12.0; exit 0; fi
+if [ "$1" = pull ]; then
+  while [ "$1" != --untardir ]; do shift; done
+  mkdir -p "$2/app" && printf 'name: app\nversion: 1.2.3\n' > "$2/app/Chart.yaml"
+  if [ ! -e "`+bin+`/helm-no-digest" ]; then printf 'Pulled: ghcr.io/org/charts/app:1.2.3\nDigest: `+digest+`\n'; fi
+  exit 0
+fi
+echo "$@" > "`+templateArgsFile+`"
+printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n'
+`), 0o755))
+	argsFile := filepath.Join(bin, "cosign-args")
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "cosign"), []byte(`#!/bin/sh
+echo "$@" > "`+argsFile+`"
+if [ -e "`+bin+`/cosign-fail" ]; then echo "no matching signatures" >&2; exit 1; fi
+`), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	config, err := parseConfig([]byte(`type: helm
+registry: oci://ghcr.io/org/charts/app
+version: 1.2.3
+name: app
+cosign:
+  identityRegexp: ^https://github.com/org/
+  issuer: https://token.actions.githubusercontent.com
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	result, err := config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Resources, 1)
+		args, _ := os.ReadFile(argsFile)
+		assert.Equal(t, "verify --certificate-identity-regexp ^https://github.com/org/ --certificate-oidc-issuer https://token.actions.githubusercontent.com --output text ghcr.io/org/charts/app@"+digest, strings.TrimSpace(string(args)))
+		templateArgs, _ := os.ReadFile(templateArgsFile)
+		assert.NotContains(t, string(templateArgs), "oci://")
+		assert.Contains(t, string(templateArgs), "-chart/app")
+	}
+
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "helm-no-digest"), nil, 0o644))
+	_, err = config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "did not report a digest")
+	}
+	assert.NoError(t, os.Remove(filepath.Join(bin, "helm-no-digest")))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "cosign-fail"), nil, 0o644))
+	_, err = config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+	if assert.Error(t, err) {
+		assert.Equal(t, 5, ExitCode(err))
+		assert.Contains(t, err.Error(), "no matching signatures")
+	}
+
+	config, err = parseConfig([]byte("type: helm\nregistry: https://charts.example.com\nchart: app\nversion: 1.2.3\ncosign:\n  key: cosign.pub\n"))
+	if assert.NoError(t, err) {
+		_, err = config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "only supported for oci://")
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package internal
+
+func checkHelmChartDeprecation(ctx GeneratorContext, chart string, versions []helmRegistryIndexEntry, entry helmRegistryIndexEntry) error {
+	message := ""
+	if newest, err := selectHelmChartVersion(versions, "", false); err == nil && newest.Deprecated {
+		message = "chart " + chart + " is deprecated"
+	} else if entry.Deprecated {
+		message = "chart " + chart + " version " + entry.Version + " is deprecated"
+	}
+	if message == "" {
+		return nil
+	}
+	if ctx.Strict {
+		return validationErrorf("%s", message)
+	}
+	ctx.log().Warn(message, "chart", chart, "version", entry.Version)
+	return nil
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHelmChartDeprecation(t *testing.T) {
+	versions := []helmRegistryIndexEntry{
+		{Version: "1.0.0", Deprecated: true},
+		{Version: "1.1.0"},
+	}
+	logs := bytes.Buffer{}
+	ctx := GeneratorContext{Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	assert.NoError(t, checkHelmChartDeprecation(ctx, "app", versions, versions[1]))
+	assert.Empty(t, logs.String())
+	assert.NoError(t, checkHelmChartDeprecation(ctx, "app", versions, versions[0]))
+	assert.Contains(t, logs.String(), "chart app version 1.0.0 is deprecated")
+
+	ctx.Strict = true
+	err := checkHelmChartDeprecation(ctx, "app", versions, versions[0])
+	if assert.Error(t, err) {
+		assert.Equal(t, "chart app version 1.0.0 is deprecated", err.Error())
+		assert.Equal(t, 5, ExitCode(err))
+	}
+
+	deprecatedChart := []helmRegistryIndexEntry{
+		{Version: "1.0.0"},
+		{Version: "2.0.0-rc.1"},
+		{Version: "1.1.0", Deprecated: true},
+	}
+	err = checkHelmChartDeprecation(ctx, "app", deprecatedChart, deprecatedChart[0])
+	if assert.Error(t, err) {
+		assert.Equal(t, "chart app is deprecated", err.Error())
+	}
+	err = checkHelmChartDeprecation(ctx, "app", nil, helmRegistryIndexEntry{Version: "1.0.0", Deprecated: true})
+	if assert.Error(t, err) {
+		assert.Equal(t, "chart app version 1.0.0 is deprecated", err.Error())
+	}
+}
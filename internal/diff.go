@@ -0,0 +1,217 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+const diffContextLines = 3
+
+type diffLine struct {
+	Op   byte
+	Text string
+}
+
+type ResourceDiff struct {
+	Resource string
+	Change   string
+	Diff     string
+	Fields   []FieldChange
+}
+
+func diffLines(a string, b string) []diffLine {
+	aLines := splitDiffLines(a)
+	bLines := splitDiffLines(b)
+
+	prefix := 0
+	for prefix < len(aLines) && prefix < len(bLines) && aLines[prefix] == bLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(aLines)-prefix && suffix < len(bLines)-prefix && aLines[len(aLines)-1-suffix] == bLines[len(bLines)-1-suffix] {
+		suffix++
+	}
+	aMiddle := aLines[prefix : len(aLines)-suffix]
+	bMiddle := bLines[prefix : len(bLines)-suffix]
+
+	lcs := make([][]int, len(aMiddle)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bMiddle)+1)
+	}
+	for i := len(aMiddle) - 1; i >= 0; i-- {
+		for j := len(bMiddle) - 1; j >= 0; j-- {
+			if aMiddle[i] == bMiddle[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	result := []diffLine{}
+	for _, line := range aLines[:prefix] {
+		result = append(result, diffLine{Op: ' ', Text: line})
+	}
+	i, j := 0, 0
+	for i < len(aMiddle) || j < len(bMiddle) {
+		switch {
+		case i < len(aMiddle) && j < len(bMiddle) && aMiddle[i] == bMiddle[j]:
+			result = append(result, diffLine{Op: ' ', Text: aMiddle[i]})
+			i++
+			j++
+		case j < len(bMiddle) && (i == len(aMiddle) || lcs[i][j+1] > lcs[i+1][j]):
+			result = append(result, diffLine{Op: '+', Text: bMiddle[j]})
+			j++
+		default:
+			result = append(result, diffLine{Op: '-', Text: aMiddle[i]})
+			i++
+		}
+	}
+	for _, line := range aLines[len(aLines)-suffix:] {
+		result = append(result, diffLine{Op: ' ', Text: line})
+	}
+	return result
+}
+
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func unifiedDiff(a string, b string) string {
+	lines := diffLines(a, b)
+	changed := []int{}
+	for i, line := range lines {
+		if line.Op != ' ' {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+
+	result := strings.Builder{}
+	for k := 0; k < len(changed); {
+		start := changed[k] - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := changed[k] + diffContextLines + 1
+		for k < len(changed) && changed[k]-diffContextLines <= end {
+			end = changed[k] + diffContextLines + 1
+			k++
+		}
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		aStart, bStart := 1, 1
+		for _, line := range lines[:start] {
+			if line.Op != '+' {
+				aStart++
+			}
+			if line.Op != '-' {
+				bStart++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, line := range lines[start:end] {
+			if line.Op != '+' {
+				aCount++
+			}
+			if line.Op != '-' {
+				bCount++
+			}
+		}
+		if aCount == 0 {
+			aStart--
+		}
+		if bCount == 0 {
+			bStart--
+		}
+		result.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount))
+		for _, line := range lines[start:end] {
+			result.WriteByte(line.Op)
+			result.WriteString(line.Text)
+			result.WriteByte('\n')
+		}
+	}
+	return result.String()
+}
+
+func diffGeneratorResults(before GeneratorResult, after GeneratorResult) ([]ResourceDiff, error) {
+	beforeContents, err := indexResourceContents(before)
+	if err != nil {
+		return nil, err
+	}
+	afterContents, err := indexResourceContents(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range beforeContents {
+		names = append(names, name)
+	}
+	for name := range afterContents {
+		if _, ok := beforeContents[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := []ResourceDiff{}
+	for _, name := range names {
+		beforeContent, beforeOk := beforeContents[name]
+		afterContent, afterOk := afterContents[name]
+		switch {
+		case !beforeOk:
+			result = append(result, ResourceDiff{Resource: name, Change: "added", Diff: unifiedDiff("", afterContent)})
+		case !afterOk:
+			result = append(result, ResourceDiff{Resource: name, Change: "removed", Diff: unifiedDiff(beforeContent, "")})
+		case beforeContent != afterContent:
+			fields, err := diffYamlFields(beforeContent, afterContent)
+			if err != nil {
+				fields = nil
+			}
+			result = append(result, ResourceDiff{Resource: name, Change: "changed", Diff: unifiedDiff(beforeContent, afterContent), Fields: fields})
+		}
+	}
+	return result, nil
+}
+
+func indexResourceContents(result GeneratorResult) (map[string]string, error) {
+	contents := map[string]string{}
+	var collect func(dir string, result GeneratorResult) error
+	collect = func(dir string, result GeneratorResult) error {
+		for _, resource := range result.Resources {
+			id, err := identifyResource(resource)
+			if err != nil {
+				return fmt.Errorf("reading resource %s failed: %v", resource.File, err)
+			}
+			name := id.String()
+			if dir != "." {
+				name = dir + ": " + name
+			}
+			contents[name] = resource.Content
+		}
+		for _, child := range result.Children {
+			err := collect(path.Join(dir, child.Dir), child.Result)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err := collect(".", result)
+	if err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
@@ -0,0 +1,286 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
+)
+
+type FieldChange struct {
+	Path   string
+	Change string
+	Before string
+	After  string
+}
+
+func diffYamlFields(before string, after string) ([]FieldChange, error) {
+	beforeNode := yaml.Node{}
+	err := yaml.Unmarshal([]byte(before), &beforeNode)
+	if err != nil {
+		return nil, err
+	}
+	afterNode := yaml.Node{}
+	err = yaml.Unmarshal([]byte(after), &afterNode)
+	if err != nil {
+		return nil, err
+	}
+	changes := []FieldChange{}
+	collectFieldChanges("", documentRoot(&beforeNode), documentRoot(&afterNode), &changes)
+	return changes, nil
+}
+
+func documentRoot(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return node.Content[0]
+	}
+	if node.Kind == 0 {
+		return nil
+	}
+	return node
+}
+
+func collectFieldChanges(prefix string, before *yaml.Node, after *yaml.Node, changes *[]FieldChange) {
+	switch {
+	case before == nil && after == nil:
+		return
+	case before == nil:
+		*changes = append(*changes, FieldChange{Path: fieldPathOrRoot(prefix), Change: "added", After: formatFieldValue(after)})
+		return
+	case after == nil:
+		*changes = append(*changes, FieldChange{Path: fieldPathOrRoot(prefix), Change: "removed", Before: formatFieldValue(before)})
+		return
+	}
+
+	if before.Kind == yaml.MappingNode && after.Kind == yaml.MappingNode {
+		beforeValues := map[string]*yaml.Node{}
+		keys := []string{}
+		for i := 0; i+1 < len(before.Content); i += 2 {
+			beforeValues[before.Content[i].Value] = before.Content[i+1]
+			keys = append(keys, before.Content[i].Value)
+		}
+		afterValues := map[string]*yaml.Node{}
+		for i := 0; i+1 < len(after.Content); i += 2 {
+			afterValues[after.Content[i].Value] = after.Content[i+1]
+			if _, ok := beforeValues[after.Content[i].Value]; !ok {
+				keys = append(keys, after.Content[i].Value)
+			}
+		}
+		for _, key := range keys {
+			collectFieldChanges(joinFieldPath(prefix, key), beforeValues[key], afterValues[key], changes)
+		}
+		return
+	}
+
+	if before.Kind == yaml.SequenceNode && after.Kind == yaml.SequenceNode {
+		beforeNames, beforeOk := sequenceItemNames(before)
+		afterNames, afterOk := sequenceItemNames(after)
+		if beforeOk && afterOk {
+			names := []string{}
+			beforeItems := map[string]*yaml.Node{}
+			for i, name := range beforeNames {
+				beforeItems[name] = before.Content[i]
+				names = append(names, name)
+			}
+			afterItems := map[string]*yaml.Node{}
+			for i, name := range afterNames {
+				afterItems[name] = after.Content[i]
+				if _, ok := beforeItems[name]; !ok {
+					names = append(names, name)
+				}
+			}
+			for _, name := range names {
+				collectFieldChanges(fmt.Sprintf("%s[name=%s]", prefix, name), beforeItems[name], afterItems[name], changes)
+			}
+			return
+		}
+		for i := 0; i < len(before.Content) || i < len(after.Content); i++ {
+			var beforeItem, afterItem *yaml.Node
+			if i < len(before.Content) {
+				beforeItem = before.Content[i]
+			}
+			if i < len(after.Content) {
+				afterItem = after.Content[i]
+			}
+			collectFieldChanges(fmt.Sprintf("%s[%d]", prefix, i), beforeItem, afterItem, changes)
+		}
+		return
+	}
+
+	beforeValue := formatFieldValue(before)
+	afterValue := formatFieldValue(after)
+	if beforeValue != afterValue {
+		*changes = append(*changes, FieldChange{Path: fieldPathOrRoot(prefix), Change: "changed", Before: beforeValue, After: afterValue})
+	}
+}
+
+func sequenceItemNames(node *yaml.Node) ([]string, bool) {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, false
+		}
+		name := ""
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "name" && item.Content[i+1].Kind == yaml.ScalarNode {
+				name = item.Content[i+1].Value
+			}
+		}
+		if name == "" || seen[name] {
+			return nil, false
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, len(names) > 0
+}
+
+func joinFieldPath(prefix string, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return fmt.Sprintf("%s[%q]", prefix, key)
+	}
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func fieldPathOrRoot(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+func formatFieldValue(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		if strings.Contains(node.Value, "\n") {
+			return node.Value
+		}
+		if node.Tag == "!!str" && node.Value == "" {
+			return `""`
+		}
+		return node.Value
+	}
+	flow := copyYamlNode(node)
+	flow.Style = yaml.FlowStyle
+	bytes, err := yaml.Marshal(flow)
+	if err != nil {
+		return node.Value
+	}
+	return strings.TrimSpace(string(bytes))
+}
+
+func formatFieldChanges(changes []FieldChange, indent string, enabled bool) string {
+	result := strings.Builder{}
+	for _, change := range changes {
+		multiline := strings.Contains(change.Before, "\n") || strings.Contains(change.After, "\n")
+		switch {
+		case multiline && change.Change != "changed":
+			marker, color, value := "+", colorGreen, change.After
+			if change.Change == "removed" {
+				marker, color, value = "-", colorRed, change.Before
+			}
+			result.WriteString(colorize(fmt.Sprintf("%s%s %s: |\n", indent, marker, change.Path), color, enabled))
+			for _, line := range splitDiffLines(value) {
+				result.WriteString(colorize(fmt.Sprintf("%s    %s %s\n", indent, marker, line), color, enabled))
+			}
+		case change.Change == "added":
+			result.WriteString(colorize(fmt.Sprintf("%s+ %s: %s\n", indent, change.Path, change.After), colorGreen, enabled))
+		case change.Change == "removed":
+			result.WriteString(colorize(fmt.Sprintf("%s- %s: %s\n", indent, change.Path, change.Before), colorRed, enabled))
+		case multiline:
+			result.WriteString(colorize(fmt.Sprintf("%s~ %s:\n", indent, change.Path), colorYellow, enabled))
+			for _, line := range splitDiffLines(unifiedDiff(change.Before, change.After)) {
+				result.WriteString(colorizeDiffLine(indent+"    "+line, line, enabled) + "\n")
+			}
+		default:
+			result.WriteString(colorize(fmt.Sprintf("%s~ %s: %s -> %s\n", indent, change.Path, change.Before, change.After), colorYellow, enabled))
+		}
+	}
+	return result.String()
+}
+
+func colorize(s string, color string, enabled bool) string {
+	if !enabled {
+		return s
+	}
+	trimmed := strings.TrimSuffix(s, "\n")
+	return color + trimmed + colorReset + s[len(trimmed):]
+}
+
+func colorizeDiffLine(s string, line string, enabled bool) string {
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return colorize(s, colorGreen, enabled)
+	case strings.HasPrefix(line, "-"):
+		return colorize(s, colorRed, enabled)
+	default:
+		return s
+	}
+}
+
+func FormatResourceDiffs(diffs []ResourceDiff, color bool) string {
+	result := strings.Builder{}
+	for _, diff := range diffs {
+		result.WriteString(formatChangeHeader(diff.Resource, diff.Change, color))
+		if diff.Change == "changed" {
+			if diff.Fields != nil {
+				result.WriteString(formatFieldChanges(diff.Fields, "    ", color))
+			} else {
+				result.WriteString(formatUnifiedDiff(diff.Diff, "    ", color))
+			}
+		}
+	}
+	return result.String()
+}
+
+func FormatFileChangesFields(dir string, changes []FileChange, color bool) string {
+	result := strings.Builder{}
+	for _, change := range changes {
+		file := path.Join(filepath.ToSlash(dir), change.File)
+		result.WriteString(formatChangeHeader(file, change.Change, color))
+		if change.Change != "changed" {
+			continue
+		}
+		fields, err := diffYamlFields(string(change.Committed), string(change.Rendered))
+		if err != nil {
+			result.WriteString(formatUnifiedDiff(unifiedDiff(string(change.Committed), string(change.Rendered)), "    ", color))
+			continue
+		}
+		result.WriteString(formatFieldChanges(fields, "    ", color))
+	}
+	return result.String()
+}
+
+func formatChangeHeader(name string, change string, color bool) string {
+	switch change {
+	case "added":
+		return colorize(fmt.Sprintf("+ %s (added)\n", name), colorGreen, color)
+	case "removed":
+		return colorize(fmt.Sprintf("- %s (removed)\n", name), colorRed, color)
+	default:
+		return colorize(fmt.Sprintf("~ %s (%s)\n", name, change), colorYellow, color)
+	}
+}
+
+func formatUnifiedDiff(diff string, indent string, color bool) string {
+	result := strings.Builder{}
+	for _, line := range splitDiffLines(diff) {
+		result.WriteString(colorizeDiffLine(indent+line, line, color) + "\n")
+	}
+	return result.String()
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffYamlFields(t *testing.T) {
+	before := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app.kubernetes.io/version: "1.0"
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app:1.0
+        - name: sidecar
+          image: sidecar:1.0
+      tolerations:
+        - key: a
+`
+	after := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app.kubernetes.io/version: "1.1"
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app:1.1
+          args: [--verbose]
+      tolerations:
+        - key: a
+        - key: b
+`
+	changes, err := diffYamlFields(before, after)
+	assert.NoError(t, err)
+	assert.Equal(t, []FieldChange{
+		{Path: `metadata.labels["app.kubernetes.io/version"]`, Change: "changed", Before: "1.0", After: "1.1"},
+		{Path: "spec.replicas", Change: "changed", Before: "1", After: "2"},
+		{Path: "spec.template.spec.containers[name=app].image", Change: "changed", Before: "app:1.0", After: "app:1.1"},
+		{Path: "spec.template.spec.containers[name=app].args", Change: "added", After: "[--verbose]"},
+		{Path: "spec.template.spec.containers[name=sidecar]", Change: "removed", Before: "{name: sidecar, image: 'sidecar:1.0'}"},
+		{Path: "spec.template.spec.tolerations[1]", Change: "added", After: "{key: b}"},
+	}, changes)
+
+	_, err = diffYamlFields("a: [", "a: 1")
+	assert.Error(t, err)
+}
+
+func TestFormatFieldChanges(t *testing.T) {
+	changes := []FieldChange{
+		{Path: "spec.replicas", Change: "changed", Before: "1", After: "2"},
+		{Path: "data.script", Change: "changed", Before: "echo a\necho b\n", After: "echo a\necho c\n"},
+		{Path: "data.new", Change: "added", After: "value"},
+	}
+	assert.Equal(t, "  ~ spec.replicas: 1 -> 2\n  ~ data.script:\n      @@ -1,2 +1,2 @@\n       echo a\n      -echo b\n      +echo c\n  + data.new: value\n", formatFieldChanges(changes, "  ", false))
+	assert.Equal(t, "\033[32m+ data.new: value\033[0m\n", formatFieldChanges(changes[2:], "", true))
+}
+
+func TestFormatFileChangesFields(t *testing.T) {
+	assert.Equal(t, "~ app/kustomization.yaml (changed)\n    + resources[0]: resources\n+ app/resources/new.yaml (added)\n", FormatFileChangesFields("app", []FileChange{
+		{File: "kustomization.yaml", Change: "changed", Committed: []byte("resources: []\n"), Rendered: []byte("resources:\n  - resources\n")},
+		{File: "resources/new.yaml", Change: "added", Rendered: []byte("new")},
+	}, false))
+}
+
+func TestFormatResourceDiffs(t *testing.T) {
+	assert.Equal(t, "- ConfigMap old (removed)\n~ Secret database (changed)\n    + data: {}\n~ ConfigMap raw (changed)\n    @@ -1,1 +1,1 @@\n    -a\n    +b\n", FormatResourceDiffs([]ResourceDiff{
+		{Resource: "ConfigMap old", Change: "removed"},
+		{Resource: "Secret database", Change: "changed", Fields: []FieldChange{{Path: "data", Change: "added", After: "{}"}}},
+		{Resource: "ConfigMap raw", Change: "changed", Diff: "@@ -1,1 +1,1 @@\n-a\n+b\n"},
+	}, false))
+}
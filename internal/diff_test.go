@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Equal(t, "", unifiedDiff("a\nb\n", "a\nb\n"))
+	assert.Equal(t, "@@ -1,3 +1,3 @@\n a\n-b\n+c\n d\n", unifiedDiff("a\nb\nd\n", "a\nc\nd\n"))
+	assert.Equal(t, "@@ -0,0 +1,2 @@\n+a\n+b\n", unifiedDiff("", "a\nb\n"))
+	assert.Equal(t, "@@ -2,7 +2,6 @@\n 2\n 3\n 4\n-5\n 6\n 7\n 8\n@@ -10,4 +9,4 @@\n 10\n 11\n 12\n-13\n+x\n", unifiedDiff(
+		"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n",
+		"1\n2\n3\n4\n6\n7\n8\n9\n10\n11\n12\nx\n",
+	))
+}
+
+func TestDiffGeneratorResults(t *testing.T) {
+	before := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "database-secret.yaml", Content: mockResource("Secret", "database")},
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "old-configmap.yaml", Content: mockResource("ConfigMap", "old")},
+		},
+	}
+	after := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "database-secret.yaml", Content: mockResource("Secret", "database") + "data: {}\n"},
+		},
+		Children: []GeneratorResultChild{
+			{Dir: "app", Result: GeneratorResult{Resources: []GeneratorResource{
+				{ApiVersion: "v1", Kind: "ConfigMap", File: "new-configmap.yaml", Content: mockResource("ConfigMap", "new")},
+			}}},
+		},
+	}
+	diffs, err := diffGeneratorResults(before, after)
+	assert.NoError(t, err)
+	if assert.Len(t, diffs, 3) {
+		assert.Equal(t, "ConfigMap old", diffs[0].Resource)
+		assert.Equal(t, "removed", diffs[0].Change)
+		assert.Equal(t, "Secret database", diffs[1].Resource)
+		assert.Equal(t, "changed", diffs[1].Change)
+		assert.Contains(t, diffs[1].Diff, "+data: {}\n")
+		assert.Equal(t, []FieldChange{{Path: "data", Change: "added", After: "{}"}}, diffs[1].Fields)
+		assert.Equal(t, "app: ConfigMap new", diffs[2].Resource)
+		assert.Equal(t, "added", diffs[2].Change)
+	}
+}
@@ -0,0 +1,33 @@
+package internal
+
+func DiffVersions(dir string, fromVersion string, toVersion string, opts RunOptions) ([]ResourceDiff, error) {
+	config, err := loadRunConfig(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	generator, ok := config.Generator.(HelmGenerator)
+	if !ok {
+		return nil, configErrorf("comparing versions is only supported for helm generators, not %s", config.Type)
+	}
+	if fromVersion == "" {
+		fromVersion = generator.Version
+	}
+
+	renderVersion := func(version string) (*GeneratorResult, error) {
+		versionGenerator := generator
+		versionGenerator.Version = version
+		versionConfig := *config
+		versionConfig.Generator = versionGenerator
+		_, result, err := renderConfig(dir, versionConfig, opts)
+		return result, err
+	}
+	before, err := renderVersion(fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	after, err := renderVersion(toVersion)
+	if err != nil {
+		return nil, err
+	}
+	return diffGeneratorResults(*before, *after)
+}
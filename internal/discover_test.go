@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindConfigDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"vendors/b", "vendors/a", "vendors/a/nested", ".git/vendors/c", "other"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0o755))
+	}
+	for _, dir := range []string{"vendors/b", "vendors/a", "vendors/a/nested", ".git/vendors/c"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(root, dir, configFile), []byte("type: download\n"), 0o644))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "other", "kustomization.yaml"), []byte(""), 0o644))
+
+	dirs, err := FindConfigDirs(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "vendors/a"),
+		filepath.Join(root, "vendors/a/nested"),
+		filepath.Join(root, "vendors/b"),
+	}, dirs)
+}
+
+func TestWriteKeepsNestedConfigDirs(t *testing.T) {
+	fsys := NewMemoryFS()
+	assert.NoError(t, fsys.WriteFile("nested/"+configFile, []byte("type: download\n"), 0o644))
+	assert.NoError(t, fsys.WriteFile("nested/kustomization.yaml", []byte("resources: []\n"), 0o644))
+
+	_, err := write(fsys, GeneratorResult{}, writeOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, fsys.Files(), "nested/"+configFile)
+	assert.Contains(t, fsys.Files(), "nested/kustomization.yaml")
+}
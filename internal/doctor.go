@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	DoctorStatusOk      = "ok"
+	DoctorStatusWarning = "warning"
+	DoctorStatusError   = "error"
+)
+
+type DoctorFinding struct {
+	Check   string
+	Status  string
+	Message string
+}
+
+func Doctor(root string, opts RunOptions) ([]DoctorFinding, error) {
+	findings := []DoctorFinding{}
+	add := func(check string, status string, format string, args ...interface{}) {
+		findings = append(findings, DoctorFinding{Check: check, Status: status, Message: fmt.Sprintf(format, args...)})
+	}
+
+	ctx, err := newGeneratorContext(root, Config{}, opts)
+	if err != nil {
+		add("repositories", DoctorStatusError, "%v", err)
+		return findings, nil
+	}
+	helmPath, err := HelmGenerator{}.resolveHelm(ctx)
+	if err != nil {
+		add("helm", DoctorStatusError, "%v (install helm, pass --helm-bin or set helmVersion to download a managed helm)", err)
+	} else if version, err := detectHelmVersion(ctx, helmPath); err != nil {
+		add("helm", DoctorStatusWarning, "%s found, but its version could not be detected: %v", helmPath, err)
+	} else {
+		add("helm", DoctorStatusOk, "%s (v%d.%d.%d)", helmPath, version.Major, version.Minor, version.Patch)
+	}
+
+	cacheDir, err := ctx.cacheDir()
+	if err != nil {
+		add("cache", DoctorStatusError, "unable to determine cache directory: %v", err)
+	} else if err := checkDirWritable(cacheDir, true); err != nil {
+		add("cache", DoctorStatusError, "%s is not writable: %v (pass --cache-dir to use another directory)", cacheDir, err)
+	} else {
+		add("cache", DoctorStatusOk, "%s is writable", cacheDir)
+	}
+
+	dirs, err := FindConfigDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		add("configs", DoctorStatusWarning, "no %s found below %s", configFile, root)
+	}
+	checked := map[string]bool{}
+	for _, dir := range dirs {
+		config, err := loadRunConfig(dir, opts)
+		if err != nil {
+			add("config "+dir, DoctorStatusError, "%v", err)
+			continue
+		}
+		if err := checkDirWritable(dir, false); err != nil {
+			add("config "+dir, DoctorStatusError, "directory is not writable: %v", err)
+		} else {
+			add("config "+dir, DoctorStatusOk, "%s configuration is valid and directory is writable", config.Type)
+		}
+		dirCtx, err := newGeneratorContext(dir, *config, opts)
+		if err != nil {
+			add("config "+dir, DoctorStatusError, "%v", err)
+			continue
+		}
+		for _, generator := range collectHelmGenerators(config.Generator) {
+			if generator.Registry == "" {
+				continue
+			}
+			repository, err := dirCtx.resolveRepository(generator.Registry)
+			if err != nil {
+				add("registry "+generator.Registry, DoctorStatusError, "%v", err)
+				continue
+			}
+			if generator.AuthEnv != "" {
+				repository.AuthEnv = generator.AuthEnv
+				repository.Username = ""
+				repository.Password = ""
+			}
+			key := strings.Join([]string{repository.Url, repository.Username, repository.AuthEnv}, "\x00")
+			if checked[key] {
+				continue
+			}
+			checked[key] = true
+			status, message := checkRegistry(dirCtx, *repository)
+			add("registry "+repository.Url, status, "%s", message)
+		}
+	}
+	return findings, nil
+}
+
+func collectHelmGenerators(generator Generator) []HelmGenerator {
+	switch g := generator.(type) {
+	case HelmGenerator:
+		return []HelmGenerator{g}
+	case MultiGenerator:
+		result := []HelmGenerator{}
+		for _, entry := range g.Generators {
+			result = append(result, collectHelmGenerators(entry.Generator)...)
+		}
+		return result
+	}
+	return nil
+}
+
+func checkRegistry(ctx GeneratorContext, repository Repository) (string, string) {
+	if err := repository.checkAuthEnv(); err != nil {
+		return DoctorStatusError, err.Error()
+	}
+	switch {
+	case strings.HasPrefix(repository.Url, "https://"):
+		resp, err := ctx.repositoryGet(repository, strings.TrimSuffix(repository.Url, "/")+"/index.yaml")
+		if err != nil {
+			return DoctorStatusError, fmt.Sprintf("unreachable: %v (check network access, proxy and caBundle settings)", err)
+		}
+		resp.Body.Close()
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return DoctorStatusError, fmt.Sprintf("credentials were rejected with status code %d (check repositories.yaml, .netrc or authEnv)", resp.StatusCode)
+		case resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound:
+			return DoctorStatusError, fmt.Sprintf("index request failed with status code %d", resp.StatusCode)
+		}
+		return DoctorStatusOk, "reachable"
+	case strings.HasPrefix(repository.Url, "oci://"):
+		return DoctorStatusOk, "oci registries are checked by helm when rendering"
+	case isBucketRegistry(repository.Url):
+		return DoctorStatusOk, "bucket registries are checked by the aws or gcloud CLI when rendering"
+	}
+	return DoctorStatusWarning, "unsupported registry"
+}
+
+func checkDirWritable(dir string, create bool) error {
+	if create {
+		err := os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return err
+		}
+	}
+	file, err := os.CreateTemp(dir, ".kustomization-generator-*-doctor")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+func FormatDoctorFindings(findings []DoctorFinding) string {
+	result := strings.Builder{}
+	for _, finding := range findings {
+		result.WriteString(fmt.Sprintf("[%s] %s: %s\n", finding.Status, finding.Check, finding.Message))
+	}
+	return result.String()
+}
+
+func CountDoctorFindings(findings []DoctorFinding, status string) int {
+	count := 0
+	for _, finding := range findings {
+		if finding.Status == status {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoctor(t *testing.T) {
+	bin := t.TempDir()
+	helm := filepath.Join(bin, "helm")
+	assert.NoError(t, os.WriteFile(helm, []byte("#!/bin/sh\necho v3.12.0\n"), 0o755))
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/private/index.yaml" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("entries: {}\n"))
+	}))
+	defer server.Close()
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o644))
+
+	root := t.TempDir()
+	cacheDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "public"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "public", configFile), []byte("type: helm\nregistry: "+server.URL+"\nchart: app\n"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "private"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "private", configFile), []byte("type: helm\nregistry: "+server.URL+"/private\nchart: app\n"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "broken"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "broken", configFile), []byte("type: unknown\n"), 0o644))
+
+	findings, err := Doctor(root, RunOptions{HelmBin: helm, CacheDir: cacheDir, UserConfig: &UserConfig{CaBundle: caBundle}})
+	assert.NoError(t, err)
+	statuses := map[string]string{}
+	for _, finding := range findings {
+		statuses[finding.Check] = finding.Status
+	}
+	assert.Equal(t, map[string]string{
+		"helm":  DoctorStatusOk,
+		"cache": DoctorStatusOk,
+		"config " + filepath.Join(root, "broken"):  DoctorStatusError,
+		"config " + filepath.Join(root, "private"): DoctorStatusOk,
+		"config " + filepath.Join(root, "public"):  DoctorStatusOk,
+		"registry " + server.URL + "/private":      DoctorStatusError,
+		"registry " + server.URL:                   DoctorStatusOk,
+	}, statuses)
+	assert.Equal(t, 2, CountDoctorFindings(findings, DoctorStatusError))
+}
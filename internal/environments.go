@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+)
+
+type EnvironmentConfig struct {
+	Values map[string]interface{} `yaml:"values"`
+}
+
+func applyEnvironment(config Config, environment string) (*Config, error) {
+	if environment == "" {
+		return &config, nil
+	}
+	overlay, ok := config.Environments[environment]
+	if !ok {
+		names := []string{}
+		for name := range config.Environments {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, configErrorf("environment %s is not defined (available: %s)", environment, strings.Join(names, ", "))
+	}
+	generator, ok := config.Generator.(HelmGenerator)
+	if !ok {
+		return nil, configErrorf("environments are not supported for type %s", config.Type)
+	}
+	generator.Values = mergeValuesWithListMerge(generator.Values, overlay.Values, generator.ListMerge, "")
+	config.Generator = generator
+	return &config, nil
+}
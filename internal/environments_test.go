@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyEnvironment(t *testing.T) {
+	config, err := parseConfig([]byte(`type: helm
+chart: app
+values:
+  replicas: 1
+  ingress:
+    enabled: true
+    host: app.staging.example.com
+  debug: true
+environments:
+  prod:
+    values:
+      replicas: 3
+      ingress:
+        host: app.example.com
+      debug: null
+`))
+	assert.NoError(t, err)
+
+	base, err := applyEnvironment(*config, "")
+	assert.NoError(t, err)
+	assert.Equal(t, config.Generator, base.Generator)
+
+	prod, err := applyEnvironment(*config, "prod")
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"replicas": 3,
+			"ingress":  map[string]interface{}{"enabled": true, "host": "app.example.com"},
+		}, prod.Generator.(HelmGenerator).Values)
+	}
+
+	_, err = applyEnvironment(*config, "dev")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "available: prod")
+	}
+}
+
+func TestApplyEnvironmentListMerge(t *testing.T) {
+	config, err := parseConfig([]byte(`type: helm
+chart: app
+values:
+  extraEnv:
+    - name: LOG_LEVEL
+      value: info
+  args: [--base]
+  controller:
+    extraArgs: [--a]
+listMerge:
+  extraEnv: append
+  controller.extraArgs: prepend
+environments:
+  prod:
+    values:
+      extraEnv:
+        - name: REGION
+          value: eu
+      args: [--prod]
+      controller:
+        extraArgs: [--b]
+`))
+	assert.NoError(t, err)
+
+	prod, err := applyEnvironment(*config, "prod")
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]interface{}{
+			"extraEnv": []interface{}{
+				map[string]interface{}{"name": "LOG_LEVEL", "value": "info"},
+				map[string]interface{}{"name": "REGION", "value": "eu"},
+			},
+			"args":       []interface{}{"--prod"},
+			"controller": map[string]interface{}{"extraArgs": []interface{}{"--b", "--a"}},
+		}, prod.Generator.(HelmGenerator).Values)
+	}
+
+	_, err = parseConfig([]byte("type: helm\nchart: app\nlistMerge:\n  extraEnv: merge\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unsupported list merge strategy merge for extraEnv")
+	}
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+type ErrorClass int
+
+const (
+	ErrorClassUnknown ErrorClass = iota
+	ErrorClassConfig
+	ErrorClassNetwork
+	ErrorClassExecution
+	ErrorClassValidation
+	ErrorClassDrift
+	ErrorClassInterrupted
+)
+
+type ClassifiedError struct {
+	Class ErrorClass
+	File  string
+	Err   error
+}
+
+func (e ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+func configErrorf(format string, args ...interface{}) error {
+	return ClassifiedError{Class: ErrorClassConfig, Err: fmt.Errorf(format, args...)}
+}
+
+func networkErrorf(format string, args ...interface{}) error {
+	return ClassifiedError{Class: ErrorClassNetwork, Err: fmt.Errorf(format, args...)}
+}
+
+func executionErrorf(format string, args ...interface{}) error {
+	return ClassifiedError{Class: ErrorClassExecution, Err: fmt.Errorf(format, args...)}
+}
+
+func validationErrorf(format string, args ...interface{}) error {
+	return ClassifiedError{Class: ErrorClassValidation, Err: fmt.Errorf(format, args...)}
+}
+
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	classified := ClassifiedError{}
+	if !errors.As(err, &classified) {
+		return 1
+	}
+	switch classified.Class {
+	case ErrorClassConfig:
+		return 2
+	case ErrorClassNetwork:
+		return 3
+	case ErrorClassExecution:
+		return 4
+	case ErrorClassValidation:
+		return 5
+	case ErrorClassDrift:
+		return 6
+	case ErrorClassInterrupted:
+		return 130
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, 1, ExitCode(fmt.Errorf("unknown")))
+	assert.Equal(t, 2, ExitCode(configErrorf("config")))
+	assert.Equal(t, 3, ExitCode(fmt.Errorf("unable to run: %w", networkErrorf("network"))))
+	assert.Equal(t, 4, ExitCode(executionErrorf("execution")))
+	assert.Equal(t, 5, ExitCode(validationErrorf("validation")))
+	assert.Equal(t, 6, ExitCode(ClassifiedError{Class: ErrorClassDrift, Err: fmt.Errorf("drift")}))
+	assert.Equal(t, 130, ExitCode(ClassifiedError{Class: ErrorClassInterrupted, Err: fmt.Errorf("interrupted")}))
+}
@@ -0,0 +1,257 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+type ValueOrigin struct {
+	Key       string
+	Value     string
+	Source    string
+	Overrides []string
+}
+
+type ChartValueOrigins struct {
+	Chart   string
+	Version string
+	Release string
+	Values  []ValueOrigin
+}
+
+type explainedPlaceholder string
+
+type valuesLayer struct {
+	source string
+	tree   map[string]interface{}
+	paths  map[string]interface{}
+}
+
+func ExplainValues(dir string, opts RunOptions) ([]ChartValueOrigins, error) {
+	config, err := loadRunConfig(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	generators := collectHelmGenerators(config.Generator)
+	if len(generators) == 0 {
+		return nil, configErrorf("explaining values is only supported for helm charts")
+	}
+	baseOpts := opts
+	baseOpts.Environment = ""
+	baseConfig, err := loadRunConfig(dir, baseOpts)
+	if err != nil {
+		return nil, err
+	}
+	baseGenerators := collectHelmGenerators(baseConfig.Generator)
+	ctx, err := newGeneratorContext(dir, *config, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := []ChartValueOrigins{}
+	for i, generator := range generators {
+		layers := []valuesLayer{{source: "values", tree: baseGenerators[i].Values}}
+		if opts.Environment != "" {
+			layers = append(layers, valuesLayer{source: "environment " + opts.Environment, tree: config.Environments[opts.Environment].Values})
+		}
+		err := generator.withChartDir(ctx, func(chartDir string, source GeneratorSource) error {
+			defaults := map[string]interface{}{}
+			err := readYamlFile(filepath.Join(chartDir, "values.yaml"), &defaults)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("reading chart values failed: %v", err)
+			}
+			for _, variant := range generator.valuesVariants() {
+				release := variant.release
+				if release == "" {
+					release = source.Chart
+				}
+				variantLayers := append(append([]valuesLayer{{source: "chart default", tree: defaults}}, layers...), variant.layers...)
+				variantLayers = append(variantLayers, generator.pathValuesLayers()...)
+				result = append(result, ChartValueOrigins{
+					Chart:   source.Chart,
+					Version: source.Version,
+					Release: release,
+					Values:  explainValuesLayers(variantLayers, generator.ListMerge),
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+type valuesVariant struct {
+	release string
+	layers  []valuesLayer
+}
+
+func (g HelmGenerator) valuesVariants() []valuesVariant {
+	variants := []valuesVariant{}
+	for _, instance := range g.Instances {
+		variants = append(variants, valuesVariant{release: instance.Name, layers: []valuesLayer{{source: "instance " + instance.Name, tree: instance.Values}}})
+	}
+	for _, namespace := range g.Namespaces {
+		variants = append(variants, valuesVariant{release: g.Name + " (" + namespace + ")", layers: []valuesLayer{{source: "namespaceValues " + namespace, tree: g.NamespaceValues[namespace]}}})
+	}
+	if len(variants) == 0 {
+		variants = append(variants, valuesVariant{release: g.Name})
+	}
+	return variants
+}
+
+func (g HelmGenerator) pathValuesLayers() []valuesLayer {
+	stringValues := map[string]interface{}{}
+	for key, value := range g.StringValues {
+		stringValues[key] = value
+	}
+	secretValues := map[string]interface{}{}
+	for key := range g.SecretValues {
+		secretValues[key] = explainedPlaceholder("<secret>")
+	}
+	return []valuesLayer{
+		{source: "stringValues", paths: stringValues},
+		{source: "secretValues", paths: secretValues},
+		{source: "set", paths: escapedSetPaths(g.Set)},
+		{source: "setString", paths: escapedSetPaths(g.SetString)},
+		{source: "setFile", paths: escapedSetPaths(explainedSetFiles(g.SetFile))},
+	}
+}
+
+func escapedSetPaths(values map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range values {
+		result[escapeHelmSetKey(key)] = value
+	}
+	return result
+}
+
+func explainedSetFiles(values map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range values {
+		if nested, ok := value.(map[string]interface{}); ok {
+			result[key] = explainedSetFiles(nested)
+		} else {
+			result[key] = explainedPlaceholder(fmt.Sprintf("<file %v>", value))
+		}
+	}
+	return result
+}
+
+func explainValuesLayers(layers []valuesLayer, listMerge map[string]string) []ValueOrigin {
+	merged := map[string]interface{}{}
+	effective := map[string]interface{}{}
+	history := map[string][]string{}
+	for _, layer := range layers {
+		flat := map[string]interface{}{}
+		if layer.tree != nil {
+			merged = mergeValuesWithListMerge(merged, layer.tree, listMerge, "")
+			flattenValues("", layer.tree, flat)
+			mergedFlat := map[string]interface{}{}
+			flattenValues("", merged, mergedFlat)
+			for key := range flat {
+				flat[key] = mergedFlat[key]
+			}
+		}
+		for key, value := range layer.paths {
+			flattenPathValue(key, value, flat)
+		}
+		for key, value := range flat {
+			effective[key] = value
+			history[key] = append(history[key], layer.source)
+		}
+	}
+	keys := []string{}
+	for key := range effective {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := []ValueOrigin{}
+	for _, key := range keys {
+		sources := history[key]
+		result = append(result, ValueOrigin{
+			Key:       key,
+			Value:     formatExplainedValue(effective[key]),
+			Source:    sources[len(sources)-1],
+			Overrides: sources[:len(sources)-1],
+		})
+	}
+	return result
+}
+
+func flattenValues(prefix string, values map[string]interface{}, result map[string]interface{}) {
+	for key, value := range values {
+		path := escapeHelmSetKey(key)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		flattenPathValue(path, value, result)
+	}
+}
+
+func flattenPathValue(path string, value interface{}, result map[string]interface{}) {
+	if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+		flattenValues(path, nested, result)
+		return
+	}
+	result[path] = value
+}
+
+func formatExplainedValue(value interface{}) string {
+	if placeholder, ok := value.(explainedPlaceholder); ok {
+		return string(placeholder)
+	}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(bytes)
+}
+
+func FilterValueOrigins(charts []ChartValueOrigins, prefixes []string) []ChartValueOrigins {
+	if len(prefixes) == 0 {
+		return charts
+	}
+	result := []ChartValueOrigins{}
+	for _, chart := range charts {
+		filtered := chart
+		filtered.Values = nil
+		for _, value := range chart.Values {
+			for _, prefix := range prefixes {
+				if value.Key == prefix || strings.HasPrefix(value.Key, prefix+".") || strings.HasPrefix(value.Key, prefix+"[") {
+					filtered.Values = append(filtered.Values, value)
+					break
+				}
+			}
+		}
+		result = append(result, filtered)
+	}
+	return result
+}
+
+func FormatValueOrigins(charts []ChartValueOrigins) string {
+	result := strings.Builder{}
+	for i, chart := range charts {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+		result.WriteString(fmt.Sprintf("# %s %s (release %s)\n", chart.Chart, chart.Version, chart.Release))
+		w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE\tOVERRIDES")
+		for _, value := range chart.Values {
+			overrides := strings.Join(value.Overrides, ", ")
+			if overrides == "" {
+				overrides = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", value.Key, value.Value, value.Source, overrides)
+		}
+		w.Flush()
+	}
+	return result.String()
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainValues(t *testing.T) {
+	helm := fakeHelm(t, "")
+	chartDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: app\nversion: 1.2.3\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "values.yaml"), []byte("replicaCount: 1\nimage:\n  tag: latest\n  pullPolicy: IfNotPresent\nextraArgs: [--a]\n"), 0o644))
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte(`type: helm
+chart: `+chartDir+`
+name: web
+values:
+  replicaCount: 2
+  image:
+    tag: v1
+  extraArgs: [--b]
+listMerge:
+  extraArgs: append
+set:
+  image:
+    tag: v2
+secretValues:
+  auth.password: vault:secret/app#password
+environments:
+  prod:
+    values:
+      replicaCount: 5
+`), 0o644))
+
+	charts, err := ExplainValues(dir, RunOptions{HelmBin: helm, Environment: "prod"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []ChartValueOrigins{{
+			Chart:   "app",
+			Version: "1.2.3",
+			Release: "web",
+			Values: []ValueOrigin{
+				{Key: "auth.password", Value: "<secret>", Source: "secretValues", Overrides: []string{}},
+				{Key: "extraArgs", Value: `["--a","--b"]`, Source: "values", Overrides: []string{"chart default"}},
+				{Key: "image.pullPolicy", Value: `"IfNotPresent"`, Source: "chart default", Overrides: []string{}},
+				{Key: "image.tag", Value: `"v2"`, Source: "set", Overrides: []string{"chart default", "values"}},
+				{Key: "replicaCount", Value: "5", Source: "environment prod", Overrides: []string{"chart default", "values"}},
+			},
+		}}, charts)
+
+		filtered := FilterValueOrigins(charts, []string{"image"})
+		assert.Len(t, filtered[0].Values, 2)
+		output := FormatValueOrigins(filtered)
+		assert.Contains(t, output, "# app 1.2.3 (release web)\n")
+		assert.Contains(t, output, "chart default, values")
+	}
+}
+
+func TestExplainValuesInstances(t *testing.T) {
+	layers := append([]valuesLayer{{source: "values", tree: map[string]interface{}{"a": 1}}}, HelmGenerator{Instances: []HelmInstance{{Name: "x", Values: map[string]interface{}{"a": 2}}}}.valuesVariants()[0].layers...)
+	assert.Equal(t, []ValueOrigin{{Key: "a", Value: "2", Source: "instance x", Overrides: []string{"values"}}}, explainValuesLayers(layers, nil))
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultExternalSecretKey = "{{ .Namespace }}/{{ .Name }}"
+
+type ExternalSecretsConfig struct {
+	SecretStore     string `yaml:"secretStore"`
+	Kind            string `yaml:"kind"`
+	Key             string `yaml:"key"`
+	RefreshInterval string `yaml:"refreshInterval"`
+}
+
+type externalSecretKeyData struct {
+	Name      string
+	Namespace string
+	Key       string
+}
+
+func convertExternalSecrets(ctx GeneratorContext, result GeneratorResult, config ExternalSecretsConfig) (*GeneratorResult, error) {
+	if config.SecretStore == "" {
+		return nil, configErrorf("externalSecrets is missing secretStore")
+	}
+	kind := config.Kind
+	if kind == "" {
+		kind = "SecretStore"
+	}
+	if kind != "SecretStore" && kind != "ClusterSecretStore" {
+		return nil, configErrorf("unsupported externalSecrets kind %s", kind)
+	}
+	keyTemplate := config.Key
+	if keyTemplate == "" {
+		keyTemplate = defaultExternalSecretKey
+	}
+	key, err := template.New("key").Option("missingkey=error").Parse(keyTemplate)
+	if err != nil {
+		return nil, configErrorf("parsing externalSecrets key template failed: %v", err)
+	}
+	refreshInterval := config.RefreshInterval
+	if refreshInterval == "" {
+		refreshInterval = "1h"
+	}
+
+	return mapGeneratorResources(result, func(resource GeneratorResource) (GeneratorResource, error) {
+		if resource.ApiVersion != "v1" || resource.Kind != "Secret" {
+			return resource, nil
+		}
+		secret := struct {
+			Metadata   KubernetesResourceMetadata `yaml:"metadata"`
+			Type       string                     `yaml:"type"`
+			Data       map[string]string          `yaml:"data"`
+			StringData map[string]string          `yaml:"stringData"`
+		}{}
+		err := yaml.Unmarshal([]byte(resource.Content), &secret)
+		if err != nil {
+			return resource, fmt.Errorf("converting secret %s failed: %v", resource.File, err)
+		}
+		keys := []string{}
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		for k := range secret.StringData {
+			if _, ok := secret.Data[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		data := []map[string]interface{}{}
+		for _, k := range keys {
+			remoteKey := bytes.Buffer{}
+			err := key.Execute(&remoteKey, externalSecretKeyData{Name: secret.Metadata.Name, Namespace: secret.Metadata.Namespace, Key: k})
+			if err != nil {
+				return resource, configErrorf("rendering externalSecrets key for %s failed: %v", resource.File, err)
+			}
+			data = append(data, map[string]interface{}{
+				"secretKey": k,
+				"remoteRef": map[string]interface{}{
+					"key":      remoteKey.String(),
+					"property": k,
+				},
+			})
+		}
+		target := map[string]interface{}{
+			"name":           secret.Metadata.Name,
+			"creationPolicy": "Owner",
+		}
+		if secret.Type != "" && secret.Type != "Opaque" {
+			target["template"] = map[string]interface{}{"type": secret.Type}
+		}
+		metadata := map[string]interface{}{"name": secret.Metadata.Name}
+		if secret.Metadata.Namespace != "" {
+			metadata["namespace"] = secret.Metadata.Namespace
+		}
+		if len(secret.Metadata.Labels) > 0 {
+			metadata["labels"] = secret.Metadata.Labels
+		}
+		externalSecret := map[string]interface{}{
+			"apiVersion": "external-secrets.io/v1beta1",
+			"kind":       "ExternalSecret",
+			"metadata":   metadata,
+			"spec": map[string]interface{}{
+				"refreshInterval": refreshInterval,
+				"secretStoreRef": map[string]interface{}{
+					"name": config.SecretStore,
+					"kind": kind,
+				},
+				"target": target,
+				"data":   data,
+			},
+		}
+		content, err := writeYaml(externalSecret)
+		if err != nil {
+			return resource, fmt.Errorf("converting secret %s failed: %v", resource.File, err)
+		}
+		ctx.log().Info("secret converted", "name", secret.Metadata.Name, "keys", len(keys))
+		return GeneratorResource{
+			ApiVersion: "external-secrets.io/v1beta1",
+			Kind:       "ExternalSecret",
+			File:       strings.TrimSuffix(strings.TrimSuffix(resource.File, ".yaml"), "-secret") + "-externalsecret.yaml",
+			Content:    string(content),
+		}, nil
+	})
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertExternalSecrets(t *testing.T) {
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "app-secret.yaml", Content: `apiVersion: v1
+kind: Secret
+metadata:
+  name: app
+  namespace: prod
+type: kubernetes.io/basic-auth
+data:
+  username: dXNlcg==
+  password: czNjcjN0
+`},
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "app-configmap.yaml", Content: mockResource("ConfigMap", "app")},
+		},
+	}
+	converted, err := convertExternalSecrets(GeneratorContext{}, result, ExternalSecretsConfig{SecretStore: "vault", Kind: "ClusterSecretStore", Key: "apps/{{ .Namespace }}/{{ .Name }}"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, GeneratorResource{
+			ApiVersion: "external-secrets.io/v1beta1",
+			Kind:       "ExternalSecret",
+			File:       "app-externalsecret.yaml",
+			Content: `apiVersion: external-secrets.io/v1beta1
+kind: ExternalSecret
+metadata:
+  name: app
+  namespace: prod
+spec:
+  data:
+    - remoteRef:
+        key: apps/prod/app
+        property: password
+      secretKey: password
+    - remoteRef:
+        key: apps/prod/app
+        property: username
+      secretKey: username
+  refreshInterval: 1h
+  secretStoreRef:
+    kind: ClusterSecretStore
+    name: vault
+  target:
+    creationPolicy: Owner
+    name: app
+    template:
+      type: kubernetes.io/basic-auth
+`,
+		}, converted.Resources[0])
+		assert.Equal(t, result.Resources[1], converted.Resources[1])
+	}
+
+	_, err = convertExternalSecrets(GeneratorContext{}, result, ExternalSecretsConfig{})
+	assert.Error(t, err)
+	_, err = convertExternalSecrets(GeneratorContext{}, result, ExternalSecretsConfig{SecretStore: "vault", Key: "{{ .Unknown }}"})
+	assert.Error(t, err)
+}
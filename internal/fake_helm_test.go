@@ -0,0 +1,17 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeHelm(t *testing.T, script string) string {
+	bin := t.TempDir()
+	helm := filepath.Join(bin, "helm")
+	assert.NoError(t, os.WriteFile(helm, []byte("#!/bin/sh\nif [ \"$1\" = version ]; then echo v3.12.0; exit 0; fi\n"+script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return helm
+}
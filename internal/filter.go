@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ResourceSelector struct {
+	ApiVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Name       string            `yaml:"name"`
+	Namespace  string            `yaml:"namespace"`
+	Labels     map[string]string `yaml:"labels"`
+}
+
+func (s ResourceSelector) Matches(resource KubernetesResource) bool {
+	if s.ApiVersion != "" && s.ApiVersion != resource.ApiVersion {
+		return false
+	}
+	if s.Kind != "" && s.Kind != resource.Kind {
+		return false
+	}
+	if s.Name != "" {
+		if ok, _ := path.Match(s.Name, resource.Metadata.Name); !ok {
+			return false
+		}
+	}
+	if s.Namespace != "" && s.Namespace != resource.Metadata.Namespace {
+		return false
+	}
+	for key, value := range s.Labels {
+		if actual, ok := resource.Metadata.Labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+func filterGeneratorResult(result GeneratorResult, include []ResourceSelector, exclude []ResourceSelector) (*GeneratorResult, error) {
+	filtered := result
+	filtered.Resources = nil
+	filtered.Children = nil
+	for _, resource := range result.Resources {
+		kubernetesResource := KubernetesResource{}
+		err := yaml.Unmarshal([]byte(resource.Content), &kubernetesResource)
+		if err != nil {
+			return nil, fmt.Errorf("filtering resource %s failed: %v", resource.File, err)
+		}
+		if len(include) > 0 && !matchesAnyResourceSelector(include, kubernetesResource) {
+			continue
+		}
+		if matchesAnyResourceSelector(exclude, kubernetesResource) {
+			continue
+		}
+		filtered.Resources = append(filtered.Resources, resource)
+	}
+	for _, child := range result.Children {
+		childResult, err := filterGeneratorResult(child.Result, include, exclude)
+		if err != nil {
+			return nil, err
+		}
+		child.Result = *childResult
+		filtered.Children = append(filtered.Children, child)
+	}
+	return &filtered, nil
+}
+
+func matchesAnyResourceSelector(selectors []ResourceSelector, resource KubernetesResource) bool {
+	for _, selector := range selectors {
+		if selector.Matches(resource) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterGeneratorResult(t *testing.T) {
+	backend := "apiVersion: v1\nkind: Service\nmetadata:\n  name: backend\n  labels:\n    tier: backend\n"
+	frontend := "apiVersion: v1\nkind: Service\nmetadata:\n  name: frontend\n  labels:\n    tier: frontend\n"
+	pdb := "apiVersion: policy/v1\nkind: PodDisruptionBudget\nmetadata:\n  name: backend\n  labels:\n    tier: backend\n"
+	input := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Service", File: "backend-service.yaml", Content: backend},
+			{ApiVersion: "v1", Kind: "Service", File: "frontend-service.yaml", Content: frontend},
+			{ApiVersion: "policy/v1", Kind: "PodDisruptionBudget", File: "backend-poddisruptionbudget.yaml", Content: pdb},
+		},
+	}
+
+	actual, err := filterGeneratorResult(input, nil, []ResourceSelector{{Kind: "PodDisruptionBudget"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, input.Resources[0:2], actual.Resources)
+	}
+
+	actual, err = filterGeneratorResult(input, []ResourceSelector{{Labels: map[string]string{"tier": "backend"}}}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []GeneratorResource{input.Resources[0], input.Resources[2]}, actual.Resources)
+	}
+
+	actual, err = filterGeneratorResult(input, []ResourceSelector{{Name: "*end"}}, []ResourceSelector{{Name: "front*"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []GeneratorResource{input.Resources[0], input.Resources[2]}, actual.Resources)
+	}
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing/fstest"
+)
+
+type OutputFS interface {
+	fs.FS
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	MkdirAll(name string, perm fs.FileMode) error
+	Remove(name string) error
+	Chmod(name string, mode fs.FileMode) error
+}
+
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+func NewDirFS(dir string) OutputFS {
+	return dirFS{FS: os.DirFS(dir), dir: dir}
+}
+
+func (f dirFS) path(name string) string {
+	return filepath.Join(f.dir, filepath.FromSlash(name))
+}
+
+func (f dirFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(f.path(name), data, perm)
+}
+
+func (f dirFS) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(f.path(name), perm)
+}
+
+func (f dirFS) Remove(name string) error {
+	return os.Remove(f.path(name))
+}
+
+func (f dirFS) Chmod(name string, mode fs.FileMode) error {
+	return os.Chmod(f.path(name), mode)
+}
+
+type MemoryFS struct {
+	files fstest.MapFS
+}
+
+func NewMemoryFS() *MemoryFS {
+	return &MemoryFS{files: fstest.MapFS{}}
+}
+
+func (f *MemoryFS) Open(name string) (fs.File, error) {
+	return f.files.Open(name)
+}
+
+func (f *MemoryFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	if info, err := fs.Stat(f.files, name); err == nil && info.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrExist}
+	}
+	f.files[name] = &fstest.MapFile{Data: append([]byte{}, data...), Mode: perm}
+	return nil
+}
+
+func (f *MemoryFS) MkdirAll(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	dirs := []string{}
+	for current := name; current != "."; current = path.Dir(current) {
+		dirs = append([]string{current}, dirs...)
+	}
+	for _, current := range dirs {
+		info, err := fs.Stat(f.files, current)
+		if err == nil && !info.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: current, Err: fs.ErrExist}
+		}
+		if err != nil {
+			f.files[current] = &fstest.MapFile{Mode: fs.ModeDir | perm}
+		}
+	}
+	return nil
+}
+
+func (f *MemoryFS) Remove(name string) error {
+	info, err := fs.Stat(f.files, name)
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if info.IsDir() {
+		entries, err := fs.ReadDir(f.files, name)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrExist}
+		}
+	}
+	delete(f.files, name)
+	return nil
+}
+
+func (f *MemoryFS) Chmod(name string, mode fs.FileMode) error {
+	file, ok := f.files[name]
+	if !ok {
+		return &fs.PathError{Op: "chmod", Path: name, Err: fs.ErrNotExist}
+	}
+	file.Mode = file.Mode.Type() | mode.Perm()
+	return nil
+}
+
+func (f *MemoryFS) Files() map[string][]byte {
+	result := map[string][]byte{}
+	for name, file := range f.files {
+		if !file.Mode.IsDir() {
+			result[name] = file.Data
+		}
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryFS(t *testing.T) {
+	fsys := NewMemoryFS()
+	assert.NoError(t, fsys.MkdirAll("a/b", 0o755))
+	assert.NoError(t, fsys.WriteFile("a/b/file.yaml", []byte("content"), 0o644))
+	content, err := fs.ReadFile(fsys, "a/b/file.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+
+	assert.Error(t, fsys.Remove("a/b"))
+	assert.Error(t, fsys.WriteFile("a", []byte{}, 0o644))
+	assert.Error(t, fsys.MkdirAll("a/b/file.yaml/c", 0o755))
+	assert.NoError(t, fsys.Remove("a/b/file.yaml"))
+	assert.NoError(t, fsys.Remove("a/b"))
+	assert.True(t, os.IsNotExist(fsys.Remove("a/b")))
+	assert.Equal(t, map[string][]byte{}, fsys.Files())
+}
+
+func TestWritePrunesStaleDirsInMemoryFS(t *testing.T) {
+	fsys := NewMemoryFS()
+	assert.NoError(t, fsys.WriteFile("stale/file.yaml", []byte("stale"), 0o644))
+
+	stats, err := write(fsys, GeneratorResult{}, writeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, syncStats{Written: 4, Removed: 1}, *stats)
+	_, err = fs.Stat(fsys, "stale")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGenerateIntoOutputFS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mockResource("ConfigMap", "app")))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte(fmt.Sprintf("type: download\nurl: %s\n", server.URL)), 0o644))
+	fsys := NewMemoryFS()
+	assert.NoError(t, Run(dir, RunOptions{OutputFS: fsys}))
+	assert.Equal(t, mockResource("ConfigMap", "app"), string(fsys.Files()["resources/app-configmap.yaml"]))
+	_, err := os.Stat(filepath.Join(dir, "kustomization.yaml"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -1,8 +1,15 @@
 package internal
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -11,7 +18,12 @@ import (
 )
 
 type Kustomization struct {
-	Resources []string `yaml:"resources"`
+	ApiVersion       string            `yaml:"apiVersion,omitempty"`
+	Kind             string            `yaml:"kind,omitempty"`
+	Resources        []string          `yaml:"resources"`
+	Replacements     []interface{}     `yaml:"replacements,omitempty"`
+	SortOptions      *SortOptions      `yaml:"sortOptions,omitempty"`
+	GeneratorOptions *GeneratorOptions `yaml:"generatorOptions,omitempty"`
 }
 
 type GeneratorResource struct {
@@ -23,14 +35,96 @@ type GeneratorResource struct {
 
 type GeneratorResult struct {
 	Resources []GeneratorResource
+	Children  []GeneratorResultChild
+	Files     map[string][]byte
+	Source    *GeneratorSource
+}
+
+type GeneratorSource struct {
+	Chart      string
+	Version    string
+	AppVersion string
+	Digest     string
+}
+
+type GeneratorResultChild struct {
+	Dir    string
+	Result GeneratorResult
+}
+
+func (r GeneratorResult) AllResources() []GeneratorResource {
+	result := append([]GeneratorResource{}, r.Resources...)
+	for _, child := range r.Children {
+		result = append(result, child.Result.AllResources()...)
+	}
+	return result
 }
 
 type Generator interface {
-	Generate() (*GeneratorResult, error)
+	Generate(ctx GeneratorContext) (*GeneratorResult, error)
+}
+
+type GeneratorContext struct {
+	Context       context.Context
+	Timeouts      TimeoutsConfig
+	HelmBin       string
+	CacheDir      string
+	TempDir       string
+	Dir           string
+	Logger        *slog.Logger
+	Progress      *ProgressReporter
+	Repositories  map[string]Repository
+	Transport     http.RoundTripper
+	Proxy         string
+	CaBundle      string
+	HelmArgs      []string
+	Metrics       *Metrics
+	Findings      *ValidationFindings
+	MaxOutput     int64
+	RenderCache   bool
+	BundledCharts bool
+	IndexCache    *HelmIndexCache
+	Strict        bool
+}
+
+type Config struct {
+	Type                string                       `yaml:"type"`
+	Generator           Generator                    `yaml:"-"`
+	Include             []ResourceSelector           `yaml:"include"`
+	Exclude             []ResourceSelector           `yaml:"exclude"`
+	Validate            *ValidationConfig            `yaml:"validate"`
+	Policies            *PolicyConfig                `yaml:"policies"`
+	HelmLabels          *HelmLabelsConfig            `yaml:"helmLabels"`
+	Provenance          *ProvenanceConfig            `yaml:"provenance"`
+	Ownership           *OwnershipConfig             `yaml:"ownership"`
+	PostPatches         []PostPatch                  `yaml:"postPatches"`
+	Normalize           bool                         `yaml:"normalize"`
+	Reproducible        bool                         `yaml:"reproducible"`
+	Timeouts            TimeoutsConfig               `yaml:"timeouts"`
+	OutputDir           string                       `yaml:"outputDir"`
+	ParentKustomization string                       `yaml:"parentKustomization"`
+	Conflicts           string                       `yaml:"conflicts"`
+	Component           bool                         `yaml:"component"`
+	Replacements        []interface{}                `yaml:"replacements"`
+	SortOptions         *SortOptions                 `yaml:"sortOptions"`
+	GeneratorOptions    *GeneratorOptions            `yaml:"generatorOptions"`
+	Permissions         *PermissionsConfig           `yaml:"permissions"`
+	Metadata            bool                         `yaml:"metadata"`
+	Header              bool                         `yaml:"header"`
+	OutputFormat        string                       `yaml:"outputFormat"`
+	ManualEdits         string                       `yaml:"manualEdits"`
+	Environments        map[string]EnvironmentConfig `yaml:"environments"`
+	ExternalSecrets     *ExternalSecretsConfig       `yaml:"externalSecrets"`
+	Seal                *SealConfig                  `yaml:"seal"`
+	Sops                *SopsConfig                  `yaml:"sops"`
+	Hooks               *HooksConfig                 `yaml:"hooks"`
 }
 
 type KubernetesResourceMetadata struct {
-	Name string `yaml:"name"`
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
 }
 
 type KubernetesResource struct {
@@ -43,37 +137,160 @@ func (r KubernetesResource) NonEmpty() bool {
 	return r.ApiVersion != "" && r.Kind != "" && r.Metadata.Name != ""
 }
 
+func LoadConfig(file string) (*Config, error) {
+	bytes, err := readConfigFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(bytes)
+}
+
+func LoadConfigFromReader(reader io.Reader) (*Config, error) {
+	bytesRaw, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := expandConfig(bytesRaw)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(bytes)
+}
+
+func parseConfig(bytes []byte) (*Config, error) {
+	generator, err := parseGenerator(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Config{}
+	err = readYaml(bytes, &result)
+	if err != nil {
+		return nil, err
+	}
+	result.Generator = *generator
+	if result.Seal != nil && result.Sops != nil {
+		return nil, configErrorf("seal and sops are mutually exclusive")
+	}
+	_, err = newOutputModes(result.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if result.SortOptions != nil {
+		err = validateSortOptions(*result.SortOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = validateManualEdits(result.ManualEdits)
+	if err != nil {
+		return nil, err
+	}
+	err = validateOwnership(result.Ownership)
+	if err != nil {
+		return nil, err
+	}
+	err = validateOutputFormat(result)
+	if err != nil {
+		return nil, err
+	}
+	if result.OutputDir != "" {
+		result.OutputDir, err = cleanOutputDir(result.OutputDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &result, nil
+}
+
 func LoadGenerator(file string) (*Generator, error) {
-	bytesRaw, err := os.ReadFile(file)
+	bytes, err := readConfigFile(file)
 	if err != nil {
 		return nil, err
 	}
+	return parseGenerator(bytes)
+}
 
-	var expansionTemp interface{}
-	err = yaml.Unmarshal(bytesRaw, &expansionTemp)
+func readConfigFile(file string) ([]byte, error) {
+	bytesRaw, err := os.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
-	expansionTemp, err = expandenv.ExpandEnv(expansionTemp)
+	return expandConfig(bytesRaw)
+}
+
+func expandConfig(bytesRaw []byte) ([]byte, error) {
+	expansionTemp := yaml.Node{}
+	err := yaml.Unmarshal(bytesRaw, &expansionTemp)
 	if err != nil {
 		return nil, err
 	}
-	bytes, err := yaml.Marshal(expansionTemp)
+	err = expandEnvYamlNode(&expansionTemp)
 	if err != nil {
 		return nil, err
 	}
+	return yaml.Marshal(&expansionTemp)
+}
 
+func expandEnvYamlNode(node *yaml.Node) error {
+	errMsgs := []string{}
+	var recursion func(node *yaml.Node)
+	recursion = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			for i := 1; i < len(node.Content); i += 2 {
+				recursion(node.Content[i])
+			}
+			return
+		}
+		for _, child := range node.Content {
+			recursion(child)
+		}
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!str" || !strings.Contains(node.Value, "${") {
+			return
+		}
+		expanded, err := expandenv.ExpandEnv(node.Value)
+		if err != nil {
+			errMsgs = append(errMsgs, err.Error())
+			return
+		}
+		switch v := expanded.(type) {
+		case string:
+			*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+		case bool:
+			*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprintf("%v", v)}
+		case int, int64:
+			*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprintf("%v", v)}
+		default:
+			*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: fmt.Sprintf("%v", v)}
+		}
+	}
+	recursion(node)
+	if len(errMsgs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errMsgs, ", "))
+	}
+	return nil
+}
+
+func parseGenerator(bytes []byte) (*Generator, error) {
 	var raw map[string]interface{}
-	err = readYaml(bytes, &raw)
+	err := readYaml(bytes, &raw)
 	if err != nil {
 		return nil, err
 	}
 	t, ok := raw["type"].(string)
 	if !ok {
-		return nil, fmt.Errorf("config is missing proper type")
+		return nil, configErrorf("config is missing proper type")
 	}
 
 	var result Generator
+	if t == "cue" {
+		generator := CueGenerator{}
+		err = readYaml(bytes, &generator)
+		if err != nil {
+			return nil, err
+		}
+		result = generator
+	}
 	if t == "download" {
 		generator := DownloadGenerator{}
 		err = readYaml(bytes, &generator)
@@ -88,6 +305,57 @@ func LoadGenerator(file string) (*Generator, error) {
 		if err != nil {
 			return nil, err
 		}
+		err = validateListMerge(generator.ListMerge)
+		if err != nil {
+			return nil, err
+		}
+		err = validateHelmNamespaces(generator)
+		if err != nil {
+			return nil, err
+		}
+		err = validateHelmInstances(generator)
+		if err != nil {
+			return nil, err
+		}
+		err = validateHelmCosign(generator.Cosign)
+		if err != nil {
+			return nil, err
+		}
+		err = validateHelmChartDigest(generator)
+		if err != nil {
+			return nil, err
+		}
+		err = validateHelmLimits(generator.Limits)
+		if err != nil {
+			return nil, err
+		}
+		err = validateHelmRelease(generator.Release)
+		if err != nil {
+			return nil, err
+		}
+		result = generator
+	}
+	if t == "helmfile" {
+		generator := HelmfileGenerator{}
+		err = readYaml(bytes, &generator)
+		if err != nil {
+			return nil, err
+		}
+		result = generator
+	}
+	if t == "multi" {
+		generator, err := parseMultiGenerator(bytes)
+		if err != nil {
+			return nil, err
+		}
+		result = *generator
+	}
+	if t == "oci" {
+		generator := OciGenerator{}
+		err = readYaml(bytes, &generator)
+		if err != nil {
+			return nil, err
+		}
 		result = generator
 	}
 	if t == "kustomize" {
@@ -100,7 +368,7 @@ func LoadGenerator(file string) (*Generator, error) {
 	}
 
 	if result == nil {
-		return nil, fmt.Errorf("config has unknown type %s", t)
+		return nil, configErrorf("config has unknown type %s", t)
 	}
 	return &result, nil
 }
@@ -120,44 +388,159 @@ func splitCombinedKubernetesResources(all string) ([]GeneratorResource, error) {
 	for i, line := range allLines {
 		empty := true
 		for j := start; j < i; j++ {
-			if allLines[j] != "---" && allLines[j] != "" && !strings.HasPrefix(strings.TrimLeft(allLines[j], " \t"), "#") {
+			if isYamlDocumentSeparator(allLines[j]) {
+				if yamlDocumentSeparatorContent(allLines[j]) != "" {
+					empty = false
+					break
+				}
+				continue
+			}
+			if allLines[j] != "" && !strings.HasPrefix(strings.TrimLeft(allLines[j], " \t"), "#") && !isYamlNullDocument(allLines[j]) {
 				empty = false
 				break
 			}
 		}
-		if !empty && strings.HasPrefix(line, seperator) {
+		if !empty && isYamlDocumentSeparator(line) {
 			lines := []string{}
 			for _, line := range allLines[start:i] {
-				if line != seperator {
+				if isYamlDocumentSeparator(line) {
+					if content := yamlDocumentSeparatorContent(line); content != "" {
+						lines = append(lines, content)
+					}
+				} else if !isYamlNullDocument(line) {
 					lines = append(lines, line)
 				}
 			}
 			content := strings.Trim(strings.Join(lines, newLine), "\n \t") + newLine
-			start = i + 1
+			start = i
 
-			kubernetesResource := KubernetesResource{}
-			err := yaml.Unmarshal([]byte(content), &kubernetesResource)
+			contents, err := explodeKubernetesList(content)
 			if err != nil {
 				return result, err
 			}
-			if !kubernetesResource.NonEmpty() {
-				continue
-			}
+			for _, content := range contents {
+				kubernetesResource := KubernetesResource{}
+				err := yaml.Unmarshal([]byte(content), &kubernetesResource)
+				if err != nil {
+					return result, err
+				}
+				if !kubernetesResource.NonEmpty() {
+					continue
+				}
 
-			nameBase := strings.Trim(fmt.Sprintf("%s-%s", kubernetesResource.Metadata.Name, kubernetesResource.Kind), "-")
-			name := getUniqueKubernetesResourceFileName(nameBase, &existingNames)
-			result = append(result, GeneratorResource{
-				ApiVersion: kubernetesResource.ApiVersion,
-				Kind:       kubernetesResource.Kind,
-				File:       name + ".yaml",
-				Content:    content,
-			})
+				nameBase := strings.Trim(fmt.Sprintf("%s-%s", kubernetesResource.Metadata.Name, kubernetesResource.Kind), "-")
+				name := getUniqueKubernetesResourceFileName(nameBase, &existingNames)
+				result = append(result, GeneratorResource{
+					ApiVersion: kubernetesResource.ApiVersion,
+					Kind:       kubernetesResource.Kind,
+					File:       name + ".yaml",
+					Content:    content,
+				})
+			}
 		}
 	}
 
 	return result, nil
 }
 
+func isYamlDocumentSeparator(line string) bool {
+	if line == "---" || line == "..." {
+		return true
+	}
+	return strings.HasPrefix(line, "---") && strings.ContainsRune(" \t#", rune(line[3]))
+}
+
+func yamlDocumentSeparatorContent(line string) string {
+	if line == "..." {
+		return ""
+	}
+	content := strings.TrimLeft(strings.TrimPrefix(line, "---"), " \t")
+	if strings.HasPrefix(content, "#") {
+		return ""
+	}
+	return content
+}
+
+func isYamlNullDocument(line string) bool {
+	return line == "null" || line == "~" || line == "{}"
+}
+
+func explodeKubernetesList(content string) ([]string, error) {
+	list := struct {
+		ApiVersion string      `yaml:"apiVersion"`
+		Kind       string      `yaml:"kind"`
+		Items      []yaml.Node `yaml:"items"`
+	}{}
+	err := yaml.Unmarshal([]byte(content), &list)
+	if err != nil {
+		return nil, err
+	}
+	if list.ApiVersion != "v1" || !strings.HasSuffix(list.Kind, "List") {
+		return []string{content}, nil
+	}
+	result := []string{}
+	for _, item := range list.Items {
+		itemContent, err := writeYaml(&item)
+		if err != nil {
+			return nil, err
+		}
+		exploded, err := explodeKubernetesList(string(itemContent))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, exploded...)
+	}
+	return result, nil
+}
+
+var defaultIncludeFiles = []string{"*.yaml", "*.yml"}
+
+func readKubernetesResourcesFromDir(dir string, includeFiles []string, excludeFiles []string) ([]GeneratorResource, error) {
+	if len(includeFiles) == 0 {
+		includeFiles = defaultIncludeFiles
+	}
+	contents := []string{}
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !matchFilePatterns(name, includeFiles) || matchFilePatterns(name, excludeFiles) {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		contents = append(contents, string(content))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return splitCombinedKubernetesResources(strings.Join(contents, "\n---\n"))
+}
+
+func matchFilePatterns(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		subject := name
+		if !strings.Contains(pattern, "/") {
+			subject = path.Base(name)
+		}
+		if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func getUniqueKubernetesResourceFileName(name string, existing *map[string]int) string {
 	invalidRegex := regexp.MustCompile("[^a-z0-9]+")
 	nameNormalized := invalidRegex.ReplaceAllString(strings.ToLower(name), "-")
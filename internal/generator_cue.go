@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+type CueGenerator struct {
+	Dir        string            `yaml:"dir"`
+	Package    string            `yaml:"package"`
+	Expression string            `yaml:"expression"`
+	Tags       map[string]string `yaml:"tags"`
+	Args       []string          `yaml:"args"`
+}
+
+func (g CueGenerator) Generate(ctx GeneratorContext) (*GeneratorResult, error) {
+	cuePath, err := exec.LookPath("cue")
+	if err != nil {
+		return nil, executionErrorf("executing cue failed: executable not found")
+	}
+	cueArgs := []string{
+		"export",
+	}
+	if g.Package != "" {
+		cueArgs = append(cueArgs, g.Package)
+	}
+	if g.Expression != "" {
+		cueArgs = append(cueArgs, "--expression", g.Expression)
+	}
+	tagKeys := []string{}
+	for key := range g.Tags {
+		tagKeys = append(tagKeys, key)
+	}
+	sort.Strings(tagKeys)
+	for _, key := range tagKeys {
+		cueArgs = append(cueArgs, "--inject", key+"="+g.Tags[key])
+	}
+	cueArgs = append(cueArgs, "--out", "json")
+	cueArgs = append(cueArgs, g.Args...)
+	cmd := exec.Command(cuePath, cueArgs...)
+	cmd.Dir = resolvePath(ctx.Dir, g.Dir)
+	done := ctx.Phase("exporting")
+	cueStdout, cueStderr, err := ctx.runCommand(*cmd)
+	done()
+	if err != nil {
+		return nil, executionErrorf("executing cue failed: %v\n%s", err, string(cueStderr))
+	}
+
+	objects, err := decodeCueOutput(cueStdout)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cue output failed: %v", err)
+	}
+	documents := []string{}
+	for _, object := range objects {
+		document, err := writeYaml(object)
+		if err != nil {
+			return nil, fmt.Errorf("converting cue output failed: %v", err)
+		}
+		documents = append(documents, string(document))
+	}
+
+	resources, err := splitCombinedKubernetesResources(strings.Join(documents, "---\n"))
+	if err != nil {
+		return nil, fmt.Errorf("splitting cue resources failed: %v", err)
+	}
+	result := GeneratorResult{
+		Resources: resources,
+	}
+	return &result, nil
+}
+
+func decodeCueOutput(output []byte) ([]interface{}, error) {
+	objects := []interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, flattenCueObjects(value)...)
+	}
+}
+
+func flattenCueObjects(value interface{}) []interface{} {
+	result := []interface{}{}
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			result = append(result, flattenCueObjects(item)...)
+		}
+	case map[string]interface{}:
+		if _, ok := v["kind"]; ok {
+			return append(result, v)
+		}
+		keys := []string{}
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result = append(result, flattenCueObjects(v[key])...)
+		}
+	}
+	return result
+}
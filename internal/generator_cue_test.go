@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadGeneratorCue(t *testing.T) {
+	c1, err := LoadGenerator("./generator_cue_test.yaml")
+	if assert.NoError(t, err) {
+		c2 := CueGenerator{
+			Dir:        "deploy",
+			Package:    "./apps/...",
+			Expression: "objects",
+			Tags: map[string]string{
+				"env": "production",
+			},
+		}
+		assert.Equal(t, c2, *c1)
+	}
+}
+
+func TestFlattenCueObjects(t *testing.T) {
+	secret := map[string]interface{}{"kind": "Secret"}
+	service := map[string]interface{}{"kind": "Service"}
+	value := map[string]interface{}{
+		"b": []interface{}{service},
+		"a": map[string]interface{}{"x": secret},
+		"c": "ignored",
+	}
+	assert.Equal(t, []interface{}{secret, service}, flattenCueObjects(value))
+}
+
+func TestDecodeCueOutput(t *testing.T) {
+	objects, err := decodeCueOutput([]byte("{\"objects\": {\"a\": {\"kind\": \"Secret\"}}}\n{\"objects\": [{\"kind\": \"Service\"}]}\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"kind": "Secret"},
+		map[string]interface{}{"kind": "Service"},
+	}, objects)
+
+	_, err = decodeCueOutput([]byte("{\"kind\": \"Secret\"} garbage"))
+	assert.Error(t, err)
+}
+
+func TestCueGeneratorMultiplePackages(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho '{\"apiVersion\": \"v1\", \"kind\": \"ConfigMap\", \"metadata\": {\"name\": \"a\"}}'\necho '{\"apiVersion\": \"v1\", \"kind\": \"ConfigMap\", \"metadata\": {\"name\": \"b\"}}'\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "cue"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result, err := CueGenerator{Package: "./apps/..."}.Generate(GeneratorContext{})
+	if assert.NoError(t, err) && assert.Len(t, result.Resources, 2) {
+		assert.Equal(t, "a-configmap.yaml", result.Resources[0].File)
+		assert.Equal(t, "b-configmap.yaml", result.Resources[1].File)
+	}
+}
+
+func TestCueGeneratorDir(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\n[ -f app.cue ] || { echo 'app.cue not found' >&2; exit 1; }\necho '{\"apiVersion\": \"v1\", \"kind\": \"ConfigMap\", \"metadata\": {\"name\": \"a\"}}'\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "cue"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "apps"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "apps", "app.cue"), []byte("package apps\n"), 0o644))
+
+	result, err := CueGenerator{Dir: "apps"}.Generate(GeneratorContext{Dir: dir})
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Resources, 1)
+	}
+}
@@ -2,33 +2,115 @@ package internal
 
 import (
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+const defaultMinHelmVersion = "3.0.0"
+
 type HelmGenerator struct {
-	Registry    string                 `yaml:"registry"`
-	Chart       string                 `yaml:"chart"`
-	Version     string                 `yaml:"version"`
-	Name        string                 `yaml:"name"`
-	Namespace   string                 `yaml:"namespace"`
-	ApiVersions []string               `yaml:"apiVersions"`
-	Args        []string               `yaml:"args"`
-	Values      map[string]interface{} `yaml:"values"`
+	Registry           string                            `yaml:"registry"`
+	Chart              string                            `yaml:"chart"`
+	Version            string                            `yaml:"version"`
+	Name               string                            `yaml:"name"`
+	Namespace          string                            `yaml:"namespace"`
+	Namespaces         []string                          `yaml:"namespaces"`
+	NamespaceValues    map[string]map[string]interface{} `yaml:"namespaceValues"`
+	Instances          []HelmInstance                    `yaml:"instances"`
+	ApiVersions        []string                          `yaml:"apiVersions"`
+	Args               []string                          `yaml:"args"`
+	Values             map[string]interface{}            `yaml:"values"`
+	ListMerge          map[string]string                 `yaml:"listMerge"`
+	ChartVariables     bool                              `yaml:"chartVariables"`
+	StringValues       map[string]string                 `yaml:"stringValues"`
+	Set                map[string]interface{}            `yaml:"set"`
+	SetString          map[string]interface{}            `yaml:"setString"`
+	SetFile            map[string]interface{}            `yaml:"setFile"`
+	CheckValues        string                            `yaml:"checkValues"`
+	Sandbox            *SandboxConfig                    `yaml:"sandbox"`
+	HelmBin            string                            `yaml:"helmBin"`
+	HelmVersion        string                            `yaml:"helmVersion"`
+	MinHelmVersion     string                            `yaml:"minHelmVersion"`
+	HelmVersionCheck   string                            `yaml:"helmVersionCheck"`
+	CreateNamespace    bool                              `yaml:"createNamespace"`
+	NoHooks            bool                              `yaml:"noHooks"`
+	IsUpgrade          bool                              `yaml:"isUpgrade"`
+	Cluster            *HelmClusterConfig                `yaml:"cluster"`
+	SchemaCheck        bool                              `yaml:"schemaCheck"`
+	PreserveChartFiles bool                              `yaml:"preserveChartFiles"`
+	Notes              bool                              `yaml:"notes"`
+	Cosign             *HelmCosignConfig                 `yaml:"cosign"`
+	ShowOnly           []string                          `yaml:"showOnly"`
+	Devel              bool                              `yaml:"devel"`
+	Digest             string                            `yaml:"digest"`
+	SecretValues       map[string]string                 `yaml:"secretValues"`
+	AuthEnv            string                            `yaml:"authEnv"`
+	Lint               bool                              `yaml:"lint"`
+	Limits             *HelmLimitsConfig                 `yaml:"limits"`
+	Release            *HelmReleaseConfig                `yaml:"release"`
+}
+
+type HelmClusterConfig struct {
+	Kubeconfig string `yaml:"kubeconfig"`
+	Context    string `yaml:"context"`
 }
 
-func (g HelmGenerator) Generate() (*GeneratorResult, error) {
-	valuesPath, err := os.CreateTemp("", ".kustomization-generator-*-values.yaml")
+func (g HelmGenerator) Generate(ctx GeneratorContext) (*GeneratorResult, error) {
+	if len(g.Instances) > 0 {
+		return g.generateInstances(ctx)
+	}
+	if len(g.Namespaces) > 0 {
+		return g.generateNamespaces(ctx)
+	}
+	ctx = g.Limits.apply(ctx)
+	if g.Name != "" {
+		err := validateHelmReleaseName(g.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	secrets, err := resolveSecretValues(ctx, g.SecretValues)
+	if err != nil {
+		return nil, err
+	}
+	values := mergeStringValues(mergeStringValues(g.Values, g.StringValues), secrets)
+
+	helmPath, err := g.resolveHelm(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chart, err := g.resolveChart(ctx, helmPath)
+	if err != nil {
+		return nil, err
+	}
+	defer chart.Cleanup()
+	chartArgs := chart.Args
+	localChartDir := chart.LocalDir
+	source := chart.Source
+	if g.ChartVariables {
+		if source.AppVersion == "" {
+			source.AppVersion, err = resolveHelmChartAppVersion(ctx, helmPath, *chart)
+			if err != nil {
+				return nil, err
+			}
+		}
+		values = substituteChartVariables(values, source).(map[string]interface{})
+	}
+
+	valuesPath, err := os.CreateTemp(ctx.TempDir, ".kustomization-generator-*-values.yaml")
 	if err != nil {
 		return nil, fmt.Errorf("writing temporary values file failed: %v", err)
 	}
 	defer os.Remove(valuesPath.Name())
-	valuesBytes, err := yaml.Marshal(g.Values)
+	valuesBytes, err := yaml.Marshal(values)
 	if err != nil {
 		return nil, fmt.Errorf("writing temporary values file failed: %v", err)
 	}
@@ -36,101 +118,675 @@ func (g HelmGenerator) Generate() (*GeneratorResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("writing temporary values file failed: %v", err)
 	}
+	if g.Name == "" {
+		g.Name = source.Chart
+		err := validateHelmReleaseName(g.Name)
+		if err != nil {
+			return nil, err
+		}
+		ctx.log().Info("release name derived from chart", "name", g.Name)
+	}
+
+	g.Args = append(append([]string{}, ctx.HelmArgs...), g.Args...)
+	cacheKey := ""
+	if ctx.RenderCache && len(secrets) == 0 {
+		cacheKey = g.renderCacheKey(ctx, helmPath, *chart, valuesBytes)
+	}
+	helmStdout, cached := loadRenderCache(ctx, cacheKey)
+	files := map[string][]byte{}
+	if !cached {
+		helmStdout, files, err = g.render(ctx, helmPath, chartArgs, localChartDir, values, valuesPath.Name())
+		if err != nil {
+			return nil, err
+		}
+		storeRenderCache(ctx, cacheKey, helmStdout)
+	}
 
-	helmPath, err := exec.LookPath("helm")
+	resources, err := splitCombinedKubernetesResources(string(helmStdout))
 	if err != nil {
-		return nil, fmt.Errorf("executing helm failed: executable not found")
+		return nil, fmt.Errorf("splitting helm resources failed: %v", err)
+	}
+	if g.CreateNamespace {
+		resources, err = prependHelmNamespace(resources, g.Namespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+	result := GeneratorResult{
+		Resources: resources,
+		Files:     files,
+		Source:    &source,
+	}
+	return markSecretResources(result, secrets)
+}
+
+func (g HelmGenerator) render(ctx GeneratorContext, helmPath string, chartArgs []string, localChartDir string, values map[string]interface{}, valuesFile string) ([]byte, map[string][]byte, error) {
+	if g.CheckValues != "" {
+		done := ctx.Phase("values check")
+		unknownKeys, err := checkHelmValues(ctx, helmPath, chartArgs, g.Values)
+		done()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, key := range unknownKeys {
+			ctx.log().Warn("value is not known by chart", "key", key)
+		}
+		if len(unknownKeys) > 0 && g.CheckValues == "error" {
+			return nil, nil, configErrorf("values contain keys not known by chart: %s", strings.Join(unknownKeys, ", "))
+		}
+	}
+
+	chartDir := localChartDir
+	if chartDir == "" && (g.SchemaCheck || g.PreserveChartFiles || g.Notes || g.Lint || g.Release.rewritesTemplates()) {
+		done := ctx.Phase("chart pull")
+		pulledChartDir, cleanup, err := pullHelmChart(ctx, helmPath, chartArgs)
+		done()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer cleanup()
+		chartDir = pulledChartDir
+	}
+	if g.SchemaCheck {
+		done := ctx.Phase("schema check")
+		err := checkHelmValuesSchema(chartDir, g.applySetValues(ctx, values))
+		done()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if g.Lint {
+		done := ctx.Phase("lint")
+		lintStdout, lintStderr, err := ctx.runCommand(*exec.Command(helmPath, g.lintArgs(ctx, chartDir, valuesFile)...))
+		done()
+		if err != nil {
+			return nil, nil, validationErrorf("linting chart failed: %v\n%s%s", err, string(lintStdout), string(lintStderr))
+		}
+	}
+	files := map[string][]byte{}
+	if g.PreserveChartFiles {
+		var err error
+		files, err = readHelmChartFiles(chartDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading chart files failed: %v", err)
+		}
 	}
-	helmArgs := []string{
-		"template",
-		g.Name,
-		"--namespace", g.Namespace,
-		"--values", valuesPath.Name(),
+
+	if g.Notes {
+		done := ctx.Phase("notes")
+		notes, err := g.renderNotes(ctx, helmPath, chartDir, valuesFile)
+		done()
+		if err != nil {
+			return nil, nil, err
+		}
+		if notes != nil {
+			files[helmNotesFile] = notes
+		}
 	}
 
-	if strings.HasPrefix(g.Registry, "oci://") {
-		helmArgs = append(helmArgs, g.Registry, "--version", g.Version)
-	} else if strings.HasPrefix(g.Registry, "https://") {
-		url, err := retrieveHelmChartArchiveUrl(g.Registry, g.Chart, g.Version)
+	if g.Release.rewritesTemplates() {
+		rewrittenChartDir, cleanup, err := g.Release.rewriteChart(ctx, chartDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer cleanup()
+		chartArgs = []string{rewrittenChartDir}
+	}
+
+	helmCmd := exec.Command(helmPath, g.templateArgs(ctx, valuesFile, chartArgs)...)
+	if g.Sandbox != nil {
+		cleanup, err := g.Sandbox.apply(helmCmd, ctx.TempDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("preparing helm sandbox failed: %v", err)
+		}
+		defer cleanup()
+	}
+	done := ctx.Phase("templating")
+	helmStdout, helmStderr, err := ctx.runCommand(*helmCmd)
+	done()
+	if err != nil {
+		return nil, nil, executionErrorf("executing helm failed: %v\n%s", err, string(helmStderr))
+	}
+	return helmStdout, files, nil
+}
+
+type resolvedHelmChart struct {
+	Args     []string
+	LocalDir string
+	Source   GeneratorSource
+	Cleanup  func()
+}
+
+func (g HelmGenerator) resolveChart(ctx GeneratorContext, helmPath string) (*resolvedHelmChart, error) {
+	cleanup := func() {}
+	repository, err := ctx.resolveRepository(g.Registry)
+	if err != nil {
+		return nil, err
+	}
+	if g.AuthEnv != "" {
+		repository.AuthEnv = g.AuthEnv
+		repository.Username = ""
+		repository.Password = ""
+	}
+	if repository.Url != "" && ctx.BundledCharts {
+		bundled, archive, err := loadBundledChart(ctx, repository.Url, g.Chart, g.Version, g.Devel)
+		if err != nil {
+			return nil, err
+		}
+		if bundled != nil && g.Cosign != nil {
+			return nil, configErrorf("bundled chart %s %s cannot be used with cosign verification", bundled.ResolvedChart, bundled.ResolvedVersion)
+		}
+		if bundled != nil {
+			err := checkHelmChartIndexDigest(bundled.ResolvedChart, bundled.ResolvedVersion, bundled.Digest, g.Digest)
+			if err != nil {
+				return nil, err
+			}
+			chartDir, chartCleanup, err := extractHelmChartArchive(ctx, archive)
+			if err != nil {
+				return nil, err
+			}
+			ctx.log().Info("chart restored from bundle", "chart", bundled.ResolvedChart, "version", bundled.ResolvedVersion)
+			return &resolvedHelmChart{Args: []string{chartDir}, LocalDir: chartDir, Source: bundled.source(), Cleanup: chartCleanup}, nil
+		}
+	}
+	err = repository.checkAuthEnv()
+	if err != nil {
+		return nil, err
+	}
+	registry := repository.Url
+	chartArgs := []string{}
+	localChartDir := ""
+	source := GeneratorSource{
+		Chart:   g.Chart,
+		Version: g.Version,
+	}
+	if g.Digest != "" && !strings.HasPrefix(registry, "https://") && !isBucketRegistry(registry) {
+		return nil, configErrorf("digest pinning is only supported for https:// and bucket registries")
+	}
+	if strings.HasPrefix(registry, "oci://") {
+		registryArgs, registryCleanup, err := repository.registryConfigArgs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cleanup = registryCleanup
+		resolved := false
+		defer func() {
+			if !resolved {
+				registryCleanup()
+			}
+		}()
+		chartArgs = append(chartArgs, registry)
+		if g.Version != "" {
+			chartArgs = append(chartArgs, "--version", g.Version)
+		}
+		if g.Devel {
+			chartArgs = append(chartArgs, "--devel")
+		}
+		chartArgs = append(chartArgs, registryArgs...)
+		if source.Chart == "" {
+			source.Chart = path.Base(registry)
+		}
+		if g.Version == "" || isSemverConstraint(g.Version) {
+			stdout, stderr, err := ctx.runCommand(*exec.Command(helmPath, append([]string{"show", "chart"}, chartArgs...)...))
+			if err != nil {
+				return nil, executionErrorf("executing helm failed: %v\n%s", err, string(stderr))
+			}
+			chart := helmLocalChart{}
+			err = yaml.Unmarshal(stdout, &chart)
+			if err != nil {
+				return nil, executionErrorf("parsing chart metadata failed: %v", err)
+			}
+			ctx.log().Info("chart resolved", "chart", source.Chart, "version", chart.Version)
+			err = checkHelmChartDeprecation(ctx, source.Chart, nil, helmRegistryIndexEntry{Version: chart.Version, Deprecated: chart.Deprecated})
+			if err != nil {
+				return nil, err
+			}
+			source.Version = chart.Version
+			source.AppVersion = chart.AppVersion
+			chartArgs = append([]string{registry, "--version", chart.Version}, registryArgs...)
+		}
+		if g.Cosign != nil {
+			chartDir, chartCleanup, err := pullVerifiedHelmChart(ctx, helmPath, *g.Cosign, registry, chartArgs)
+			if err != nil {
+				return nil, err
+			}
+			registryCleanup()
+			cleanup = chartCleanup
+			chartArgs = []string{chartDir}
+			localChartDir = chartDir
+		}
+		resolved = true
+	} else if g.Cosign != nil {
+		return nil, configErrorf("cosign verification is only supported for oci:// registries")
+	} else if strings.HasPrefix(registry, "https://") {
+		done := ctx.Phase("index fetch")
+		entry, urls, err := retrieveHelmChartArchive(ctx, *repository, g.Chart, g.Version, g.Devel)
+		done()
+		if err != nil {
+			return nil, err
+		}
+		ctx.log().Info("chart resolved", "chart", g.Chart, "version", entry.Version, "url", urls[0])
+		err = checkHelmChartIndexDigest(g.Chart, entry.Version, entry.Digest, g.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if repository.AuthEnv != "" || repository.hasCredentials() || len(urls) > 1 || g.Digest != "" {
+			done := ctx.Phase("chart download")
+			chartDir, chartCleanup, err := downloadHelmChartFromMirrors(ctx, urls, func(url string) (string, func(), error) {
+				return downloadHelmChartArchive(ctx, *repository, url, g.Digest)
+			})
+			done()
+			if err != nil {
+				return nil, err
+			}
+			cleanup = chartCleanup
+			chartArgs = append(chartArgs, chartDir)
+			localChartDir = chartDir
+		} else {
+			chartArgs = append(chartArgs, urls[0])
+			if ctx.CaBundle != "" {
+				chartArgs = append(chartArgs, "--ca-file", ctx.CaBundle)
+			}
+		}
+		source.Version = entry.Version
+		source.AppVersion = entry.AppVersion
+		source.Digest = entry.Digest
+	} else if isBucketRegistry(registry) {
+		done := ctx.Phase("chart download")
+		entry, chartDir, chartCleanup, err := retrieveBucketHelmChart(ctx, *repository, g.Chart, g.Version, g.Devel, g.Digest)
+		done()
+		if err != nil {
+			return nil, err
+		}
+		cleanup = chartCleanup
+		ctx.log().Info("chart resolved", "chart", g.Chart, "version", entry.Version)
+		chartArgs = append(chartArgs, chartDir)
+		localChartDir = chartDir
+		source.Version = entry.Version
+		source.AppVersion = entry.AppVersion
+		source.Digest = entry.Digest
+	} else if registry == "" {
+		chartPath := g.Chart
+		if !filepath.IsAbs(chartPath) {
+			chartPath = filepath.Join(ctx.Dir, chartPath)
+		}
+		chartPath, err := filepath.Abs(chartPath)
+		if err != nil {
+			return nil, configErrorf("reading local chart %s failed: %v", g.Chart, err)
+		}
+		chart, err := readHelmLocalChart(chartPath)
+		if err != nil {
+			return nil, configErrorf("reading local chart %s failed: %v", g.Chart, err)
+		}
+		if len(chart.Dependencies) > 0 {
+			dependencyArgs, dependencyCleanup, err := ctx.dependencyBuildArgs(*chart)
+			if err != nil {
+				return nil, err
+			}
+			done := ctx.Phase("dependency build")
+			_, depStderr, err := ctx.runCommand(*exec.Command(helmPath, append([]string{"dependency", "build", chartPath}, dependencyArgs...)...))
+			done()
+			dependencyCleanup()
+			if err != nil {
+				return nil, executionErrorf("executing helm failed: %v\n%s", err, string(depStderr))
+			}
+		}
+		chartArgs = append(chartArgs, chartPath)
+		localChartDir = chartPath
+		source.Chart = chart.Name
+		source.Version = chart.Version
+		source.AppVersion = chart.AppVersion
+		err = checkHelmChartDeprecation(ctx, chart.Name, nil, helmRegistryIndexEntry{Version: chart.Version, Deprecated: chart.Deprecated})
 		if err != nil {
 			return nil, err
 		}
-		helmArgs = append(helmArgs, *url)
 	} else {
-		return nil, fmt.Errorf("unsupported registry %s", g.Registry)
+		return nil, configErrorf("unsupported registry %s", registry)
 	}
+	return &resolvedHelmChart{Args: chartArgs, LocalDir: localChartDir, Source: source, Cleanup: cleanup}, nil
+}
+
+func (g HelmGenerator) templateArgs(ctx GeneratorContext, valuesFile string, chartArgs []string) []string {
+	helmArgs := []string{"template", g.Name}
+	helmArgs = append(helmArgs, g.namespaceArgs()...)
+	helmArgs = append(helmArgs, "--values", valuesFile)
+	helmArgs = append(helmArgs, chartArgs...)
+	helmArgs = append(helmArgs, helmSetArgs("--set", g.Set)...)
+	helmArgs = append(helmArgs, helmSetArgs("--set-string", g.SetString)...)
+	helmArgs = append(helmArgs, helmSetArgs("--set-file", absolutizePaths(ctx.Dir, g.SetFile))...)
 
 	if len(g.ApiVersions) > 0 {
 		helmArgs = append(helmArgs, "--api-versions", strings.Join(g.ApiVersions, ","))
 	}
-	helmArgs = append(helmArgs, g.Args...)
-	helmStdout, helmStderr, err := runCommand(*exec.Command(helmPath, helmArgs...))
+	for _, template := range g.ShowOnly {
+		helmArgs = append(helmArgs, "--show-only", template)
+	}
+	if g.NoHooks {
+		helmArgs = append(helmArgs, "--no-hooks")
+	}
+	if g.IsUpgrade {
+		helmArgs = append(helmArgs, "--is-upgrade")
+	}
+	if g.Release != nil && g.Release.KubeVersion != "" {
+		helmArgs = append(helmArgs, "--kube-version", g.Release.KubeVersion)
+	}
+	if g.Cluster != nil {
+		helmArgs = append(helmArgs, "--validate")
+		if g.Cluster.Kubeconfig != "" {
+			kubeconfig, err := filepath.Abs(resolvePath(ctx.Dir, g.Cluster.Kubeconfig))
+			if err != nil {
+				kubeconfig = g.Cluster.Kubeconfig
+			}
+			helmArgs = append(helmArgs, "--kubeconfig", kubeconfig)
+		}
+		if g.Cluster.Context != "" {
+			helmArgs = append(helmArgs, "--kube-context", g.Cluster.Context)
+		}
+	}
+	return append(helmArgs, g.Args...)
+}
+
+func (g HelmGenerator) lintArgs(ctx GeneratorContext, chartDir string, valuesFile string) []string {
+	helmArgs := []string{"lint", chartDir}
+	helmArgs = append(helmArgs, g.namespaceArgs()...)
+	helmArgs = append(helmArgs, "--values", valuesFile)
+	helmArgs = append(helmArgs, helmSetArgs("--set", g.Set)...)
+	helmArgs = append(helmArgs, helmSetArgs("--set-string", g.SetString)...)
+	return append(helmArgs, helmSetArgs("--set-file", absolutizePaths(ctx.Dir, g.SetFile))...)
+}
+
+func (g HelmGenerator) namespaceArgs() []string {
+	if g.Namespace == "" {
+		return nil
+	}
+	return []string{"--namespace", g.Namespace}
+}
+
+func prependHelmNamespace(resources []GeneratorResource, namespace string) ([]GeneratorResource, error) {
+	if namespace == "" {
+		return nil, configErrorf("createNamespace requires a namespace")
+	}
+	for _, resource := range resources {
+		if resource.ApiVersion != "v1" || resource.Kind != "Namespace" {
+			continue
+		}
+		kubernetesResource := KubernetesResource{}
+		err := yaml.Unmarshal([]byte(resource.Content), &kubernetesResource)
+		if err == nil && kubernetesResource.Metadata.Name == namespace {
+			return resources, nil
+		}
+	}
+	namespaceResources, err := splitCombinedKubernetesResources(fmt.Sprintf("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: %s\n", namespace))
 	if err != nil {
-		return nil, fmt.Errorf("executing helm failed: %v\n%s", err, string(helmStderr))
+		return nil, err
 	}
+	return append(namespaceResources, resources...), nil
+}
 
-	resources, err := splitCombinedKubernetesResources(string(helmStdout))
+func (g HelmGenerator) resolveHelm(ctx GeneratorContext) (string, error) {
+	helmBin := ctx.HelmBin
+	if helmBin == "" {
+		helmBin = g.HelmBin
+	}
+	if helmBin == "" && g.HelmVersion != "" {
+		managedHelmPath, err := ensureManagedHelm(ctx, g.HelmVersion)
+		if err != nil {
+			return "", err
+		}
+		helmBin = managedHelmPath
+	}
+	if helmBin == "" {
+		helmBin = "helm"
+	}
+	helmPath, err := exec.LookPath(helmBin)
 	if err != nil {
-		return nil, fmt.Errorf("splitting helm resources failed: %v", err)
+		return "", executionErrorf("executing helm failed: executable %s not found", helmBin)
 	}
-	result := GeneratorResult{
-		Resources: resources,
+
+	minVersion := g.MinHelmVersion
+	if minVersion == "" {
+		minVersion = defaultMinHelmVersion
+	}
+	version, err := detectHelmVersion(ctx, helmPath)
+	if err == nil {
+		var min *semver
+		min, err = parseSemver(minVersion)
+		if err != nil {
+			return "", configErrorf("invalid minimum helm version %s", minVersion)
+		}
+		if version.Compare(*min) < 0 {
+			err = fmt.Errorf("helm %d.%d.%d at %s is older than the required %s", version.Major, version.Minor, version.Patch, helmPath, minVersion)
+		}
 	}
-	return &result, nil
+	if err != nil {
+		if g.HelmVersionCheck == "warn" {
+			ctx.log().Warn("helm version check failed", "error", err)
+			return helmPath, nil
+		}
+		return "", executionErrorf("checking helm version failed: %v", err)
+	}
+	return helmPath, nil
 }
 
-type helmRegistryIndex struct {
-	ApiVersion string `yaml:"apiVersion"`
-	Entries    map[string][]struct {
-		ApiVersion string   `yaml:"apiVersion"`
-		AppVersion string   `yaml:"appVersion"`
-		Name       string   `yaml:"name"`
-		Version    string   `yaml:"version"`
-		Urls       []string `yaml:"urls"`
-	} `yaml:"entries"`
+func detectHelmVersion(ctx GeneratorContext, helmPath string) (*semver, error) {
+	stdout, stderr, err := ctx.runCommand(*exec.Command(helmPath, "version", "--short"))
+	match := regexp.MustCompile(`v\d+\.\d+\.\d+`).FindString(string(stdout))
+	if match == "" {
+		if err != nil {
+			return nil, fmt.Errorf("%v\n%s", err, string(stderr))
+		}
+		return nil, fmt.Errorf("unable to parse helm version from %q", strings.TrimSpace(string(stdout)))
+	}
+	return parseSemver(match)
 }
 
-func retrieveHelmChartArchiveUrl(registry string, chart string, version string) (*string, error) {
-	url := strings.TrimSuffix(registry, "/") + "/index.yaml"
-	req, err := http.NewRequest("GET", url, nil)
-	client := &http.Client{}
+func helmSetArgs(flag string, values map[string]interface{}) []string {
+	assignments := []string{}
+	var flatten func(prefix string, value interface{})
+	flatten = func(prefix string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, nestedValue := range v {
+				flatten(prefix+"."+escapeHelmSetKey(key), nestedValue)
+			}
+			return
+		case []interface{}:
+			if containsHelmSetCollection(v) {
+				for i, item := range v {
+					flatten(fmt.Sprintf("%s[%d]", prefix, i), item)
+				}
+				return
+			}
+		}
+		assignments = append(assignments, prefix+"="+escapeHelmSetValue(value))
+	}
+	for key, value := range values {
+		flatten(escapeHelmSetKey(key), value)
+	}
+	sort.Strings(assignments)
+
+	result := []string{}
+	for _, assignment := range assignments {
+		result = append(result, flag, assignment)
+	}
+	return result
+}
+
+func containsHelmSetCollection(items []interface{}) bool {
+	for _, item := range items {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}:
+			return true
+		}
+	}
+	return false
+}
+
+func escapeHelmSetKey(key string) string {
+	return strings.NewReplacer(`\`, `\\`, ".", `\.`, ",", `\,`, "=", `\=`, "[", `\[`).Replace(key)
+}
+
+func escapeHelmSetValue(value interface{}) string {
+	str := ""
+	switch v := value.(type) {
+	case nil:
+		str = "null"
+	case []interface{}:
+		items := []string{}
+		for _, item := range v {
+			items = append(items, escapeHelmSetValue(item))
+		}
+		return "{" + strings.Join(items, ",") + "}"
+	default:
+		str = fmt.Sprintf("%v", v)
+	}
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`).Replace(str)
+}
+
+const helmReleaseNameMaxLength = 53
+
+var helmReleaseNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+func validateHelmReleaseName(name string) error {
+	if name == "" {
+		return configErrorf("release name is missing")
+	}
+	if len(name) > helmReleaseNameMaxLength {
+		return configErrorf("release name %s is longer than %d characters", name, helmReleaseNameMaxLength)
+	}
+	if !helmReleaseNameRegex.MatchString(name) {
+		return configErrorf("release name %s is invalid: it must consist of lower case alphanumeric characters, '-' or '.' and start and end with an alphanumeric character", name)
+	}
+	return nil
+}
+
+var helmChartFiles = []string{"Chart.yaml", "LICENSE", "LICENSE.md", "LICENSE.txt"}
+
+func readHelmChartFiles(chartDir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for _, name := range helmChartFiles {
+		content, err := os.ReadFile(filepath.Join(chartDir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[path.Join("chart", name)] = content
+	}
+	return files, nil
+}
+
+type helmLocalChart struct {
+	Name         string `yaml:"name"`
+	Version      string `yaml:"version"`
+	AppVersion   string `yaml:"appVersion"`
+	Deprecated   bool   `yaml:"deprecated"`
+	Dependencies []struct {
+		Name       string `yaml:"name"`
+		Alias      string `yaml:"alias"`
+		Version    string `yaml:"version"`
+		Repository string `yaml:"repository"`
+	} `yaml:"dependencies"`
+}
+
+func readHelmLocalChart(dir string) (*helmLocalChart, error) {
+	chart := helmLocalChart{}
+	err := readYamlFile(filepath.Join(dir, "Chart.yaml"), &chart)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch registry index at %s: %v", url, err)
+		return nil, err
 	}
+	return &chart, nil
+}
+
+type helmRegistryIndex struct {
+	ApiVersion string                              `yaml:"apiVersion"`
+	Entries    map[string][]helmRegistryIndexEntry `yaml:"entries"`
+}
 
-	resp, err := client.Do(req)
+type helmRegistryIndexEntry struct {
+	ApiVersion string   `yaml:"apiVersion"`
+	AppVersion string   `yaml:"appVersion"`
+	Name       string   `yaml:"name"`
+	Version    string   `yaml:"version"`
+	Digest     string   `yaml:"digest"`
+	Urls       []string `yaml:"urls"`
+	Deprecated bool     `yaml:"deprecated"`
+}
+
+func retrieveHelmChartArchive(ctx GeneratorContext, repository Repository, chart string, version string, devel bool) (*helmRegistryIndexEntry, []string, error) {
+	var versions []helmRegistryIndexEntry
+	var base string
+	var ok bool
+	var err error
+	if repository.Type == "chartmuseum" {
+		versions, base, ok, err = retrieveChartMuseumVersions(ctx, repository, chart, version)
+	} else {
+		versions, base, ok, err = retrieveHelmRegistryIndexVersions(ctx, repository, chart)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch registry index at %s: %v", url, err)
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, configErrorf("chart %s could not be found", chart)
 	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	entry, err := selectHelmChartVersion(versions, version, devel)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch registry index at %s: %v", url, err)
+		return nil, nil, configErrorf("chart %s %v", chart, err)
 	}
-	index := helmRegistryIndex{}
-	err = yaml.Unmarshal(body, &index)
+	err = checkHelmChartDeprecation(ctx, chart, versions, *entry)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch registry index at %s: %v", url, err)
+		return nil, nil, err
 	}
+	urls, err := resolveHelmChartUrls(base, chart, *entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry, urls, nil
+}
 
-	versions, ok := index.Entries[chart]
-	if !ok {
-		return nil, fmt.Errorf("chart %s could not be found", chart)
+func resolveHelmChartUrl(base string, chartUrl string) (string, error) {
+	baseUrl, err := url.Parse(base)
+	if err != nil {
+		return "", err
 	}
-	for _, entry := range versions {
-		if entry.Version == version {
-			if len(entry.Urls) == 0 {
-				return nil, fmt.Errorf("chart %s version %s has no download urls", chart, version)
-			}
-			if len(entry.Urls) > 1 {
-				return nil, fmt.Errorf("chart %s version %s has multiple download urls", chart, version)
-			}
-			result := entry.Urls[0]
-			if !strings.HasPrefix(result, "http://") && !strings.HasPrefix(result, "https://") {
-				result = strings.TrimSuffix(registry, "/") + "/" + strings.TrimPrefix(result, "/")
+	ref, err := url.Parse(chartUrl)
+	if err != nil {
+		return "", err
+	}
+	return baseUrl.ResolveReference(ref).String(), nil
+}
+
+func selectHelmChartVersion(entries []helmRegistryIndexEntry, version string, devel bool) (*helmRegistryIndexEntry, error) {
+	if version == "" {
+		version = "*"
+	}
+	if !isSemverConstraint(version) {
+		for _, entry := range entries {
+			if entry.Version == version {
+				return &entry, nil
 			}
-			return &result, nil
 		}
+		return nil, fmt.Errorf("version %s could not be found", version)
+	}
+	constraint, err := parseSemverConstraint(version)
+	if err != nil {
+		return nil, err
+	}
+	var selected *helmRegistryIndexEntry
+	var selectedVersion *semver
+	for i := range entries {
+		entryVersion, err := parseSemver(entries[i].Version)
+		if err != nil || (entryVersion.IsPrerelease() && !devel) || !constraint.Matches(*entryVersion) {
+			continue
+		}
+		if selectedVersion == nil || entryVersion.Compare(*selectedVersion) > 0 {
+			selected = &entries[i]
+			selectedVersion = entryVersion
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("version matching %s could not be found", version)
 	}
-	return nil, fmt.Errorf("chart %s version %s could not be found", chart, version)
+	return selected, nil
 }
@@ -1,6 +1,10 @@
 package internal
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,3 +29,293 @@ func TestLoadGeneratorHelm(t *testing.T) {
 		assert.Equal(t, c2, *c1)
 	}
 }
+
+func TestReadHelmLocalChart(t *testing.T) {
+	chart, err := readHelmLocalChart("./testdata/chart")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "app", chart.Name)
+		assert.Equal(t, "1.2.3", chart.Version)
+		assert.Equal(t, "4.5.6", chart.AppVersion)
+		assert.Len(t, chart.Dependencies, 1)
+		assert.Equal(t, "postgresql", chart.Dependencies[0].Name)
+	}
+}
+
+func TestHelmSetArgs(t *testing.T) {
+	assert.Equal(t, []string{}, helmSetArgs("--set", nil))
+	assert.Equal(t, []string{
+		"--set", `image.tag=1.2`,
+		"--set", `ingress.annotations.kubernetes\.io/ingress\.class=nginx`,
+		"--set", `ingress.hosts={a.com,b.com}`,
+		"--set", `ingress.tls[0].hosts={a.com}`,
+		"--set", `ingress.tls[0].secretName=tls`,
+		"--set", `ingress.tls[1].secretName=other`,
+		"--set", `kubernetes\.io/name\[0]=a=b`,
+		"--set", `matrix[0]={1,2}`,
+		"--set", `replicas=3`,
+		"--set", `tolerations=a\,b`,
+	}, helmSetArgs("--set", map[string]interface{}{
+		"image": map[string]interface{}{
+			"tag": "1.2",
+		},
+		"ingress": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				"kubernetes.io/ingress.class": "nginx",
+			},
+			"hosts": []interface{}{"a.com", "b.com"},
+			"tls": []interface{}{
+				map[string]interface{}{"secretName": "tls", "hosts": []interface{}{"a.com"}},
+				map[string]interface{}{"secretName": "other"},
+			},
+		},
+		"kubernetes.io/name[0]": "a=b",
+		"matrix":                []interface{}{[]interface{}{1, 2}},
+		"replicas":              3,
+		"tolerations":           "a,b",
+	}))
+}
+
+func TestLoadGeneratorHelmStringValues(t *testing.T) {
+	t.Setenv("KUSTOMIZATION_GENERATOR_TEST_REPLICAS", "3")
+	c1, err := LoadGenerator("./generator_helm_string_test.yaml")
+	if assert.NoError(t, err) {
+		c2 := HelmGenerator{
+			Registry: "https://charts.domain.com",
+			Chart:    "chart",
+			Version:  "1.2.3",
+			Values: map[string]interface{}{
+				"quoted": "1.20",
+				"tagged": "1.20",
+				"env":    3,
+			},
+			StringValues: map[string]string{
+				"image.tag": "1.20",
+			},
+		}
+		assert.Equal(t, c2, *c1)
+	}
+}
+
+func TestHelmGeneratorResolveHelm(t *testing.T) {
+	dir := t.TempDir()
+	helm2 := filepath.Join(dir, "helm2")
+	helm3 := filepath.Join(dir, "helm3")
+	assert.NoError(t, os.WriteFile(helm2, []byte("#!/bin/sh\necho 'Client: v2.16.1+gbbdfe5e'\n"), 0o755))
+	assert.NoError(t, os.WriteFile(helm3, []byte("#!/bin/sh\necho 'v3.12.0+gc9f554d'\n"), 0o755))
+
+	path, err := HelmGenerator{}.resolveHelm(GeneratorContext{HelmBin: helm3})
+	assert.NoError(t, err)
+	assert.Equal(t, helm3, path)
+
+	_, err = HelmGenerator{}.resolveHelm(GeneratorContext{HelmBin: helm2})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "older than the required 3.0.0")
+	}
+
+	path, err = HelmGenerator{HelmBin: helm2, HelmVersionCheck: "warn"}.resolveHelm(GeneratorContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, helm2, path)
+
+	_, err = HelmGenerator{HelmBin: helm3, MinHelmVersion: "3.13.0"}.resolveHelm(GeneratorContext{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "older than the required 3.13.0")
+	}
+}
+
+func TestPrependHelmNamespace(t *testing.T) {
+	resources := []GeneratorResource{
+		{ApiVersion: "v1", Kind: "Secret", File: "database-secret.yaml", Content: mockResource("Secret", "database")},
+	}
+	result, err := prependHelmNamespace(resources, "app")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Namespace", File: "app-namespace.yaml", Content: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: app\n"},
+			resources[0],
+		}, result)
+	}
+
+	result, err = prependHelmNamespace(result, "app")
+	if assert.NoError(t, err) {
+		assert.Len(t, result, 2)
+	}
+
+	_, err = prependHelmNamespace(resources, "")
+	assert.EqualError(t, err, "createNamespace requires a namespace")
+}
+
+func TestHelmTemplateArgs(t *testing.T) {
+	g := HelmGenerator{
+		Name:        "name",
+		Namespace:   "namespace",
+		ApiVersions: []string{"a/v1", "b/v1"},
+		Args:        []string{"--include-crds"},
+		ShowOnly:    []string{"templates/deployment.yaml", "templates/service.yaml"},
+		NoHooks:     true,
+		IsUpgrade:   true,
+	}
+	assert.Equal(t, []string{
+		"template", "name",
+		"--namespace", "namespace",
+		"--values", "values.yaml",
+		"chart.tgz",
+		"--api-versions", "a/v1,b/v1",
+		"--show-only", "templates/deployment.yaml",
+		"--show-only", "templates/service.yaml",
+		"--no-hooks",
+		"--is-upgrade",
+		"--include-crds",
+	}, g.templateArgs(GeneratorContext{}, "values.yaml", []string{"chart.tgz"}))
+
+	dir := t.TempDir()
+	g = HelmGenerator{Name: "name", Cluster: &HelmClusterConfig{Kubeconfig: "kubeconfig", Context: "staging"}}
+	assert.Equal(t, []string{
+		"template", "name",
+		"--values", "values.yaml",
+		"chart.tgz",
+		"--validate",
+		"--kubeconfig", filepath.Join(dir, "kubeconfig"),
+		"--kube-context", "staging",
+	}, g.templateArgs(GeneratorContext{Dir: dir}, "values.yaml", []string{"chart.tgz"}))
+}
+
+func TestHelmGeneratorLintArgs(t *testing.T) {
+	g := HelmGenerator{Name: "name", Namespace: "namespace", Set: map[string]interface{}{"a": "b"}, Lint: true}
+	assert.Equal(t, []string{
+		"lint", "chart",
+		"--namespace", "namespace",
+		"--values", "values.yaml",
+		"--set", "a=b",
+	}, g.lintArgs(GeneratorContext{}, "chart", "values.yaml"))
+
+	g = HelmGenerator{Name: "name"}
+	assert.Equal(t, []string{
+		"lint", "chart",
+		"--values", "values.yaml",
+	}, g.lintArgs(GeneratorContext{}, "chart", "values.yaml"))
+}
+
+func TestReadHelmChartFiles(t *testing.T) {
+	files, err := readHelmChartFiles("./testdata/chart")
+	if assert.NoError(t, err) {
+		chart, err := os.ReadFile("./testdata/chart/Chart.yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]byte{"chart/Chart.yaml": chart}, files)
+	}
+}
+
+func TestSelectHelmChartVersion(t *testing.T) {
+	entries := []helmRegistryIndexEntry{
+		{Version: "1.3.0-rc.1"},
+		{Version: "1.2.0"},
+		{Version: "1.2.1"},
+		{Version: "1.1.0"},
+		{Version: "not-semver"},
+	}
+	selected := func(version string, devel bool) string {
+		entry, err := selectHelmChartVersion(entries, version, devel)
+		if err != nil {
+			return err.Error()
+		}
+		return entry.Version
+	}
+	assert.Equal(t, "1.2.0", selected("1.2.0", false))
+	assert.Equal(t, "1.3.0-rc.1", selected("1.3.0-rc.1", false))
+	assert.Equal(t, "not-semver", selected("not-semver", false))
+	assert.Equal(t, "1.2.1", selected("^1.0.0", false))
+	assert.Equal(t, "1.2.1", selected("", false))
+	assert.Equal(t, "1.3.0-rc.1", selected("", true))
+	assert.Equal(t, "1.3.0-rc.1", selected("^1.0.0", true))
+	assert.Equal(t, "1.1.0", selected("~1.1", false))
+	assert.Equal(t, "version 1.4.0 could not be found", selected("1.4.0", false))
+	assert.Equal(t, "version matching ^2.0.0 could not be found", selected("^2.0.0", false))
+}
+
+func TestValidateHelmReleaseName(t *testing.T) {
+	assert.NoError(t, validateHelmReleaseName("cert-manager"))
+	assert.NoError(t, validateHelmReleaseName("app.v2"))
+	assert.Error(t, validateHelmReleaseName(""))
+	assert.Error(t, validateHelmReleaseName("Cert-Manager"))
+	assert.Error(t, validateHelmReleaseName("cert_manager"))
+	assert.Error(t, validateHelmReleaseName("-cert-manager"))
+	assert.Error(t, validateHelmReleaseName(strings.Repeat("a", 54)))
+	err := validateHelmReleaseName("my_app")
+	if assert.Error(t, err) {
+		assert.Equal(t, 2, ExitCode(err))
+	}
+}
+
+func TestResolveHelmChartUrl(t *testing.T) {
+	for _, c := range [][3]string{
+		{"https://charts.example.com/stable/index.yaml", "app-1.0.0.tgz", "https://charts.example.com/stable/app-1.0.0.tgz"},
+		{"https://charts.example.com/stable/index.yaml", "../archive/app-1.0.0.tgz", "https://charts.example.com/archive/app-1.0.0.tgz"},
+		{"https://charts.example.com/stable/index.yaml", "/app-1.0.0.tgz", "https://charts.example.com/app-1.0.0.tgz"},
+		{"https://charts.example.com/stable/index.yaml", "https://cdn.example.com/app-1.0.0.tgz", "https://cdn.example.com/app-1.0.0.tgz"},
+	} {
+		result, err := resolveHelmChartUrl(c[0], c[1])
+		assert.NoError(t, err)
+		assert.Equal(t, c[2], result)
+	}
+}
+
+func TestHelmGeneratorTempDir(t *testing.T) {
+	bin := t.TempDir()
+	helm := filepath.Join(bin, "helm")
+	valuesFile := filepath.Join(bin, "values-file")
+	assert.NoError(t, os.WriteFile(helm, []byte(`#!/bin/sh
+if [ "$1" = version ]; then echo v3.12.0; exit 0; fi
+while [ $# -gt 0 ]; do
+  if [ "$1" = --values ]; then echo "$2" > "`+valuesFile+`"; fi
+  shift
+done
+printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n'
+`), 0o755))
+	chartDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: app\nversion: 1.2.3\n"), 0o644))
+
+	config, err := parseConfig([]byte("type: helm\nchart: " + chartDir + "\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	tempDir := t.TempDir()
+	_, err = config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm, TempDir: tempDir})
+	if assert.NoError(t, err) {
+		file, _ := os.ReadFile(valuesFile)
+		assert.Equal(t, tempDir, filepath.Dir(strings.TrimSpace(string(file))))
+	}
+}
+
+func TestHelmGeneratorLocalChartDependencies(t *testing.T) {
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "missing"))
+	bin := t.TempDir()
+	helm := filepath.Join(bin, "helm")
+	argsFile := filepath.Join(bin, "dependency-args")
+	assert.NoError(t, os.WriteFile(helm, []byte(`#!/bin/sh
+if [ "$1" = version ]; then echo v3.12.0; exit 0; fi
+if [ "$1" = dependency ]; then
+  echo "$@" > "`+argsFile+`"
+  while [ "$1" != --repository-config ]; do shift; done
+  grep -q "username: user" "$2" || exit 1
+  exit 0
+fi
+printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n'
+`), 0o755))
+	dir := t.TempDir()
+	chartDir := filepath.Join(dir, "charts", "umbrella")
+	assert.NoError(t, os.MkdirAll(chartDir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: umbrella\nversion: 1.0.0\ndependencies:\n- name: app\n  version: 1.2.3\n  repository: https://charts.example.com\n"), 0o644))
+
+	config, err := parseConfig([]byte("type: helm\nchart: ./charts/umbrella\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := GeneratorContext{Context: context.Background(), HelmBin: helm, Dir: dir, TempDir: t.TempDir(), Repositories: map[string]Repository{
+		"private": {Url: "https://charts.example.com", Username: "user", Password: "secret"},
+	}}
+	result, err := config.Generator.Generate(ctx)
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Resources, 1)
+		args, _ := os.ReadFile(argsFile)
+		assert.True(t, strings.HasPrefix(string(args), "dependency build "+chartDir+" --repository-config "))
+		assert.NotContains(t, string(args), "secret")
+	}
+}
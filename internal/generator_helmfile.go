@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+type HelmfileGenerator struct {
+	File        string   `yaml:"file"`
+	Environment string   `yaml:"environment"`
+	Selectors   []string `yaml:"selectors"`
+	Args        []string `yaml:"args"`
+}
+
+type helmfileRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Enabled   bool   `json:"enabled"`
+}
+
+func (g HelmfileGenerator) Generate(ctx GeneratorContext) (*GeneratorResult, error) {
+	helmfilePath, err := exec.LookPath("helmfile")
+	if err != nil {
+		return nil, executionErrorf("executing helmfile failed: executable not found")
+	}
+
+	listArgs := append(g.globalArgs(g.Selectors), "list", "--output", "json")
+	listCmd := exec.Command(helmfilePath, listArgs...)
+	listCmd.Dir = ctx.Dir
+	listStdout, listStderr, err := ctx.runCommand(*listCmd)
+	if err != nil {
+		return nil, executionErrorf("executing helmfile failed: %v\n%s", err, string(listStderr))
+	}
+	releases := []helmfileRelease{}
+	err = json.Unmarshal(listStdout, &releases)
+	if err != nil {
+		return nil, fmt.Errorf("parsing helmfile releases failed: %v", err)
+	}
+
+	result := GeneratorResult{}
+	existingDirs := map[string]int{}
+	for _, release := range releases {
+		if !release.Enabled {
+			continue
+		}
+		selectors := []string{"name=" + release.Name}
+		if release.Namespace != "" {
+			selectors[0] = selectors[0] + ",namespace=" + release.Namespace
+		}
+		templateArgs := append(g.globalArgs(selectors), "template")
+		templateArgs = append(templateArgs, g.Args...)
+		templateCmd := exec.Command(helmfilePath, templateArgs...)
+		templateCmd.Dir = ctx.Dir
+		done := ctx.Phase("templating " + release.Name)
+		templateStdout, templateStderr, err := ctx.runCommand(*templateCmd)
+		done()
+		if err != nil {
+			return nil, executionErrorf("executing helmfile failed: %v\n%s", err, string(templateStderr))
+		}
+
+		resources, err := splitCombinedKubernetesResources(string(templateStdout))
+		if err != nil {
+			return nil, fmt.Errorf("splitting helmfile resources failed: %v", err)
+		}
+		result.Children = append(result.Children, GeneratorResultChild{
+			Dir: getUniqueKubernetesResourceFileName(release.Name, &existingDirs),
+			Result: GeneratorResult{
+				Resources: resources,
+			},
+		})
+	}
+	return &result, nil
+}
+
+func (g HelmfileGenerator) globalArgs(selectors []string) []string {
+	args := []string{}
+	if g.File != "" {
+		args = append(args, "--file", g.File)
+	}
+	if g.Environment != "" {
+		args = append(args, "--environment", g.Environment)
+	}
+	for _, selector := range selectors {
+		args = append(args, "--selector", selector)
+	}
+	return args
+}
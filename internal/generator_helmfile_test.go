@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadGeneratorHelmfile(t *testing.T) {
+	c1, err := LoadGenerator("./generator_helmfile_test.yaml")
+	if assert.NoError(t, err) {
+		c2 := HelmfileGenerator{
+			File:        "helmfile.yaml",
+			Environment: "production",
+			Selectors:   []string{"tier=backend"},
+			Args:        []string{"--include-crds"},
+		}
+		assert.Equal(t, c2, *c1)
+	}
+}
+
+func TestHelmfileGeneratorDir(t *testing.T) {
+	bin := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "helmfile"), []byte(`#!/bin/sh
+[ -f helmfile.yaml ] || { echo "helmfile.yaml not found" >&2; exit 1; }
+for arg in "$@"; do
+  case "$arg" in
+    list) echo '[{"name":"app","namespace":"default","enabled":true}]'; exit 0 ;;
+    template) printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n'; exit 0 ;;
+  esac
+done
+exit 1
+`), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "helmfile.yaml"), []byte("releases: []\n"), 0o644))
+
+	result, err := HelmfileGenerator{File: "helmfile.yaml"}.Generate(GeneratorContext{Context: context.Background(), Dir: dir})
+	if assert.NoError(t, err) && assert.Len(t, result.Children, 1) {
+		assert.Equal(t, "app", result.Children[0].Dir)
+		assert.Len(t, result.Children[0].Result.Resources, 1)
+	}
+}
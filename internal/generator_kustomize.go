@@ -10,19 +10,21 @@ type KustomizeGenerator struct {
 	Args []string `yaml:"args"`
 }
 
-func (g KustomizeGenerator) Generate() (*GeneratorResult, error) {
+func (g KustomizeGenerator) Generate(ctx GeneratorContext) (*GeneratorResult, error) {
 	kustomizePath, err := exec.LookPath("kustomize")
 	if err != nil {
-		return nil, fmt.Errorf("executing kustomize failed: executable not found")
+		return nil, executionErrorf("executing kustomize failed: executable not found")
 	}
 	kustomizeArgs := []string{
 		"build",
 		g.Url,
 	}
 	kustomizeArgs = append(kustomizeArgs, g.Args...)
-	kustomizeStdout, kustomizeStderr, err := runCommand(*exec.Command(kustomizePath, kustomizeArgs...))
+	done := ctx.Phase("building")
+	kustomizeStdout, kustomizeStderr, err := ctx.runCommand(*exec.Command(kustomizePath, kustomizeArgs...))
+	done()
 	if err != nil {
-		return nil, fmt.Errorf("executing kustomize failed: %v\n%s", err, string(kustomizeStderr))
+		return nil, executionErrorf("executing kustomize failed: %v\n%s", err, string(kustomizeStderr))
 	}
 
 	resources, err := splitCombinedKubernetesResources(string(kustomizeStdout))
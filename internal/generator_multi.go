@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type MultiGenerator struct {
+	Generators []MultiGeneratorEntry
+}
+
+type MultiGeneratorEntry struct {
+	Dir       string
+	Generator Generator
+}
+
+func parseMultiGenerator(bytes []byte) (*MultiGenerator, error) {
+	raw := struct {
+		Generators []yaml.Node `yaml:"generators"`
+	}{}
+	err := readYaml(bytes, &raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw.Generators) == 0 {
+		return nil, configErrorf("config of type multi has no generators")
+	}
+
+	result := MultiGenerator{}
+	existingDirs := map[string]int{}
+	for i, node := range raw.Generators {
+		entryBytes, err := yaml.Marshal(&node)
+		if err != nil {
+			return nil, err
+		}
+		generator, err := parseGenerator(entryBytes)
+		if err != nil {
+			return nil, fmt.Errorf("generator %d: %w", i+1, err)
+		}
+		entry := struct {
+			Type      string `yaml:"type"`
+			Name      string `yaml:"name"`
+			OutputDir string `yaml:"outputDir"`
+		}{}
+		err = readYaml(entryBytes, &entry)
+		if err != nil {
+			return nil, err
+		}
+		dir := ""
+		if entry.OutputDir != "" {
+			dir, err = cleanOutputDir(entry.OutputDir)
+			if err != nil {
+				return nil, fmt.Errorf("generator %d: %w", i+1, err)
+			}
+			if existingDirs[dir] > 0 {
+				return nil, configErrorf("generator %d: output dir %s is used multiple times", i+1, dir)
+			}
+			existingDirs[dir]++
+		} else {
+			dir = entry.Name
+			if dir == "" {
+				dir = entry.Type
+			}
+			dir = getUniqueKubernetesResourceFileName(dir, &existingDirs)
+		}
+		result.Generators = append(result.Generators, MultiGeneratorEntry{
+			Dir:       dir,
+			Generator: *generator,
+		})
+	}
+	return &result, nil
+}
+
+func (g MultiGenerator) Generate(ctx GeneratorContext) (*GeneratorResult, error) {
+	result := GeneratorResult{}
+	for _, entry := range g.Generators {
+		entryResult, err := entry.Generator.Generate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result.Children = append(result.Children, GeneratorResultChild{
+			Dir:    entry.Dir,
+			Result: *entryResult,
+		})
+	}
+	return &result, nil
+}
+
+func cleanOutputDir(dir string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(dir, "\\", "/"))
+	if path.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", configErrorf("output dir %s must be a relative path inside the target directory", dir)
+	}
+	return cleaned, nil
+}
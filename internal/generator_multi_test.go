@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadGeneratorMulti(t *testing.T) {
+	c1, err := LoadGenerator("./generator_multi_test.yaml")
+	if assert.NoError(t, err) {
+		c2 := MultiGenerator{
+			Generators: []MultiGeneratorEntry{
+				{
+					Dir: "operator",
+					Generator: HelmGenerator{
+						Registry:  "https://charts.domain.com",
+						Chart:     "operator",
+						Version:   "1.0.0",
+						Name:      "operator",
+						Namespace: "namespace",
+					},
+				},
+				{
+					Dir: "charts/app",
+					Generator: HelmGenerator{
+						Registry:  "https://charts.domain.com",
+						Chart:     "app",
+						Version:   "1.2.3",
+						Name:      "app",
+						Namespace: "namespace",
+					},
+				},
+				{
+					Dir: "download",
+					Generator: DownloadGenerator{
+						Url: "https://domain.com/crds.yaml",
+					},
+				},
+			},
+		}
+		assert.Equal(t, c2, *c1)
+	}
+}
+
+func TestCleanOutputDir(t *testing.T) {
+	dir, err := cleanOutputDir("charts/app/")
+	assert.NoError(t, err)
+	assert.Equal(t, "charts/app", dir)
+	dir, err = cleanOutputDir("charts\\app")
+	assert.NoError(t, err)
+	assert.Equal(t, "charts/app", dir)
+	for _, invalid := range []string{"/abs", ".", "..", "../sibling", "a/../../b"} {
+		_, err = cleanOutputDir(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
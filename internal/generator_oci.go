@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type OciGenerator struct {
+	Url          string   `yaml:"url"`
+	Tag          string   `yaml:"tag"`
+	Digest       string   `yaml:"digest"`
+	IncludeFiles []string `yaml:"includeFiles"`
+	ExcludeFiles []string `yaml:"excludeFiles"`
+}
+
+func (g OciGenerator) Generate(ctx GeneratorContext) (*GeneratorResult, error) {
+	if !strings.HasPrefix(g.Url, "oci://") {
+		return nil, configErrorf("unsupported artifact url %s", g.Url)
+	}
+	ref := g.Url
+	if g.Digest != "" {
+		ref = ref + "@" + g.Digest
+	} else if g.Tag != "" {
+		ref = ref + ":" + g.Tag
+	} else {
+		return nil, configErrorf("artifact %s is missing tag or digest", g.Url)
+	}
+
+	fluxPath, err := exec.LookPath("flux")
+	if err != nil {
+		return nil, executionErrorf("executing flux failed: executable not found")
+	}
+	artifactDir, err := os.MkdirTemp(ctx.TempDir, ".kustomization-generator-*-artifact")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary artifact directory failed: %v", err)
+	}
+	defer os.RemoveAll(artifactDir)
+
+	fluxArgs := []string{
+		"pull", "artifact", ref,
+		"--output", artifactDir,
+	}
+	done := ctx.Phase("download")
+	_, fluxStderr, err := ctx.runCommand(*exec.Command(fluxPath, fluxArgs...))
+	done()
+	if err != nil {
+		return nil, executionErrorf("executing flux failed: %v\n%s", err, string(fluxStderr))
+	}
+
+	resources, err := readKubernetesResourcesFromDir(artifactDir, g.IncludeFiles, g.ExcludeFiles)
+	if err != nil {
+		return nil, fmt.Errorf("splitting artifact resources failed: %v", err)
+	}
+	result := GeneratorResult{
+		Resources: resources,
+	}
+	return &result, nil
+}
@@ -0,0 +1,20 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadGeneratorOci(t *testing.T) {
+	c1, err := LoadGenerator("./generator_oci_test.yaml")
+	if assert.NoError(t, err) {
+		c2 := OciGenerator{
+			Url:          "oci://ghcr.io/owner/manifests",
+			Tag:          "1.2.3",
+			IncludeFiles: []string{"*.yaml", "*.json"},
+			ExcludeFiles: []string{"NOTES.yaml"},
+		}
+		assert.Equal(t, c2, *c1)
+	}
+}
@@ -0,0 +1,8 @@
+package internal
+
+type GeneratorOptions struct {
+	Labels                map[string]string `yaml:"labels,omitempty"`
+	Annotations           map[string]string `yaml:"annotations,omitempty"`
+	DisableNameSuffixHash bool              `yaml:"disableNameSuffixHash,omitempty"`
+	Immutable             bool              `yaml:"immutable,omitempty"`
+}
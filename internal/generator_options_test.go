@@ -0,0 +1,22 @@
+package internal
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGeneratorOptions(t *testing.T) {
+	config, err := LoadConfigFromReader(strings.NewReader("type: download\nurl: https://domain.com/manifest.yaml\ngeneratorOptions:\n  disableNameSuffixHash: true\n  labels:\n    app: demo\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	fsys := NewMemoryFS()
+	_, err = write(fsys, GeneratorResult{}, newWriteOptions(*config))
+	assert.NoError(t, err)
+	content, err := fs.ReadFile(fsys, "kustomization.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "resources:\n  - crds\n  - namespaces\n  - resources\ngeneratorOptions:\n  labels:\n    app: demo\n  disableNameSuffixHash: true\n", string(content))
+}
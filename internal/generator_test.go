@@ -2,6 +2,9 @@ package internal
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -144,3 +147,164 @@ metadata:
   name: %s
 `, kind, name)
 }
+
+func TestReadKubernetesResourcesFromDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(mockResource("Secret", "a")), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "b.yml"), []byte(mockResource("Secret", "b")), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# readme"), 0o644))
+
+	actual, err := readKubernetesResourcesFromDir(dir, nil, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "a-secret.yaml", Content: mockResource("Secret", "a")},
+			{ApiVersion: "v1", Kind: "Secret", File: "b-secret.yaml", Content: mockResource("Secret", "b")},
+		}, actual)
+	}
+}
+
+func TestReadKubernetesResourcesFromDirPatterns(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(mockResource("Secret", "a")), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "b.yaml"), []byte(mockResource("Secret", "b")), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c.json"), []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"c"}}`), 0o644))
+
+	actual, err := readKubernetesResourcesFromDir(dir, []string{"*.yaml", "*.json"}, []string{"nested/*"})
+	if assert.NoError(t, err) {
+		assert.Len(t, actual, 2)
+		assert.Equal(t, "a-secret.yaml", actual[0].File)
+		assert.Equal(t, "c-secret.yaml", actual[1].File)
+	}
+}
+
+func TestGeneratorResultAllResources(t *testing.T) {
+	a := GeneratorResource{File: "a.yaml"}
+	b := GeneratorResource{File: "b.yaml"}
+	c := GeneratorResource{File: "c.yaml"}
+	result := GeneratorResult{
+		Resources: []GeneratorResource{a},
+		Children: []GeneratorResultChild{
+			{Dir: "x", Result: GeneratorResult{Resources: []GeneratorResource{b}}},
+			{Dir: "y", Result: GeneratorResult{Resources: []GeneratorResource{c}}},
+		},
+	}
+	assert.Equal(t, []GeneratorResource{a, b, c}, result.AllResources())
+}
+
+func TestLoadConfigFromReader(t *testing.T) {
+	t.Setenv("KUSTOMIZATION_GENERATOR_TEST_URL", "https://domain.com/manifest.yaml")
+	config, err := LoadConfigFromReader(strings.NewReader("type: download\nurl: ${KUSTOMIZATION_GENERATOR_TEST_URL}\nnormalize: true\n"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "download", config.Type)
+		assert.True(t, config.Normalize)
+		assert.Equal(t, DownloadGenerator{Url: "https://domain.com/manifest.yaml"}, config.Generator)
+	}
+}
+
+func TestLoadConfigExpandsHelmFields(t *testing.T) {
+	t.Setenv("KUSTOMIZATION_GENERATOR_TEST_MIRROR", "https://charts.mirror.internal")
+	t.Setenv("KUSTOMIZATION_GENERATOR_TEST_NAMESPACE", "staging")
+	config, err := LoadConfigFromReader(strings.NewReader("type: helm\nregistry: ${KUSTOMIZATION_GENERATOR_TEST_MIRROR}\nchart: app\nversion: ${KUSTOMIZATION_GENERATOR_TEST_VERSION:-1.2.3}\nname: app\nnamespace: ${KUSTOMIZATION_GENERATOR_TEST_NAMESPACE}\n"))
+	if assert.NoError(t, err) {
+		g := config.Generator.(HelmGenerator)
+		assert.Equal(t, "https://charts.mirror.internal", g.Registry)
+		assert.Equal(t, "1.2.3", g.Version)
+		assert.Equal(t, "staging", g.Namespace)
+	}
+}
+
+func TestSplitCombinedKubernetesResourcesExplodesLists(t *testing.T) {
+	input := `apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: a
+  - apiVersion: v1
+    kind: ConfigMapList
+    items:
+      - apiVersion: v1
+        kind: ConfigMap
+        metadata:
+          name: b
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: c
+`
+	actual, err := splitCombinedKubernetesResources(input)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []GeneratorResource{
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "a-configmap.yaml", Content: mockResource("ConfigMap", "a")},
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "b-configmap.yaml", Content: mockResource("ConfigMap", "b")},
+			{ApiVersion: "v1", Kind: "Secret", File: "c-secret.yaml", Content: mockResource("Secret", "c")},
+		}, actual)
+	}
+}
+
+func TestSplitCombinedKubernetesResourcesDropsEmptyDocuments(t *testing.T) {
+	input := `---
+# Source: chart/templates/disabled.yaml
+---
+null
+--- # Source: chart/templates/empty.yaml
+~
+---
+{}
+...
+---
+# Source: chart/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: a
+--- # Source: chart/templates/comment-only.yaml
+# nothing rendered
+`
+	actual, err := splitCombinedKubernetesResources(input)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "a-secret.yaml", Content: "# Source: chart/templates/disabled.yaml\n# Source: chart/templates/secret.yaml\n" + mockResource("Secret", "a")},
+		}, actual)
+	}
+}
+
+func TestSplitCombinedKubernetesResourcesSeparatorForms(t *testing.T) {
+	input := `apiVersion: v1
+kind: Secret
+metadata:
+  name: a
+---#comment
+apiVersion: v1
+kind: Secret
+metadata:
+  name: b
+---	
+apiVersion: v1
+kind: Secret
+metadata:
+  name: c
+--- !!map
+apiVersion: v1
+kind: Secret
+metadata:
+  name: d
+--- {"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "e"}}
+`
+	actual, err := splitCombinedKubernetesResources(input)
+	if assert.NoError(t, err) && assert.Len(t, actual, 5) {
+		assert.Equal(t, mockResource("Secret", "a"), actual[0].Content)
+		assert.Equal(t, mockResource("Secret", "b"), actual[1].Content)
+		assert.Equal(t, mockResource("Secret", "c"), actual[2].Content)
+		assert.Equal(t, "!!map\n"+mockResource("Secret", "d"), actual[3].Content)
+		assert.Equal(t, "e-secret.yaml", actual[4].File)
+		assert.Equal(t, "{\"apiVersion\": \"v1\", \"kind\": \"Secret\", \"metadata\": {\"name\": \"e\"}}\n", actual[4].Content)
+	}
+	assert.False(t, isYamlDocumentSeparator("----"))
+	assert.False(t, isYamlDocumentSeparator("---foo"))
+	assert.True(t, isYamlDocumentSeparator("--- |"))
+}
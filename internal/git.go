@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func gitCommitOutput(ctx GeneratorContext, config Config, opts RunOptions, extraFiles ...string) error {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return executionErrorf("executing git failed: executable not found")
+	}
+	git := func(args ...string) ([]byte, error) {
+		cmd := exec.Command(gitPath, args...)
+		cmd.Dir = ctx.Dir
+		stdout, stderr, err := ctx.runCommand(*cmd)
+		if err != nil {
+			return stdout, executionErrorf("executing git %s failed: %v\n%s", args[0], err, string(stderr))
+		}
+		return stdout, nil
+	}
+
+	previousVersions := map[string]string{}
+	if opts.ConfigFile != "-" {
+		file := opts.ConfigFile
+		if file == "" {
+			file = filepath.Join(ctx.Dir, configFile)
+		}
+		rel, err := filepath.Rel(ctx.Dir, file)
+		if err == nil {
+			previousBytes, err := git("show", "HEAD:./"+filepath.ToSlash(rel))
+			if err == nil {
+				previousConfig, err := LoadConfigFromReader(strings.NewReader(string(previousBytes)))
+				if err == nil {
+					previousVersions = configChartVersions(*previousConfig)
+				}
+			}
+		}
+	}
+
+	pathspecs := []string{"."}
+	for _, file := range extraFiles {
+		rel, err := filepath.Rel(ctx.Dir, file)
+		if err != nil {
+			return fmt.Errorf("committing output failed: %v", err)
+		}
+		pathspecs = append(pathspecs, rel)
+	}
+	_, err = git(append([]string{"add", "--all", "--"}, pathspecs...)...)
+	if err != nil {
+		return err
+	}
+	_, err = git(append([]string{"diff", "--cached", "--quiet", "--"}, pathspecs...)...)
+	if err == nil {
+		ctx.log().Info("nothing to commit")
+		return nil
+	}
+
+	absDir, err := filepath.Abs(ctx.Dir)
+	if err != nil {
+		return fmt.Errorf("committing output failed: %v", err)
+	}
+	message := gitCommitMessage(filepath.Base(absDir), previousVersions, configChartVersions(config))
+	_, err = git(append([]string{"commit", "--quiet", "--message", message, "--"}, pathspecs...)...)
+	if err != nil {
+		return err
+	}
+	ctx.log().Info("output committed", "message", message)
+	return nil
+}
+
+func configChartVersions(config Config) map[string]string {
+	versions := map[string]string{}
+	var collect func(generator Generator)
+	collect = func(generator Generator) {
+		switch g := generator.(type) {
+		case HelmGenerator:
+			chart := g.Chart
+			if chart == "" {
+				chart = path.Base(g.Registry)
+			}
+			versions[chart] = g.Version
+		case MultiGenerator:
+			for _, entry := range g.Generators {
+				collect(entry.Generator)
+			}
+		}
+	}
+	collect(config.Generator)
+	return versions
+}
+
+func gitCommitMessage(name string, previousVersions map[string]string, versions map[string]string) string {
+	charts := []string{}
+	for chart := range versions {
+		charts = append(charts, chart)
+	}
+	sort.Strings(charts)
+	changes := []string{}
+	for _, chart := range charts {
+		previous, ok := previousVersions[chart]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s: %s", chart, versions[chart]))
+		} else if previous != versions[chart] {
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", chart, previous, versions[chart]))
+		}
+	}
+	if len(changes) == 0 {
+		return fmt.Sprintf("Regenerate %s", name)
+	}
+	return strings.Join(changes, ", ")
+}
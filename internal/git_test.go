@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitCommitMessage(t *testing.T) {
+	assert.Equal(t, "Regenerate app", gitCommitMessage("app", map[string]string{"nginx": "15.1.0"}, map[string]string{"nginx": "15.1.0"}))
+	assert.Equal(t, "nginx: 15.1.0 → 15.2.1", gitCommitMessage("app", map[string]string{"nginx": "15.1.0"}, map[string]string{"nginx": "15.2.1"}))
+	assert.Equal(t, "a: 2.0.0, b: 1.0.0 → 1.1.0", gitCommitMessage("app", map[string]string{"b": "1.0.0"}, map[string]string{"a": "2.0.0", "b": "1.1.0"}))
+}
+
+func TestConfigChartVersions(t *testing.T) {
+	config := Config{Generator: MultiGenerator{Generators: []MultiGeneratorEntry{
+		{Dir: "a", Generator: HelmGenerator{Chart: "nginx", Version: "15.1.0"}},
+		{Dir: "b", Generator: HelmGenerator{Registry: "oci://ghcr.io/org/app", Version: "1.0.0"}},
+		{Dir: "c", Generator: DownloadGenerator{Url: "https://domain.com/manifest.yaml"}},
+	}}}
+	assert.Equal(t, map[string]string{"nginx": "15.1.0", "app": "1.0.0"}, configChartVersions(config))
+}
+
+func TestGitCommitOutput(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not found")
+	}
+	dir := t.TempDir()
+	gitRun := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@domain.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@domain.com")
+		output, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(output))
+		return strings.TrimSpace(string(output))
+	}
+	gitRun("init", "--quiet")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte("type: helm\nchart: nginx\nversion: 15.1.0\n"), 0o644))
+	gitRun("add", "--all")
+	gitRun("-c", "user.name=test", "-c", "user.email=test@domain.com", "commit", "--quiet", "--message", "init")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte("type: helm\nchart: nginx\nversion: 15.2.1\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources: []\n"), 0o644))
+	config, err := LoadConfig(filepath.Join(dir, configFile))
+	assert.NoError(t, err)
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@domain.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@domain.com")
+	err = gitCommitOutput(GeneratorContext{Dir: dir}, *config, RunOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx: 15.1.0 → 15.2.1", gitRun("log", "-1", "--format=%s"))
+
+	err = gitCommitOutput(GeneratorContext{Dir: dir}, *config, RunOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", gitRun("rev-list", "--count", "HEAD"))
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+const outputDigestPrefix = "sha256:"
+
+func hashOutputFiles(files map[string][]byte, ignore []string) string {
+	names := []string{}
+	for name := range files {
+		if name == metadataFile || isIgnoredFile(name, ignore) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s\x00%d\x00", name, len(files[name]))
+		hash.Write(files[name])
+	}
+	return outputDigestPrefix + hex.EncodeToString(hash.Sum(nil))
+}
+
+func HashDir(dir string) (string, error) {
+	return hashFS(NewDirFS(dir))
+}
+
+func hashFS(fsys fs.FS) (string, error) {
+	ignore, err := readGeneratorIgnore(fsys)
+	if err != nil {
+		return "", err
+	}
+	names, _, err := listOutputFiles(fsys, ignore)
+	if err != nil {
+		return "", err
+	}
+	files := map[string][]byte{}
+	for _, name := range names {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return "", err
+		}
+		files[name] = content
+	}
+	return hashOutputFiles(files, ignore), nil
+}
+
+func VerifyDir(dir string, expected string, opts RunOptions) (string, error) {
+	if expected == "" {
+		committed, err := HashDir(dir)
+		if err != nil {
+			return "", err
+		}
+		expected = committed
+	}
+	_, files, err := renderSnapshot(dir, opts)
+	if err != nil {
+		return "", err
+	}
+	ignore, err := readGeneratorIgnore(NewDirFS(dir))
+	if err != nil {
+		return "", err
+	}
+	rendered := hashOutputFiles(files, ignore)
+	if rendered != expected {
+		return rendered, ClassifiedError{Class: ErrorClassDrift, Err: fmt.Errorf("digest mismatch for %s: expected %s, rendered %s", dir, expected, rendered)}
+	}
+	return rendered, nil
+}
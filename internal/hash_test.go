@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashOutputFiles(t *testing.T) {
+	files := map[string][]byte{
+		"kustomization.yaml":           []byte("resources:\n  - resources\n"),
+		"resources/kustomization.yaml": []byte("resources: []\n"),
+	}
+	digest := hashOutputFiles(files, nil)
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", digest)
+
+	files[metadataFile] = []byte("generatedAt: today")
+	files["README.md"] = []byte("hand written")
+	assert.Equal(t, digest, hashOutputFiles(files, []string{"README.md"}))
+
+	files["resources/kustomization.yaml"] = []byte("resources: [] \n")
+	assert.NotEqual(t, digest, hashOutputFiles(files, []string{"README.md"}))
+}
+
+func TestHashDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte("type: download\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources:\n  - resources\n"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "resources"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "resources", "kustomization.yaml"), []byte("resources: []\n"), 0o644))
+
+	digest, err := HashDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, hashOutputFiles(map[string][]byte{
+		"kustomization.yaml":           []byte("resources:\n  - resources\n"),
+		"resources/kustomization.yaml": []byte("resources: []\n"),
+	}, nil), digest)
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const generationHeader = "# Generated by kustomization-generator - do not edit\n"
+
+func sourceGenerationHeader(source *GeneratorSource) string {
+	if source == nil || source.Chart == "" {
+		return generationHeader
+	}
+	version := source.Version
+	if version != "" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return fmt.Sprintf("# Generated by kustomization-generator from chart %s - do not edit\n", strings.TrimSpace(source.Chart+" "+version))
+}
+
+func addGenerationHeaders(result GeneratorResult, source *GeneratorSource) GeneratorResult {
+	if result.Source != nil {
+		source = result.Source
+	}
+	header := sourceGenerationHeader(source)
+	annotated := result
+	annotated.Resources = nil
+	annotated.Children = nil
+	for _, resource := range result.Resources {
+		resource.Content = header + resource.Content
+		annotated.Resources = append(annotated.Resources, resource)
+	}
+	for _, child := range result.Children {
+		child.Result = addGenerationHeaders(child.Result, source)
+		annotated.Children = append(annotated.Children, child)
+	}
+	return annotated
+}
+
+func addKustomizationHeaders(files map[string][]byte) {
+	for name, content := range files {
+		if path.Base(name) == "kustomization.yaml" {
+			files[name] = append([]byte(generationHeader), content...)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGenerationHeaders(t *testing.T) {
+	fsys := NewMemoryFS()
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "plain-configmap.yaml", Content: mockResource("ConfigMap", "plain")},
+		},
+		Children: []GeneratorResultChild{
+			{Dir: "app", Result: GeneratorResult{
+				Source: &GeneratorSource{Chart: "app", Version: "1.2.3"},
+				Resources: []GeneratorResource{
+					{ApiVersion: "v1", Kind: "Secret", File: "app-secret.yaml", Content: mockResource("Secret", "app")},
+				},
+				Files: map[string][]byte{"chart/values.yaml": []byte("a: b\n")},
+			}},
+		},
+	}
+	_, err := write(fsys, result, writeOptions{Header: true})
+	assert.NoError(t, err)
+	files := fsys.Files()
+	assert.Equal(t, generationHeader+"resources:\n  - app\n  - crds\n  - namespaces\n  - resources\n", string(files["kustomization.yaml"]))
+	assert.Equal(t, generationHeader+"resources:\n  - plain-configmap.yaml\n", string(files["resources/kustomization.yaml"]))
+	assert.Equal(t, generationHeader+mockResource("ConfigMap", "plain"), string(files["resources/plain-configmap.yaml"]))
+	assert.Equal(t, "# Generated by kustomization-generator from chart app v1.2.3 - do not edit\n"+mockResource("Secret", "app"), string(files["app/resources/app-secret.yaml"]))
+	assert.Equal(t, "a: b\n", string(files["app/chart/values.yaml"]))
+
+	fsys = NewMemoryFS()
+	_, err = write(fsys, result, writeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, mockResource("Secret", "app"), string(fsys.Files()["app/resources/app-secret.yaml"]))
+}
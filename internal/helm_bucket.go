@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func isBucketRegistry(registry string) bool {
+	return strings.HasPrefix(registry, "s3://") || strings.HasPrefix(registry, "gs://")
+}
+
+func readBucketObject(ctx GeneratorContext, url string) ([]byte, error) {
+	var name string
+	var args []string
+	switch {
+	case strings.HasPrefix(url, "s3://"):
+		name, args = "aws", []string{"s3", "cp", url, "-"}
+	case strings.HasPrefix(url, "gs://"):
+		name, args = "gcloud", []string{"storage", "cat", url}
+	default:
+		return nil, configErrorf("unsupported bucket url %s", url)
+	}
+	binPath, err := exec.LookPath(name)
+	if err != nil {
+		return nil, executionErrorf("executing %s failed: executable not found", name)
+	}
+	stdout, stderr, err := ctx.runCommand(*exec.Command(binPath, args...))
+	if err != nil {
+		return nil, networkErrorf("failed to fetch %s: %v\n%s", url, err, string(stderr))
+	}
+	return stdout, nil
+}
+
+func retrieveBucketHelmChart(ctx GeneratorContext, repository Repository, chart string, version string, devel bool, digest string) (*helmRegistryIndexEntry, string, func(), error) {
+	indexUrl := strings.TrimSuffix(repository.Url, "/") + "/index.yaml"
+	index, err := readBucketObject(ctx, indexUrl)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	versions, ok, err := decodeHelmRegistryIndexEntries(bytes.NewReader(index), chart)
+	if err != nil {
+		return nil, "", nil, networkErrorf("failed to fetch registry index at %s: %v", indexUrl, err)
+	}
+	if !ok {
+		return nil, "", nil, configErrorf("chart %s could not be found", chart)
+	}
+	entry, err := selectHelmChartVersion(versions, version, devel)
+	if err != nil {
+		return nil, "", nil, configErrorf("chart %s %v", chart, err)
+	}
+	err = checkHelmChartDeprecation(ctx, chart, versions, *entry)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	err = checkHelmChartIndexDigest(chart, entry.Version, entry.Digest, digest)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	urls, err := resolveHelmChartUrls(indexUrl, chart, *entry)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	chartDir, cleanup, err := downloadHelmChartFromMirrors(ctx, urls, func(chartUrl string) (string, func(), error) {
+		var archive []byte
+		var err error
+		if isBucketRegistry(chartUrl) {
+			archive, err = readBucketObject(ctx, chartUrl)
+		} else {
+			archive, err = downloadBytes(ctx, chartUrl)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		err = checkHelmChartArchiveDigest(chartUrl, archive, digest)
+		if err != nil {
+			return "", nil, err
+		}
+		return extractHelmChartArchive(ctx, archive)
+	})
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return entry, chartDir, cleanup, nil
+}
+
+func downloadHelmChartArchive(ctx GeneratorContext, repository Repository, url string, digest string) (string, func(), error) {
+	resp, err := ctx.repositoryGet(repository, url)
+	if err != nil {
+		return "", nil, networkErrorf("failed to download %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	archive, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, networkErrorf("failed to download %s: %v", url, err)
+	}
+	err = checkHttpResponse(resp, "chart archive", url)
+	if err != nil {
+		return "", nil, err
+	}
+	if !isGzip(archive) {
+		return "", nil, networkErrorf("chart archive at %s is not a gzip archive (content type %s)", url, resp.Header.Get("Content-Type"))
+	}
+	err = checkHelmChartArchiveDigest(url, archive, digest)
+	if err != nil {
+		return "", nil, err
+	}
+	return extractHelmChartArchive(ctx, archive)
+}
+
+func extractHelmChartArchive(ctx GeneratorContext, archive []byte) (string, func(), error) {
+	tempDir, err := os.MkdirTemp(ctx.TempDir, ".kustomization-generator-*-chart")
+	if err != nil {
+		return "", nil, fmt.Errorf("extracting chart failed: %v", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+	err = extractTarGz(ctx.context(), archive, tempDir)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("extracting chart failed: %v", err)
+	}
+	chartDir, err := findPulledChartDir(tempDir)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("extracting chart failed: %v", err)
+	}
+	return chartDir, cleanup, nil
+}
+
+func extractTarGz(ctx context.Context, archive []byte, dir string) error {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return err
+	}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry %s is outside of the target directory", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0o755)
+		case tar.TypeReg:
+			err = os.MkdirAll(filepath.Dir(target), 0o755)
+			if err == nil {
+				var content []byte
+				content, err = io.ReadAll(tarReader)
+				if err == nil {
+					err = os.WriteFile(target, content, 0o644)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
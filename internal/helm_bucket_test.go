@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetrieveBucketHelmChart(t *testing.T) {
+	archive := bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range map[string]string{"app/Chart.yaml": "name: app\nversion: 1.0.0\n", "app/templates/cm.yaml": "kind: ConfigMap\n"} {
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, _ = tarWriter.Write([]byte(content))
+	}
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+
+	bucket := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(bucket, "index.yaml"), []byte("entries:\n  app:\n  - version: 1.0.0\n    urls: [s3://charts/stable/app-1.0.0.tgz]\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(bucket, "app-1.0.0.tgz"), archive.Bytes(), 0o644))
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncat \"" + bucket + "/$(basename \"$3\")\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "aws"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	entry, chartDir, cleanup, err := retrieveBucketHelmChart(GeneratorContext{}, Repository{Url: "s3://charts/stable"}, "app", "^1.0.0", false, "")
+	if assert.NoError(t, err) {
+		defer cleanup()
+		assert.Equal(t, "1.0.0", entry.Version)
+		chart, err := readHelmLocalChart(chartDir)
+		assert.NoError(t, err)
+		assert.Equal(t, "app", chart.Name)
+		assert.FileExists(t, filepath.Join(chartDir, "templates", "cm.yaml"))
+	}
+}
+
+func TestExtractTarGzRejectsPathTraversal(t *testing.T) {
+	archive := bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "../evil", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg}))
+	_, _ = tarWriter.Write([]byte("x"))
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+	assert.Error(t, extractTarGz(context.Background(), archive.Bytes(), t.TempDir()))
+}
+
+func TestExtractTarGzStopsWhenCanceled(t *testing.T) {
+	archive := bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "app/Chart.yaml", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg}))
+	_, _ = tarWriter.Write([]byte("x"))
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := extractHelmChartArchive(GeneratorContext{Context: cancelCtx}, archive.Bytes())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "context canceled")
+	}
+}
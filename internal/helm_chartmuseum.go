@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func retrieveChartMuseumVersions(ctx GeneratorContext, repository Repository, chart string, version string) ([]helmRegistryIndexEntry, string, bool, error) {
+	base := strings.TrimSuffix(repository.Url, "/") + "/"
+	apiUrl := base + "api/charts/" + url.PathEscape(chart)
+	exact := version != "" && !isSemverConstraint(version)
+	if exact {
+		apiUrl = apiUrl + "/" + url.PathEscape(version)
+	}
+	resp, err := ctx.repositoryGet(repository, apiUrl)
+	if err != nil {
+		return nil, "", false, networkErrorf("failed to fetch chart versions at %s: %v", apiUrl, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		if exact {
+			return []helmRegistryIndexEntry{}, base, true, nil
+		}
+		return nil, "", false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", false, networkErrorf("failed to fetch chart versions at %s: status %d", apiUrl, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", false, networkErrorf("failed to fetch chart versions at %s: %v", apiUrl, err)
+	}
+	if exact {
+		entry := helmRegistryIndexEntry{}
+		err = json.Unmarshal(body, &entry)
+		if err != nil {
+			return nil, "", false, networkErrorf("failed to fetch chart versions at %s: %v", apiUrl, err)
+		}
+		return []helmRegistryIndexEntry{entry}, base, true, nil
+	}
+	entries := []helmRegistryIndexEntry{}
+	err = json.Unmarshal(body, &entries)
+	if err != nil {
+		return nil, "", false, networkErrorf("failed to fetch chart versions at %s: %v", apiUrl, err)
+	}
+	return entries, base, true, nil
+}
@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetrieveHelmChartArchiveFromChartMuseum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/charts/app":
+			_, _ = w.Write([]byte(`[{"name":"app","version":"1.1.0","urls":["charts/app-1.1.0.tgz"]},{"name":"app","version":"1.0.0","urls":["charts/app-1.0.0.tgz"]}]`))
+		case "/api/charts/app/1.0.0":
+			_, _ = w.Write([]byte(`{"name":"app","version":"1.0.0","appVersion":"2.0.0","urls":["charts/app-1.0.0.tgz"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	repository := Repository{Url: server.URL, Type: "chartmuseum"}
+
+	entry, urls, err := retrieveHelmChartArchive(GeneratorContext{}, repository, "app", "1.0.0", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2.0.0", entry.AppVersion)
+		assert.Equal(t, []string{server.URL + "/charts/app-1.0.0.tgz"}, urls)
+	}
+
+	entry, _, err = retrieveHelmChartArchive(GeneratorContext{}, repository, "app", "^1.0.0", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.1.0", entry.Version)
+	}
+
+	_, _, err = retrieveHelmChartArchive(GeneratorContext{}, repository, "app", "2.0.0", false)
+	assert.Error(t, err)
+	_, _, err = retrieveHelmChartArchive(GeneratorContext{}, repository, "missing", "", false)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "chart missing could not be found")
+	}
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+var helmChartDigestRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+func normalizeHelmChartDigest(digest string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(digest), "sha256:"))
+}
+
+func validateHelmChartDigest(generator HelmGenerator) error {
+	if generator.Digest == "" {
+		return nil
+	}
+	if !helmChartDigestRegex.MatchString(normalizeHelmChartDigest(generator.Digest)) {
+		return configErrorf("digest %s is not a valid sha256 digest", generator.Digest)
+	}
+	if generator.Version == "" || isSemverConstraint(generator.Version) {
+		return configErrorf("digest requires an exact chart version")
+	}
+	return nil
+}
+
+func checkHelmChartIndexDigest(chart string, version string, actual string, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	if normalizeHelmChartDigest(actual) != normalizeHelmChartDigest(expected) {
+		return validationErrorf("chart %s version %s has digest %s but %s was expected", chart, version, actual, normalizeHelmChartDigest(expected))
+	}
+	return nil
+}
+
+func checkHelmChartArchiveDigest(url string, archive []byte, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	hash := sha256.Sum256(archive)
+	actual := hex.EncodeToString(hash[:])
+	if actual != normalizeHelmChartDigest(expected) {
+		return validationErrorf("chart archive at %s has digest %s but %s was expected", url, actual, normalizeHelmChartDigest(expected))
+	}
+	return nil
+}
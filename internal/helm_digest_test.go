@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateHelmChartDigest(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	assert.NoError(t, validateHelmChartDigest(HelmGenerator{}))
+	assert.NoError(t, validateHelmChartDigest(HelmGenerator{Version: "1.0.0", Digest: digest}))
+	assert.NoError(t, validateHelmChartDigest(HelmGenerator{Version: "1.0.0", Digest: "sha256:" + strings.ToUpper(digest)}))
+	assert.Error(t, validateHelmChartDigest(HelmGenerator{Version: "1.0.0", Digest: "abc"}))
+	assert.Error(t, validateHelmChartDigest(HelmGenerator{Version: "^1.0.0", Digest: digest}))
+	assert.Error(t, validateHelmChartDigest(HelmGenerator{Digest: digest}))
+}
+
+func TestHelmChartDigestPinning(t *testing.T) {
+	archive := bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	content := "name: app\nversion: 1.0.0\n"
+	assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "app/Chart.yaml", Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, _ = tarWriter.Write([]byte(content))
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+	hash := sha256.Sum256(archive.Bytes())
+	digest := hex.EncodeToString(hash[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			_, _ = w.Write([]byte("entries:\n  app:\n  - version: 1.0.0\n    digest: " + digest + "\n    urls: [app-1.0.0.tgz]\n"))
+		case "/app-1.0.0.tgz":
+			_, _ = w.Write(archive.Bytes())
+		}
+	}))
+	defer server.Close()
+	repository := Repository{Url: server.URL}
+
+	entry, urls, err := retrieveHelmChartArchive(GeneratorContext{}, repository, "app", "1.0.0", false)
+	assert.NoError(t, err)
+	assert.NoError(t, checkHelmChartIndexDigest("app", entry.Version, entry.Digest, "sha256:"+digest))
+	err = checkHelmChartIndexDigest("app", entry.Version, entry.Digest, strings.Repeat("0", 64))
+	if assert.Error(t, err) {
+		assert.Equal(t, 5, ExitCode(err))
+		assert.Contains(t, err.Error(), "chart app version 1.0.0 has digest "+digest)
+	}
+
+	chartDir, cleanup, err := downloadHelmChartArchive(GeneratorContext{}, repository, urls[0], digest)
+	if assert.NoError(t, err) {
+		defer cleanup()
+		assert.FileExists(t, filepath.Join(chartDir, "Chart.yaml"))
+	}
+	_, _, err = downloadHelmChartArchive(GeneratorContext{}, repository, urls[0], strings.Repeat("0", 64))
+	if assert.Error(t, err) {
+		assert.Equal(t, 5, ExitCode(err))
+		assert.Contains(t, err.Error(), "chart archive at "+urls[0]+" has digest "+digest)
+	}
+}
@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+var helmDownloadBaseUrl = "https://get.helm.sh"
+
+func (ctx GeneratorContext) cacheDir() (string, error) {
+	if ctx.CacheDir != "" {
+		return ctx.CacheDir, nil
+	}
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userCacheDir, "kustomization-generator"), nil
+}
+
+func ensureManagedHelm(ctx GeneratorContext, version string) (string, error) {
+	version = "v" + strings.TrimPrefix(version, "v")
+	cacheDir, err := ctx.cacheDir()
+	if err != nil {
+		return "", fmt.Errorf("determining cache directory failed: %v", err)
+	}
+	binary := "helm"
+	if runtime.GOOS == "windows" {
+		binary = "helm.exe"
+	}
+	helmPath := filepath.Join(cacheDir, "helm", version, binary)
+	if _, err := os.Stat(helmPath); err == nil {
+		return helmPath, nil
+	}
+	release, err := acquireFileLock(ctx, filepath.Join(cacheDir, "helm", version+".lock"))
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	if _, err := os.Stat(helmPath); err == nil {
+		return helmPath, nil
+	}
+
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	archiveName := fmt.Sprintf("helm-%s-%s.tar.gz", version, platform)
+	if runtime.GOOS == "windows" {
+		archiveName = fmt.Sprintf("helm-%s-%s.zip", version, platform)
+	}
+	archiveUrl := helmDownloadBaseUrl + "/" + archiveName
+
+	done := ctx.Phase("helm download")
+	defer done()
+	archive, err := downloadBytes(ctx, archiveUrl)
+	if err != nil {
+		return "", err
+	}
+	checksumFile, err := downloadBytes(ctx, archiveUrl+".sha256sum")
+	if err != nil {
+		return "", err
+	}
+	checksumFields := strings.Fields(string(checksumFile))
+	if len(checksumFields) == 0 {
+		return "", networkErrorf("failed to download %s: checksum file is empty", archiveUrl)
+	}
+	actualChecksum := sha256.Sum256(archive)
+	if hex.EncodeToString(actualChecksum[:]) != strings.ToLower(checksumFields[0]) {
+		return "", networkErrorf("failed to download %s: checksum mismatch", archiveUrl)
+	}
+
+	content, err := extractHelmBinary(archive, platform+"/"+binary, strings.HasSuffix(archiveName, ".zip"))
+	if err != nil {
+		return "", fmt.Errorf("extracting %s failed: %v", archiveName, err)
+	}
+	err = os.MkdirAll(filepath.Dir(helmPath), 0o755)
+	if err != nil {
+		return "", fmt.Errorf("writing helm binary failed: %v", err)
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(helmPath), ".helm-*")
+	if err != nil {
+		return "", fmt.Errorf("writing helm binary failed: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+	_, err = tempFile.Write(content)
+	tempFile.Close()
+	if err != nil {
+		return "", fmt.Errorf("writing helm binary failed: %v", err)
+	}
+	err = os.Chmod(tempFile.Name(), 0o755)
+	if err != nil {
+		return "", fmt.Errorf("writing helm binary failed: %v", err)
+	}
+	err = os.Rename(tempFile.Name(), helmPath)
+	if err != nil {
+		return "", fmt.Errorf("writing helm binary failed: %v", err)
+	}
+	ctx.log().Info("helm downloaded", "version", version, "path", helmPath)
+	return helmPath, nil
+}
+
+func downloadBytes(ctx GeneratorContext, url string) ([]byte, error) {
+	resp, err := ctx.httpGet(url)
+	if err != nil {
+		return nil, networkErrorf("failed to download %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, networkErrorf("failed to download %s: %v", url, err)
+	}
+	err = checkHttpResponse(resp, "file", url)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func extractHelmBinary(archive []byte, name string, isZip bool) ([]byte, error) {
+	if isZip {
+		reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range reader.File {
+			if file.Name == name {
+				rc, err := file.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			}
+		}
+		return nil, fmt.Errorf("%s not found in archive", name)
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s not found in archive", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Name == name {
+			return io.ReadAll(tarReader)
+		}
+	}
+}
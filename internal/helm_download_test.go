@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureManagedHelm(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test archive is a tarball")
+	}
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	archive := bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	binary := []byte("#!/bin/sh\necho v3.14.0\n")
+	assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: platform + "/helm", Mode: 0o755, Size: int64(len(binary))}))
+	_, err := tarWriter.Write(binary)
+	assert.NoError(t, err)
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+	checksum := sha256.Sum256(archive.Bytes())
+
+	requests := 0
+	corrupt := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/helm-v3.14.0-" + platform + ".tar.gz":
+			w.Write(archive.Bytes())
+		case "/helm-v3.14.0-" + platform + ".tar.gz.sha256sum":
+			if corrupt {
+				w.Write([]byte("0000  helm.tar.gz\n"))
+			} else {
+				w.Write([]byte(hex.EncodeToString(checksum[:]) + "  helm.tar.gz\n"))
+			}
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+	originalBaseUrl := helmDownloadBaseUrl
+	helmDownloadBaseUrl = server.URL
+	defer func() { helmDownloadBaseUrl = originalBaseUrl }()
+
+	corrupt = true
+	_, err = ensureManagedHelm(GeneratorContext{CacheDir: t.TempDir()}, "3.14.0")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	}
+
+	corrupt = false
+	ctx := GeneratorContext{CacheDir: t.TempDir()}
+	helmPath, err := ensureManagedHelm(ctx, "3.14.0")
+	if assert.NoError(t, err) {
+		content, err := os.ReadFile(helmPath)
+		assert.NoError(t, err)
+		assert.Equal(t, binary, content)
+	}
+	requests = 0
+	_, err = ensureManagedHelm(ctx, "v3.14.0")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, requests)
+}
@@ -0,0 +1,229 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+type helmRegistryIndexCacheEntry struct {
+	mu   sync.Mutex
+	body []byte
+	url  string
+}
+
+type HelmIndexCache struct {
+	mu      sync.Mutex
+	entries map[string]*helmRegistryIndexCacheEntry
+}
+
+func NewHelmIndexCache() *HelmIndexCache {
+	return &HelmIndexCache{entries: map[string]*helmRegistryIndexCacheEntry{}}
+}
+
+func retrieveHelmRegistryIndexVersions(ctx GeneratorContext, repository Repository, chart string) ([]helmRegistryIndexEntry, string, bool, error) {
+	body, url, err := fetchHelmRegistryIndex(ctx, repository)
+	if err != nil {
+		return nil, "", false, err
+	}
+	versions, ok, err := decodeHelmRegistryIndexEntries(bytes.NewReader(body), chart)
+	if err != nil {
+		return nil, "", false, networkErrorf("failed to fetch registry index at %s: %v", url, err)
+	}
+	return versions, url, ok, nil
+}
+
+func fetchHelmRegistryIndex(ctx GeneratorContext, repository Repository) ([]byte, string, error) {
+	entry := &helmRegistryIndexCacheEntry{}
+	if cache := ctx.IndexCache; cache != nil {
+		key := strings.Join([]string{repository.Url, repository.Username, repository.Password, repository.AuthEnv}, "\x00")
+		cache.mu.Lock()
+		cached, ok := cache.entries[key]
+		if !ok {
+			cached = entry
+			cache.entries[key] = cached
+		}
+		cache.mu.Unlock()
+		entry = cached
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.body != nil {
+		ctx.log().Debug("registry index reused", "url", entry.url)
+		return entry.body, entry.url, nil
+	}
+	body, url, err := openHelmRegistryIndex(ctx, repository)
+	if err != nil {
+		return nil, "", err
+	}
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, "", networkErrorf("failed to fetch registry index at %s: %v", url, err)
+	}
+	entry.body = content
+	entry.url = url
+	storeCachedHelmRegistryIndex(ctx, repository.Url, content)
+	return content, url, nil
+}
+
+func openHelmRegistryIndex(ctx GeneratorContext, repository Repository) (io.ReadCloser, string, error) {
+	base := strings.TrimSuffix(repository.Url, "/")
+	var resp *http.Response
+	url := ""
+	for _, name := range []string{"index.yaml", "index.yaml.gz"} {
+		url = base + "/" + name
+		var err error
+		resp, err = ctx.repositoryGet(repository, url)
+		if err != nil {
+			return nil, "", networkErrorf("failed to fetch registry index at %s: %v", url, err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			break
+		}
+		resp.Body.Close()
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		url = resp.Request.URL.String()
+	}
+	err := checkHttpResponse(resp, "registry index", url)
+	if err == nil {
+		err = checkHttpContentType(resp, "registry index", url, "text/html")
+	}
+	if err != nil {
+		resp.Body.Close()
+		return nil, "", err
+	}
+	body, err := decompressHelmRegistryIndex(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, "", networkErrorf("failed to fetch registry index at %s: %v", url, err)
+	}
+	return body, url, nil
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (r gzipReadCloser) Close() error {
+	r.Reader.Close()
+	return r.body.Close()
+}
+
+func decompressHelmRegistryIndex(body io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(body)
+	magic, _ := buffered.Peek(2)
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		return struct {
+			io.Reader
+			io.Closer
+		}{buffered, body}, nil
+	}
+	reader, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, err
+	}
+	return gzipReadCloser{Reader: reader, body: body}, nil
+}
+
+func decodeHelmRegistryIndexEntries(reader io.Reader, chart string) ([]helmRegistryIndexEntry, bool, error) {
+	buffered := bufio.NewReaderSize(reader, 64*1024)
+	seenContent := false
+	inEntries := false
+	entryIndent := -1
+	collecting := false
+	block := []string{}
+	for {
+		line, readErr := buffered.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, false, readErr
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+		content := strings.TrimLeft(trimmed, " ")
+		indent := len(trimmed) - len(content)
+		isBlank := content == "" || strings.HasPrefix(content, "#")
+
+		if !seenContent && !isBlank {
+			seenContent = true
+			if strings.HasPrefix(content, "{") || strings.HasPrefix(content, "---") || strings.HasPrefix(content, "%") {
+				rest, err := io.ReadAll(buffered)
+				if err != nil {
+					return nil, false, err
+				}
+				return decodeFullHelmRegistryIndexEntries(append([]byte(line), rest...), chart)
+			}
+		}
+
+		switch {
+		case isBlank:
+			if collecting && content == "" {
+				block = append(block, "")
+			}
+		case indent == 0:
+			if collecting {
+				return decodeHelmRegistryIndexBlock(block)
+			}
+			inEntries = content == "entries:"
+		case inEntries:
+			if entryIndent < 0 {
+				entryIndent = indent
+			}
+			if indent < entryIndent {
+				break
+			}
+			if indent == entryIndent && !strings.HasPrefix(content, "-") {
+				if collecting {
+					return decodeHelmRegistryIndexBlock(block)
+				}
+				key, rest, _ := strings.Cut(content, ":")
+				if strings.Trim(key, `"'`) != chart {
+					break
+				}
+				collecting = true
+				if rest = strings.TrimSpace(rest); rest != "" {
+					block = append(block, rest)
+				}
+				break
+			}
+			if collecting {
+				block = append(block, trimmed[entryIndent:])
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if collecting {
+		return decodeHelmRegistryIndexBlock(block)
+	}
+	return nil, false, nil
+}
+
+func decodeHelmRegistryIndexBlock(block []string) ([]helmRegistryIndexEntry, bool, error) {
+	entries := []helmRegistryIndexEntry{}
+	err := yaml.Unmarshal([]byte(strings.Join(block, "\n")), &entries)
+	if err != nil {
+		return nil, false, err
+	}
+	return entries, true, nil
+}
+
+func decodeFullHelmRegistryIndexEntries(body []byte, chart string) ([]helmRegistryIndexEntry, bool, error) {
+	index := helmRegistryIndex{}
+	err := yaml.Unmarshal(body, &index)
+	if err != nil {
+		return nil, false, err
+	}
+	entries, ok := index.Entries[chart]
+	return entries, ok, nil
+}
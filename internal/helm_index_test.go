@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeHelmRegistryIndexEntries(t *testing.T) {
+	index := `apiVersion: v1
+entries:
+  cert-manager:
+  - apiVersion: v2
+    name: cert-manager
+    version: v1.7.0
+    urls:
+    - charts/cert-manager-v1.7.0.tgz
+
+  - apiVersion: v2
+    name: cert-manager
+    version: v1.6.1
+    urls:
+    - charts/cert-manager-v1.6.1.tgz
+  "cert-manager-csi-driver":
+  - name: cert-manager-csi-driver
+    version: v0.2.0
+    urls: [charts/cert-manager-csi-driver-v0.2.0.tgz]
+  empty: []
+generated: "2022-01-01T00:00:00Z"
+`
+	entries, ok, err := decodeHelmRegistryIndexEntries(strings.NewReader(index), "cert-manager")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []helmRegistryIndexEntry{
+		{ApiVersion: "v2", Name: "cert-manager", Version: "v1.7.0", Urls: []string{"charts/cert-manager-v1.7.0.tgz"}},
+		{ApiVersion: "v2", Name: "cert-manager", Version: "v1.6.1", Urls: []string{"charts/cert-manager-v1.6.1.tgz"}},
+	}, entries)
+
+	entries, ok, err = decodeHelmRegistryIndexEntries(strings.NewReader(index), "cert-manager-csi-driver")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []helmRegistryIndexEntry{{Name: "cert-manager-csi-driver", Version: "v0.2.0", Urls: []string{"charts/cert-manager-csi-driver-v0.2.0.tgz"}}}, entries)
+
+	entries, ok, err = decodeHelmRegistryIndexEntries(strings.NewReader(index), "empty")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, entries)
+
+	_, ok, err = decodeHelmRegistryIndexEntries(strings.NewReader(index), "missing")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	entries, ok, err = decodeHelmRegistryIndexEntries(strings.NewReader(`{"entries": {"app": [{"version": "1.0.0"}]}}`), "app")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []helmRegistryIndexEntry{{Version: "1.0.0"}}, entries)
+
+	entries, ok, err = decodeHelmRegistryIndexEntries(strings.NewReader("entries:\n    app:\n      - version: 1.0.0\n"), "app")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []helmRegistryIndexEntry{{Version: "1.0.0"}}, entries)
+}
+
+func TestOpenHelmRegistryIndexGzip(t *testing.T) {
+	compressed := bytes.Buffer{}
+	w := gzip.NewWriter(&compressed)
+	_, _ = w.Write([]byte("entries:\n  app:\n  - version: 1.0.0\n"))
+	assert.NoError(t, w.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml.gz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	body, url, err := openHelmRegistryIndex(GeneratorContext{}, Repository{Url: server.URL})
+	if assert.NoError(t, err) {
+		defer body.Close()
+		assert.Equal(t, server.URL+"/index.yaml.gz", url)
+		entries, ok, err := decodeHelmRegistryIndexEntries(body, "app")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []helmRegistryIndexEntry{{Version: "1.0.0"}}, entries)
+	}
+}
+
+func TestRetrieveHelmRegistryIndexVersionsReusesIndex(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte("entries:\n  app:\n  - version: 1.0.0\n  other:\n  - version: 2.0.0\n"))
+	}))
+	defer server.Close()
+
+	ctx := GeneratorContext{IndexCache: NewHelmIndexCache()}
+	entries, _, ok, err := retrieveHelmRegistryIndexVersions(ctx, Repository{Url: server.URL}, "app")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []helmRegistryIndexEntry{{Version: "1.0.0"}}, entries)
+	entries, _, ok, err = retrieveHelmRegistryIndexVersions(ctx, Repository{Url: server.URL}, "other")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []helmRegistryIndexEntry{{Version: "2.0.0"}}, entries)
+	assert.Equal(t, 1, requests)
+
+	_, _, _, err = retrieveHelmRegistryIndexVersions(ctx, Repository{Url: server.URL, Username: "user"}, "app")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	_, _, _, err = retrieveHelmRegistryIndexVersions(GeneratorContext{IndexCache: NewHelmIndexCache()}, Repository{Url: server.URL}, "app")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requests)
+	_, _, _, err = retrieveHelmRegistryIndexVersions(GeneratorContext{}, Repository{Url: server.URL}, "app")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, requests)
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"fmt"
+)
+
+type HelmInstance struct {
+	Name      string                 `yaml:"name"`
+	Namespace string                 `yaml:"namespace"`
+	Values    map[string]interface{} `yaml:"values"`
+}
+
+func validateHelmInstances(g HelmGenerator) error {
+	if len(g.Instances) == 0 {
+		return nil
+	}
+	if g.Name != "" {
+		return configErrorf("name and instances are mutually exclusive")
+	}
+	if len(g.Namespaces) > 0 {
+		return configErrorf("namespaces and instances are mutually exclusive")
+	}
+	existing := map[string]bool{}
+	for _, instance := range g.Instances {
+		err := validateHelmReleaseName(instance.Name)
+		if err != nil {
+			return configErrorf("instance: %v", err)
+		}
+		if existing[instance.Name] {
+			return configErrorf("instance %s is listed multiple times", instance.Name)
+		}
+		existing[instance.Name] = true
+		if instance.Namespace != "" && (len(instance.Namespace) > 63 || !kubernetesNamespaceRegex.MatchString(instance.Namespace)) {
+			return configErrorf("instance %s: invalid namespace %s: must be a lowercase RFC 1123 label of at most 63 characters", instance.Name, instance.Namespace)
+		}
+	}
+	return nil
+}
+
+func (g HelmGenerator) generateInstances(ctx GeneratorContext) (*GeneratorResult, error) {
+	result := GeneratorResult{}
+	for _, instance := range g.Instances {
+		generator := g
+		generator.Name = instance.Name
+		generator.Instances = nil
+		if instance.Namespace != "" {
+			generator.Namespace = instance.Namespace
+		}
+		generator.Values = mergeValuesWithListMerge(g.Values, instance.Values, g.ListMerge, "")
+		ctx.log().Info("rendering instance", "name", instance.Name)
+		instanceResult, err := generator.Generate(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("instance %s: %w", instance.Name, err)
+		}
+		result.Children = append(result.Children, GeneratorResultChild{
+			Dir:    instance.Name,
+			Result: *instanceResult,
+		})
+	}
+	return &result, nil
+}
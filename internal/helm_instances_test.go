@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelmGeneratorInstances(t *testing.T) {
+	helm := fakeHelm(t, `release="$2"
+namespace=default
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --namespace) namespace="$2"; shift ;;
+    --values) values="$2"; shift ;;
+  esac
+  shift
+done
+printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n  namespace: %s\ndata:\n  %s\n' "$release" "$namespace" "$(grep replicas "$values")"
+`)
+
+	config, err := parseConfig([]byte(`type: helm
+chart: ./testdata/chart
+namespace: data
+values:
+  replicas: 1
+instances:
+  - name: cache
+  - name: queue
+    namespace: queue
+    values:
+      replicas: 3
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	result, err := config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+	if assert.NoError(t, err) {
+		assert.Empty(t, result.Resources)
+		if assert.Len(t, result.Children, 2) {
+			assert.Equal(t, "cache", result.Children[0].Dir)
+			assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cache\n  namespace: data\ndata:\n  replicas: 1\n", result.Children[0].Result.Resources[0].Content)
+			assert.Equal(t, "queue", result.Children[1].Dir)
+			assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: queue\n  namespace: queue\ndata:\n  replicas: 3\n", result.Children[1].Result.Resources[0].Content)
+		}
+	}
+}
+
+func TestValidateHelmInstances(t *testing.T) {
+	assert.NoError(t, validateHelmInstances(HelmGenerator{Instances: []HelmInstance{{Name: "a"}, {Name: "b"}}}))
+	for config, message := range map[string]string{
+		"name: app\ninstances: [{name: a}]":            "mutually exclusive",
+		"namespaces: [team-a]\ninstances: [{name: a}]": "mutually exclusive",
+		"instances: [{name: A}]":                       "release name A is invalid",
+		"instances: [{}]":                              "release name is missing",
+		"instances: [{name: a}, {name: a}]":            "listed multiple times",
+		"instances: [{name: a, namespace: Team}]":      "invalid namespace Team",
+	} {
+		_, err := parseConfig([]byte("type: helm\nchart: app\n" + config + "\n"))
+		if assert.Error(t, err, config) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
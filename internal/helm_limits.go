@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type HelmLimitsConfig struct {
+	Timeout       time.Duration `yaml:"timeout"`
+	MaxOutputSize string        `yaml:"maxOutputSize"`
+}
+
+var byteSizeUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"K", 1000},
+	{"M", 1000 * 1000},
+	{"G", 1000 * 1000 * 1000},
+}
+
+func parseByteSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	factor := int64(1)
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSuffix(value, unit.suffix)
+			factor = unit.factor
+			break
+		}
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size <= 0 {
+		return 0, configErrorf("invalid size %s", value)
+	}
+	return size * factor, nil
+}
+
+func validateHelmLimits(limits *HelmLimitsConfig) error {
+	if limits == nil {
+		return nil
+	}
+	if limits.Timeout < 0 {
+		return configErrorf("limits timeout must not be negative")
+	}
+	if limits.MaxOutputSize != "" {
+		if _, err := parseByteSize(limits.MaxOutputSize); err != nil {
+			return configErrorf("invalid limits maxOutputSize %s", limits.MaxOutputSize)
+		}
+	}
+	return nil
+}
+
+func (limits *HelmLimitsConfig) apply(ctx GeneratorContext) GeneratorContext {
+	if limits == nil {
+		return ctx
+	}
+	if limits.Timeout > 0 {
+		ctx.Timeouts.Command = limits.Timeout
+	}
+	if limits.MaxOutputSize != "" {
+		ctx.MaxOutput, _ = parseByteSize(limits.MaxOutputSize)
+	}
+	return ctx
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for value, expected := range map[string]int64{"1024": 1024, "64Ki": 64 << 10, "10Mi": 10 << 20, "1Gi": 1 << 30, "5M": 5000000} {
+		size, err := parseByteSize(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+	for _, value := range []string{"", "abc", "-1", "0", "10Ti"} {
+		_, err := parseByteSize(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestGeneratorContextRunCommandMaxOutput(t *testing.T) {
+	shPath, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh executable not found")
+	}
+	ctx := GeneratorContext{MaxOutput: 16}
+	stdout, _, err := ctx.runCommand(*exec.Command(shPath, "-c", "echo short"))
+	assert.NoError(t, err)
+	assert.Equal(t, "short\n", string(stdout))
+
+	_, _, err = ctx.runCommand(*exec.Command(shPath, "-c", "while true; do echo 0123456789; done"))
+	assert.EqualError(t, err, "output exceeded limit of 16 bytes")
+}
+
+func TestHelmGeneratorLimits(t *testing.T) {
+	helm := fakeHelm(t, "exec sleep 5\n")
+
+	config, err := parseConfig([]byte("type: helm\nchart: ./testdata/chart\nname: app\nlimits:\n  timeout: 50ms\n  maxOutputSize: 10Mi\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &HelmLimitsConfig{Timeout: 50 * time.Millisecond, MaxOutputSize: "10Mi"}, config.Generator.(HelmGenerator).Limits)
+	_, err = config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "timed out after 50ms")
+	}
+
+	_, err = parseConfig([]byte("type: helm\nchart: app\nlimits:\n  maxOutputSize: lots\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid limits maxOutputSize lots")
+	}
+}
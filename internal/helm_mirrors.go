@@ -0,0 +1,31 @@
+package internal
+
+func resolveHelmChartUrls(base string, chart string, entry helmRegistryIndexEntry) ([]string, error) {
+	if len(entry.Urls) == 0 {
+		return nil, configErrorf("chart %s version %s has no download urls", chart, entry.Version)
+	}
+	urls := []string{}
+	for _, chartUrl := range entry.Urls {
+		result, err := resolveHelmChartUrl(base, chartUrl)
+		if err != nil {
+			return nil, configErrorf("chart %s version %s has an invalid download url: %v", chart, entry.Version, err)
+		}
+		urls = append(urls, result)
+	}
+	return urls, nil
+}
+
+func downloadHelmChartFromMirrors(ctx GeneratorContext, urls []string, download func(url string) (string, func(), error)) (string, func(), error) {
+	var lastErr error
+	for i, url := range urls {
+		chartDir, cleanup, err := download(url)
+		if err == nil {
+			return chartDir, cleanup, nil
+		}
+		if i < len(urls)-1 {
+			ctx.log().Warn("chart download failed, trying next url", "url", url, "error", err)
+		}
+		lastErr = err
+	}
+	return "", nil, lastErr
+}
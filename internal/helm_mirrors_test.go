@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetrieveHelmChartArchiveWithMirrors(t *testing.T) {
+	archive := bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	content := "name: app\nversion: 1.0.0\n"
+	assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "app/Chart.yaml", Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, _ = tarWriter.Write([]byte(content))
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			_, _ = w.Write([]byte("entries:\n  app:\n  - version: 1.0.0\n    urls: [primary/app-1.0.0.tgz, mirror/app-1.0.0.tgz]\n"))
+		case "/mirror/app-1.0.0.tgz":
+			_, _ = w.Write(archive.Bytes())
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	repository := Repository{Url: server.URL}
+
+	entry, urls, err := retrieveHelmChartArchive(GeneratorContext{}, repository, "app", "1.0.0", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", entry.Version)
+	assert.Equal(t, []string{server.URL + "/primary/app-1.0.0.tgz", server.URL + "/mirror/app-1.0.0.tgz"}, urls)
+
+	downloaded := []string{}
+	chartDir, cleanup, err := downloadHelmChartFromMirrors(GeneratorContext{}, urls, func(url string) (string, func(), error) {
+		downloaded = append(downloaded, url)
+		return downloadHelmChartArchive(GeneratorContext{}, repository, url, "")
+	})
+	if assert.NoError(t, err) {
+		defer cleanup()
+		assert.Equal(t, urls, downloaded)
+		assert.FileExists(t, filepath.Join(chartDir, "Chart.yaml"))
+	}
+
+	_, _, err = downloadHelmChartFromMirrors(GeneratorContext{}, urls[:1], func(url string) (string, func(), error) {
+		return downloadHelmChartArchive(GeneratorContext{}, repository, url, "")
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "status code was 503")
+	}
+}
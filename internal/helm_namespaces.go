@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"fmt"
+)
+
+func validateHelmNamespaces(g HelmGenerator) error {
+	if len(g.Namespaces) == 0 {
+		if len(g.NamespaceValues) > 0 {
+			return configErrorf("namespaceValues require namespaces")
+		}
+		return nil
+	}
+	if g.Namespace != "" {
+		return configErrorf("namespace and namespaces are mutually exclusive")
+	}
+	existing := map[string]bool{}
+	for _, namespace := range g.Namespaces {
+		if len(namespace) > 63 || !kubernetesNamespaceRegex.MatchString(namespace) {
+			return configErrorf("invalid namespace %s: must be a lowercase RFC 1123 label of at most 63 characters", namespace)
+		}
+		if existing[namespace] {
+			return configErrorf("namespace %s is listed multiple times", namespace)
+		}
+		existing[namespace] = true
+	}
+	for namespace := range g.NamespaceValues {
+		if !existing[namespace] {
+			return configErrorf("namespaceValues contain %s which is not listed in namespaces", namespace)
+		}
+	}
+	return nil
+}
+
+func (g HelmGenerator) generateNamespaces(ctx GeneratorContext) (*GeneratorResult, error) {
+	result := GeneratorResult{}
+	for _, namespace := range g.Namespaces {
+		generator := g
+		generator.Namespace = namespace
+		generator.Namespaces = nil
+		generator.NamespaceValues = nil
+		generator.Values = mergeValuesWithListMerge(g.Values, g.NamespaceValues[namespace], g.ListMerge, "")
+		ctx.log().Info("rendering namespace", "namespace", namespace)
+		namespaceResult, err := generator.Generate(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %w", namespace, err)
+		}
+		result.Children = append(result.Children, GeneratorResultChild{
+			Dir:    namespace,
+			Result: *namespaceResult,
+		})
+	}
+	return &result, nil
+}
@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelmGeneratorNamespaces(t *testing.T) {
+	helm := fakeHelm(t, `while [ $# -gt 0 ]; do
+  case "$1" in
+    --namespace) namespace="$2"; shift ;;
+    --values) values="$2"; shift ;;
+  esac
+  shift
+done
+printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: %s\ndata:\n  %s\n' "$namespace" "$(grep replicas "$values")"
+`)
+
+	config, err := parseConfig([]byte(`type: helm
+chart: ./testdata/chart
+name: app
+values:
+  replicas: 1
+namespaces: [team-a, team-b]
+namespaceValues:
+  team-b:
+    replicas: 3
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	result, err := config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+	if assert.NoError(t, err) {
+		assert.Empty(t, result.Resources)
+		if assert.Len(t, result.Children, 2) {
+			assert.Equal(t, "team-a", result.Children[0].Dir)
+			assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: team-a\ndata:\n  replicas: 1\n", result.Children[0].Result.Resources[0].Content)
+			assert.Equal(t, "team-b", result.Children[1].Dir)
+			assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: team-b\ndata:\n  replicas: 3\n", result.Children[1].Result.Resources[0].Content)
+		}
+	}
+}
+
+func TestValidateHelmNamespaces(t *testing.T) {
+	assert.NoError(t, validateHelmNamespaces(HelmGenerator{Namespaces: []string{"team-a", "team-b"}}))
+	for config, message := range map[string]string{
+		"namespace: app\nnamespaces: [team-a]":                "mutually exclusive",
+		"namespaces: [Team-A]":                                "invalid namespace Team-A",
+		"namespaces: [team-a, team-a]":                        "listed multiple times",
+		"namespaces: [team-a]\nnamespaceValues: {team-c: {}}": "team-c which is not listed",
+		"namespaceValues: {team-a: {}}":                       "require namespaces",
+	} {
+		_, err := parseConfig([]byte("type: helm\nchart: app\n" + config + "\n"))
+		if assert.Error(t, err, config) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const helmNotesFile = "NOTES.md"
+
+const helmNotesTemplate = "kustomization-generator-notes.txt"
+
+func (g HelmGenerator) renderNotes(ctx GeneratorContext, helmPath string, chartDir string, valuesFile string) ([]byte, error) {
+	_, err := os.Stat(filepath.Join(chartDir, "templates", "NOTES.txt"))
+	if os.IsNotExist(err) {
+		ctx.log().Debug("chart has no notes", "chart", chartDir)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading chart notes failed: %v", err)
+	}
+	notesChartDir, cleanup, err := copyHelmChart(ctx, chartDir, func(file string, content []byte) (string, []byte) {
+		if filepath.ToSlash(file) == "templates/NOTES.txt" {
+			file = filepath.Join("templates", helmNotesTemplate)
+		}
+		if g.Release != nil && isHelmTemplateFile(file) {
+			content = []byte(g.Release.rewriteTemplate(string(content)))
+		}
+		return file, content
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	generator := g
+	generator.ShowOnly = []string{"templates/" + helmNotesTemplate}
+	generator.Cluster = nil
+	helmCmd := exec.Command(helmPath, generator.templateArgs(ctx, valuesFile, []string{notesChartDir})...)
+	if g.Sandbox != nil {
+		sandboxCleanup, err := g.Sandbox.apply(helmCmd, ctx.TempDir)
+		if err != nil {
+			return nil, fmt.Errorf("preparing helm sandbox failed: %v", err)
+		}
+		defer sandboxCleanup()
+	}
+	stdout, stderr, err := ctx.runCommand(*helmCmd)
+	if err != nil {
+		return nil, executionErrorf("rendering chart notes failed: %v\n%s", err, string(stderr))
+	}
+	notes := stripHelmSourceHeader(string(stdout))
+	if strings.TrimSpace(notes) == "" {
+		return nil, nil
+	}
+	return []byte(fmt.Sprintf("# Notes for release %s\n\n```\n%s\n```\n", g.Name, strings.Trim(notes, "\n"))), nil
+}
+
+func stripHelmSourceHeader(output string) string {
+	lines := strings.Split(output, "\n")
+	for len(lines) > 0 && (lines[0] == "---" || strings.HasPrefix(lines[0], "# Source: ")) {
+		lines = lines[1:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelmGeneratorNotes(t *testing.T) {
+	helm := fakeHelm(t, `while [ $# -gt 0 ]; do
+  case "$1" in
+    --values) chart="$3"; shift ;;
+    --show-only) template="$2"; shift ;;
+  esac
+  shift
+done
+if [ -n "$template" ]; then
+  printf -- '---\n# Source: app/%s\n' "$template"
+  sed 's/{{ .Release.Name }}/app/' "$chart/$template"
+else
+  cat "$chart/templates/configmap.yaml"
+fi
+`)
+	chartDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: app\nversion: 1.2.3\n"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(chartDir, "templates"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "templates", "configmap.yaml"), []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "templates", "NOTES.txt"), []byte("Release {{ .Release.Name }} installed.\n"), 0o644))
+
+	config, err := parseConfig([]byte("type: helm\nchart: " + chartDir + "\nname: app\nnotes: true\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	result, err := config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Resources, 1)
+		assert.Equal(t, "# Notes for release app\n\n```\nRelease app installed.\n```\n", string(result.Files[helmNotesFile]))
+	}
+
+	assert.NoError(t, os.Remove(filepath.Join(chartDir, "templates", "NOTES.txt")))
+	result, err = config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+	if assert.NoError(t, err) {
+		assert.NotContains(t, result.Files, helmNotesFile)
+	}
+}
+
+func TestStripHelmSourceHeader(t *testing.T) {
+	assert.Equal(t, "text\n", stripHelmSourceHeader("---\n# Source: app/templates/NOTES.txt\ntext\n"))
+	assert.Equal(t, "text\n", stripHelmSourceHeader("text\n"))
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type HelmReleaseConfig struct {
+	Revision    int    `yaml:"revision"`
+	Service     string `yaml:"service"`
+	KubeVersion string `yaml:"kubeVersion"`
+}
+
+var helmTemplateActionRegex = regexp.MustCompile(`\{\{[\s\S]*?\}\}`)
+var helmReleaseFieldRegex = regexp.MustCompile(`(\$[A-Za-z0-9_]*)?(\.[A-Za-z0-9_]+)*\.Release\.(Revision|Service)\b`)
+
+func (r *HelmReleaseConfig) rewritesTemplates() bool {
+	return r != nil && (r.Revision != 0 || r.Service != "")
+}
+
+func validateHelmRelease(r *HelmReleaseConfig) error {
+	if r == nil {
+		return nil
+	}
+	if r.Revision < 0 {
+		return configErrorf("release revision must not be negative")
+	}
+	if r.KubeVersion != "" {
+		if _, err := parseSemver(r.KubeVersion); err != nil {
+			return configErrorf("invalid release kubeVersion %s", r.KubeVersion)
+		}
+	}
+	return nil
+}
+
+func (r HelmReleaseConfig) rewriteTemplate(content string) string {
+	return helmTemplateActionRegex.ReplaceAllStringFunc(content, func(action string) string {
+		return helmReleaseFieldRegex.ReplaceAllStringFunc(action, r.rewriteField)
+	})
+}
+
+func (r HelmReleaseConfig) rewriteField(match string) string {
+	if strings.Contains(match, ".Values.") {
+		return match
+	}
+	if strings.HasSuffix(match, ".Revision") {
+		if r.Revision == 0 {
+			return match
+		}
+		return strconv.Itoa(r.Revision)
+	}
+	if r.Service == "" {
+		return match
+	}
+	return strconv.Quote(r.Service)
+}
+
+func (r HelmReleaseConfig) rewriteChart(ctx GeneratorContext, chartDir string) (string, func(), error) {
+	target, cleanup, err := copyHelmChart(ctx, chartDir, func(file string, content []byte) (string, []byte) {
+		if isHelmTemplateFile(file) {
+			content = []byte(r.rewriteTemplate(string(content)))
+		}
+		return file, content
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	err = r.rewriteChartDependencies(ctx, target)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("preparing chart failed: %v", err)
+	}
+	return target, cleanup, nil
+}
+
+func (r HelmReleaseConfig) rewriteChartDependencies(ctx GeneratorContext, chartDir string) error {
+	archives, err := filepath.Glob(filepath.Join(chartDir, "charts", "*.tgz"))
+	if err != nil {
+		return err
+	}
+	for _, archive := range archives {
+		content, err := os.ReadFile(archive)
+		if err != nil {
+			return err
+		}
+		dependencyDir, cleanup, err := extractHelmChartArchive(ctx, content)
+		if err != nil {
+			return fmt.Errorf("dependency %s: %v", filepath.Base(archive), err)
+		}
+		err = r.rewriteChartDir(ctx, dependencyDir)
+		if err == nil {
+			err = os.Rename(dependencyDir, filepath.Join(chartDir, "charts", filepath.Base(dependencyDir)))
+		}
+		cleanup()
+		if err != nil {
+			return fmt.Errorf("dependency %s: %v", filepath.Base(archive), err)
+		}
+		err = os.Remove(archive)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r HelmReleaseConfig) rewriteChartDir(ctx GeneratorContext, chartDir string) error {
+	err := filepath.WalkDir(chartDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.context().Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(chartDir, file)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isHelmTemplateFile(rel) {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(file, []byte(r.rewriteTemplate(string(content))), 0o644)
+	})
+	if err != nil {
+		return err
+	}
+	return r.rewriteChartDependencies(ctx, chartDir)
+}
+
+func copyHelmChart(ctx GeneratorContext, chartDir string, transform func(file string, content []byte) (string, []byte)) (string, func(), error) {
+	tempDir, err := os.MkdirTemp(ctx.TempDir, ".kustomization-generator-*-chart")
+	if err != nil {
+		return "", nil, fmt.Errorf("preparing chart failed: %v", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+	target := filepath.Join(tempDir, filepath.Base(chartDir))
+	err = filepath.WalkDir(chartDir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.context().Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(chartDir, file)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(target, rel), 0o755)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		rel, content = transform(rel, content)
+		return os.WriteFile(filepath.Join(target, rel), content, 0o644)
+	})
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("preparing chart failed: %v", err)
+	}
+	return target, cleanup, nil
+}
+
+func isHelmTemplateFile(file string) bool {
+	segments := strings.Split(filepath.ToSlash(file), "/")
+	for _, segment := range segments[:len(segments)-1] {
+		if segment == "templates" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelmReleaseConfigRewriteTemplate(t *testing.T) {
+	r := HelmReleaseConfig{Revision: 5, Service: "Argo"}
+	assert.Equal(t, `revision: {{ 5 | quote }}
+managed-by: {{ "Argo" }}
+root: {{ "Argo" }} {{ 5 }}
+nested: {{ "Argo" }}
+name: {{ .Release.Name }}
+`, r.rewriteTemplate(`revision: {{ .Release.Revision | quote }}
+managed-by: {{ .Release.Service }}
+root: {{ $.Release.Service }} {{ $root.Release.Revision }}
+nested: {{ .context.Release.Service }}
+name: {{ .Release.Name }}
+`))
+	assert.Equal(t, "{{ .Release.Revision }} {{ \"Argo\" }}", HelmReleaseConfig{Service: "Argo"}.rewriteTemplate("{{ .Release.Revision }} {{ .Release.Service }}"))
+	assert.Equal(t, "{{ .Values.Release.Service }} {{ $.Values.Release.Revision }}", r.rewriteTemplate("{{ .Values.Release.Service }} {{ $.Values.Release.Revision }}"))
+	assert.Equal(t, "# docs mention .Release.Service\nservice: {{- \"Argo\" -}}", r.rewriteTemplate("# docs mention .Release.Service\nservice: {{- .Release.Service -}}"))
+	assert.Equal(t, "{{ if eq \"Argo\"\n  \"Helm\" }}", r.rewriteTemplate("{{ if eq .Release.Service\n  \"Helm\" }}"))
+}
+
+func TestHelmReleaseConfigRewriteChartDependencies(t *testing.T) {
+	archive := bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range map[string]string{
+		"common/Chart.yaml":            "name: common\nversion: 2.0.0\n",
+		"common/templates/_labels.tpl": "managed-by: {{ .Release.Service }}\n",
+		"common/values.yaml":           "note: .Release.Service\n",
+	} {
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, _ = tarWriter.Write([]byte(content))
+	}
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+
+	chartDir := filepath.Join(t.TempDir(), "app")
+	assert.NoError(t, os.MkdirAll(filepath.Join(chartDir, "charts"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: app\nversion: 1.0.0\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "charts", "common-2.0.0.tgz"), archive.Bytes(), 0o644))
+
+	rewrittenDir, cleanup, err := HelmReleaseConfig{Service: "Argo"}.rewriteChart(GeneratorContext{Context: context.Background()}, chartDir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer cleanup()
+	_, err = os.Stat(filepath.Join(rewrittenDir, "charts", "common-2.0.0.tgz"))
+	assert.True(t, os.IsNotExist(err))
+	content, err := os.ReadFile(filepath.Join(rewrittenDir, "charts", "common", "templates", "_labels.tpl"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "managed-by: {{ \"Argo\" }}\n", string(content))
+	}
+	content, err = os.ReadFile(filepath.Join(rewrittenDir, "charts", "common", "values.yaml"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "note: .Release.Service\n", string(content))
+	}
+	_, err = os.Stat(filepath.Join(chartDir, "charts", "common-2.0.0.tgz"))
+	assert.NoError(t, err)
+}
+
+func TestHelmGeneratorRelease(t *testing.T) {
+	helm := fakeHelm(t, `while [ $# -gt 0 ]; do
+  case "$1" in
+    --values) chart="$3"; shift ;;
+    --kube-version) kube="$2"; shift ;;
+  esac
+  shift
+done
+cat "$chart/templates/configmap.yaml"
+echo "  kube: $kube"
+`)
+	chartDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: app\nversion: 1.2.3\n"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(chartDir, "templates"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "templates", "configmap.yaml"), []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  revision: {{ .Release.Revision }}\n"), 0o644))
+
+	config, err := parseConfig([]byte("type: helm\nchart: " + chartDir + "\nname: app\nrelease:\n  revision: 7\n  kubeVersion: 1.28.0\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	result, err := config.Generator.Generate(GeneratorContext{Context: context.Background(), HelmBin: helm})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  revision: {{ 7 }}\n  kube: 1.28.0\n", result.Resources[0].Content)
+	}
+	content, err := os.ReadFile(filepath.Join(chartDir, "templates", "configmap.yaml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), ".Release.Revision")
+
+	_, err = parseConfig([]byte("type: helm\nchart: app\nrelease:\n  kubeVersion: latest\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid release kubeVersion latest")
+	}
+}
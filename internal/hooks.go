@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+type HooksConfig struct {
+	Pre  []string `yaml:"pre"`
+	Post []string `yaml:"post"`
+}
+
+func runHooks(ctx GeneratorContext, stage string, commands []string, config Config, result *GeneratorResult) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	dir, err := filepath.Abs(ctx.Dir)
+	if err != nil {
+		return executionErrorf("running %s hook failed: %v", stage, err)
+	}
+	env := append(os.Environ(), hookEnv(dir, stage, config, result)...)
+
+	done := ctx.Phase(stage + " hooks")
+	defer done()
+	for _, command := range commands {
+		shell, shellArgs := "sh", []string{"-c", command}
+		if runtime.GOOS == "windows" {
+			shell, shellArgs = "cmd", []string{"/C", command}
+		}
+		shellPath, err := exec.LookPath(shell)
+		if err != nil {
+			return executionErrorf("running %s hook failed: executable %s not found", stage, shell)
+		}
+		cmd := exec.Command(shellPath, shellArgs...)
+		cmd.Dir = dir
+		cmd.Env = env
+		stdout, stderr, err := ctx.runCommand(*cmd)
+		if err != nil {
+			return executionErrorf("running %s hook %q failed: %v\n%s", stage, command, err, string(stderr))
+		}
+		ctx.log().Info("hook finished", "stage", stage, "command", command, "output", strings.TrimSpace(string(stdout)))
+	}
+	return nil
+}
+
+func hookEnv(dir string, stage string, config Config, result *GeneratorResult) []string {
+	env := []string{
+		"KUSTOMIZATION_GENERATOR_DIR=" + dir,
+		"KUSTOMIZATION_GENERATOR_TYPE=" + config.Type,
+		"KUSTOMIZATION_GENERATOR_HOOK=" + stage,
+	}
+	if result == nil {
+		return env
+	}
+	sources := collectMetadataSources(".", *result)
+	if len(sources) == 1 {
+		env = append(env,
+			"KUSTOMIZATION_GENERATOR_CHART="+sources[0].Chart,
+			"KUSTOMIZATION_GENERATOR_CHART_VERSION="+sources[0].Version,
+			"KUSTOMIZATION_GENERATOR_CHART_APP_VERSION="+sources[0].AppVersion,
+		)
+	}
+	return env
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookEnv(t *testing.T) {
+	assert.Equal(t, []string{
+		"KUSTOMIZATION_GENERATOR_DIR=/dir",
+		"KUSTOMIZATION_GENERATOR_TYPE=helm",
+		"KUSTOMIZATION_GENERATOR_HOOK=pre",
+	}, hookEnv("/dir", "pre", Config{Type: "helm"}, nil))
+
+	result := GeneratorResult{Source: &GeneratorSource{Chart: "app", Version: "1.2.3", AppVersion: "4.5.6"}}
+	assert.Equal(t, []string{
+		"KUSTOMIZATION_GENERATOR_DIR=/dir",
+		"KUSTOMIZATION_GENERATOR_TYPE=helm",
+		"KUSTOMIZATION_GENERATOR_HOOK=post",
+		"KUSTOMIZATION_GENERATOR_CHART=app",
+		"KUSTOMIZATION_GENERATOR_CHART_VERSION=1.2.3",
+		"KUSTOMIZATION_GENERATOR_CHART_APP_VERSION=4.5.6",
+	}, hookEnv("/dir", "post", Config{Type: "helm"}, &result))
+}
+
+func TestHookCommands(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil || runtime.GOOS == "windows" {
+		t.Skip("sh executable not found")
+	}
+	dir := t.TempDir()
+	ctx := GeneratorContext{Dir: dir}
+	result := GeneratorResult{Source: &GeneratorSource{Chart: "app", Version: "1.2.3"}}
+	err := runHooks(ctx, "post", []string{`echo "$KUSTOMIZATION_GENERATOR_CHART@$KUSTOMIZATION_GENERATOR_CHART_VERSION" > hook.txt`}, Config{Type: "helm"}, &result)
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "hook.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "app@1.2.3\n", string(content))
+
+	err = runHooks(ctx, "pre", []string{"echo failing >&2; exit 3"}, Config{Type: "helm"}, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "running pre hook \"echo failing >&2; exit 3\" failed")
+		assert.Contains(t, err.Error(), "failing")
+		assert.Equal(t, 4, ExitCode(err))
+	}
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+func checkHttpResponse(resp *http.Response, what string, url string) error {
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized:
+		return networkErrorf("%s at %s requires authentication (status code 401)", what, url)
+	case resp.StatusCode == http.StatusForbidden:
+		return networkErrorf("access to %s at %s was denied (status code 403)", what, url)
+	case resp.StatusCode == http.StatusNotFound:
+		return networkErrorf("%s at %s does not exist (status code 404)", what, url)
+	default:
+		return networkErrorf("failed to fetch %s at %s: status code was %d", what, url, resp.StatusCode)
+	}
+}
+
+func checkHttpContentType(resp *http.Response, what string, url string, unexpected ...string) error {
+	header := resp.Header.Get("Content-Type")
+	if header == "" {
+		return nil
+	}
+	contentType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return nil
+	}
+	for _, u := range unexpected {
+		if strings.EqualFold(contentType, u) {
+			return networkErrorf("%s at %s has unexpected content type %s", what, url, contentType)
+		}
+	}
+	return nil
+}
+
+func isGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
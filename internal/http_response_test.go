@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenHelmRegistryIndexChecksResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/forbidden/index.yaml":
+			w.WriteHeader(http.StatusForbidden)
+		case "/login/index.yaml":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html><body>login</body></html>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	_, _, err := openHelmRegistryIndex(GeneratorContext{}, Repository{Url: server.URL + "/forbidden"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "access to registry index at "+server.URL+"/forbidden/index.yaml was denied (status code 403)", err.Error())
+		assert.Equal(t, 3, ExitCode(err))
+	}
+	_, _, err = openHelmRegistryIndex(GeneratorContext{}, Repository{Url: server.URL + "/login"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "registry index at "+server.URL+"/login/index.yaml has unexpected content type text/html", err.Error())
+	}
+	_, _, err = openHelmRegistryIndex(GeneratorContext{}, Repository{Url: server.URL + "/missing"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "registry index at "+server.URL+"/missing/index.yaml.gz does not exist (status code 404)", err.Error())
+	}
+}
+
+func TestDownloadHelmChartArchiveChecksResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/private.tgz":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		}
+	}))
+	defer server.Close()
+
+	_, _, err := downloadHelmChartArchive(GeneratorContext{}, Repository{Url: server.URL}, server.URL+"/private.tgz", "")
+	if assert.Error(t, err) {
+		assert.Equal(t, "chart archive at "+server.URL+"/private.tgz requires authentication (status code 401)", err.Error())
+	}
+	_, _, err = downloadHelmChartArchive(GeneratorContext{}, Repository{Url: server.URL}, server.URL+"/chart.tgz", "")
+	if assert.Error(t, err) {
+		assert.Equal(t, "chart archive at "+server.URL+"/chart.tgz is not a gzip archive (content type text/html)", err.Error())
+	}
+}
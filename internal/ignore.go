@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+const ignoreFile = ".generatorignore"
+
+func readGeneratorIgnore(fsys fs.FS) ([]string, error) {
+	content, err := fs.ReadFile(fsys, ignoreFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	patterns := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, strings.TrimSuffix(strings.TrimPrefix(line, "/"), "/"))
+	}
+	return patterns, nil
+}
+
+func isIgnoredFile(name string, patterns []string) bool {
+	if name == ignoreFile {
+		return true
+	}
+	for current := name; current != "." && current != "/"; current = path.Dir(current) {
+		if matchFilePatterns(current, patterns) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadGeneratorIgnore(t *testing.T) {
+	fsys := NewMemoryFS()
+	patterns, err := readGeneratorIgnore(fsys)
+	assert.NoError(t, err)
+	assert.Empty(t, patterns)
+
+	assert.NoError(t, fsys.WriteFile(ignoreFile, []byte("# hand written\nREADME.md\n\n/patches/\nresources/custom-*.yaml\n"), 0o644))
+	patterns, err = readGeneratorIgnore(fsys)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "patches", "resources/custom-*.yaml"}, patterns)
+
+	assert.True(t, isIgnoredFile(ignoreFile, patterns))
+	assert.True(t, isIgnoredFile("README.md", patterns))
+	assert.True(t, isIgnoredFile("nested/README.md", patterns))
+	assert.True(t, isIgnoredFile("patches/a/b.yaml", patterns))
+	assert.True(t, isIgnoredFile("resources/custom-secret.yaml", patterns))
+	assert.False(t, isIgnoredFile("resources/database-secret.yaml", patterns))
+}
+
+func TestWriteRespectsGeneratorIgnore(t *testing.T) {
+	fsys := NewMemoryFS()
+	assert.NoError(t, fsys.WriteFile(ignoreFile, []byte("README.md\nresources/database-secret.yaml\n"), 0o644))
+	assert.NoError(t, fsys.WriteFile("README.md", []byte("# notes"), 0o644))
+	assert.NoError(t, fsys.WriteFile("resources/database-secret.yaml", []byte("hand edited"), 0o644))
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "database-secret.yaml", Content: mockResource("Secret", "database")},
+		},
+	}
+
+	stats, err := write(fsys, result, writeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, syncStats{Written: 4, Unchanged: 0, Removed: 0}, *stats)
+	assert.Contains(t, fsys.Files(), "README.md")
+	assert.Contains(t, fsys.Files(), ignoreFile)
+	assert.Equal(t, "hand edited", string(fsys.Files()["resources/database-secret.yaml"]))
+}
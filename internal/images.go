@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ImageReference struct {
+	Dir        string `yaml:"dir"`
+	Chart      string `yaml:"chart,omitempty"`
+	Image      string `yaml:"image"`
+	Repository string `yaml:"repository"`
+	Tag        string `yaml:"tag,omitempty"`
+	Digest     string `yaml:"digest,omitempty"`
+}
+
+var containerListKeys = map[string]bool{
+	"containers":          true,
+	"initContainers":      true,
+	"ephemeralContainers": true,
+}
+
+func ListImages(dir string, opts RunOptions) ([]ImageReference, error) {
+	_, _, result, err := render(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return collectImages(".", "", *result)
+}
+
+func collectImages(dir string, chart string, result GeneratorResult) ([]ImageReference, error) {
+	if result.Source != nil {
+		chart = result.Source.Chart
+	}
+	images := []ImageReference{}
+	seen := map[string]bool{}
+	for _, resource := range result.Resources {
+		document := yaml.Node{}
+		err := yaml.Unmarshal([]byte(resource.Content), &document)
+		if err != nil {
+			return nil, fmt.Errorf("reading images of resource %s failed: %v", resource.File, err)
+		}
+		for _, image := range findYamlContainerImages(&document) {
+			if seen[image] {
+				continue
+			}
+			seen[image] = true
+			reference := parseImageReference(image)
+			reference.Dir = dir
+			reference.Chart = chart
+			images = append(images, reference)
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Image < images[j].Image
+	})
+	for _, child := range result.Children {
+		childImages, err := collectImages(path.Join(dir, child.Dir), chart, child.Result)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, childImages...)
+	}
+	return images, nil
+}
+
+func findYamlContainerImages(node *yaml.Node) []string {
+	images := []string{}
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, item := range node.Content {
+			images = append(images, findYamlContainerImages(item)...)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value := node.Content[i+1]
+			if containerListKeys[node.Content[i].Value] && value.Kind == yaml.SequenceNode {
+				for _, container := range value.Content {
+					image := yamlMappingValue(container, "image")
+					if image != nil && image.Kind == yaml.ScalarNode && image.Value != "" {
+						images = append(images, image.Value)
+					}
+				}
+				continue
+			}
+			images = append(images, findYamlContainerImages(value)...)
+		}
+	}
+	return images
+}
+
+func parseImageReference(image string) ImageReference {
+	reference := ImageReference{Image: image, Repository: image}
+	if i := strings.Index(reference.Repository, "@"); i >= 0 {
+		reference.Digest = reference.Repository[i+1:]
+		reference.Repository = reference.Repository[:i]
+	}
+	if i := strings.LastIndex(reference.Repository, ":"); i > strings.LastIndex(reference.Repository, "/") {
+		reference.Tag = reference.Repository[i+1:]
+		reference.Repository = reference.Repository[:i]
+	}
+	return reference
+}
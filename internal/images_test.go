@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImageReference(t *testing.T) {
+	assert.Equal(t, ImageReference{Image: "nginx", Repository: "nginx"}, parseImageReference("nginx"))
+	assert.Equal(t, ImageReference{Image: "nginx:1.25", Repository: "nginx", Tag: "1.25"}, parseImageReference("nginx:1.25"))
+	assert.Equal(t, ImageReference{Image: "localhost:5000/app", Repository: "localhost:5000/app"}, parseImageReference("localhost:5000/app"))
+	assert.Equal(t, ImageReference{Image: "ghcr.io/org/app:v1@sha256:abc", Repository: "ghcr.io/org/app", Tag: "v1", Digest: "sha256:abc"}, parseImageReference("ghcr.io/org/app:v1@sha256:abc"))
+}
+
+func TestCollectImages(t *testing.T) {
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: init
+          image: busybox:1.36
+      containers:
+        - name: app
+          image: ghcr.io/org/app:v1
+        - name: sidecar
+          image: busybox:1.36
+`
+	cronJob := `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: job
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: job
+              image: alpine@sha256:abc
+`
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "config-configmap.yaml", Content: mockResource("ConfigMap", "config")},
+		},
+		Children: []GeneratorResultChild{
+			{Dir: "app", Result: GeneratorResult{
+				Source: &GeneratorSource{Chart: "app"},
+				Resources: []GeneratorResource{
+					{ApiVersion: "apps/v1", Kind: "Deployment", File: "app-deployment.yaml", Content: deployment},
+					{ApiVersion: "batch/v1", Kind: "CronJob", File: "job-cronjob.yaml", Content: cronJob},
+				},
+			}},
+		},
+	}
+	images, err := collectImages(".", "", result)
+	assert.NoError(t, err)
+	assert.Equal(t, []ImageReference{
+		{Dir: "app", Chart: "app", Image: "alpine@sha256:abc", Repository: "alpine", Digest: "sha256:abc"},
+		{Dir: "app", Chart: "app", Image: "busybox:1.36", Repository: "busybox", Tag: "1.36"},
+		{Dir: "app", Chart: "app", Image: "ghcr.io/org/app:v1", Repository: "ghcr.io/org/app", Tag: "v1"},
+	}, images)
+}
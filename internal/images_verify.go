@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const defaultImageRegistry = "registry-1.docker.io"
+
+var imageRegistryBaseUrl = func(registry string) string {
+	return "https://" + registry
+}
+
+var imageManifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+func verifyImages(ctx GeneratorContext, images []ImageReference) error {
+	missing := []string{}
+	checked := map[string]bool{}
+	for _, image := range images {
+		if checked[image.Image] {
+			continue
+		}
+		checked[image.Image] = true
+		exists, err := imageExists(ctx, image)
+		if err != nil {
+			return networkErrorf("verifying image %s failed: %v", image.Image, err)
+		}
+		if !exists {
+			ctx.log().Warn("image does not exist", "image", image.Image, "dir", image.Dir)
+			missing = append(missing, image.Image)
+		}
+	}
+	if len(missing) > 0 {
+		return validationErrorf("images could not be found: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func splitImageRepository(repository string) (string, string) {
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		registry := parts[0]
+		if registry == "docker.io" || registry == "index.docker.io" {
+			registry = defaultImageRegistry
+		}
+		if registry == defaultImageRegistry && !strings.Contains(parts[1], "/") {
+			return registry, "library/" + parts[1]
+		}
+		return registry, parts[1]
+	}
+	if len(parts) == 1 {
+		return defaultImageRegistry, "library/" + repository
+	}
+	return defaultImageRegistry, repository
+}
+
+func imageExists(ctx GeneratorContext, image ImageReference) (bool, error) {
+	registry, repository := splitImageRepository(image.Repository)
+	reference := image.Digest
+	if reference == "" {
+		reference = image.Tag
+	}
+	if reference == "" {
+		reference = "latest"
+	}
+	manifestUrl := fmt.Sprintf("%s/v2/%s/manifests/%s", imageRegistryBaseUrl(registry), repository, reference)
+
+	resp, err := headImageManifest(ctx, manifestUrl, "")
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := fetchImageRegistryToken(ctx, resp.Header.Get("Www-Authenticate"))
+		if err != nil {
+			return false, err
+		}
+		resp, err = headImageManifest(ctx, manifestUrl, token)
+		if err != nil {
+			return false, err
+		}
+	}
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, manifestUrl)
+	}
+}
+
+func headImageManifest(ctx GeneratorContext, manifestUrl string, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx.context(), "HEAD", manifestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(imageManifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := ctx.httpDo(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+func fetchImageRegistryToken(ctx GeneratorContext, challenge string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	params := map[string]string{}
+	for _, match := range regexp.MustCompile(`(\w+)="([^"]*)"`).FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("authentication challenge %q is missing realm", challenge)
+	}
+	query := url.Values{}
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	tokenUrl := params["realm"]
+	if len(query) > 0 {
+		tokenUrl = tokenUrl + "?" + query.Encode()
+	}
+	resp, err := ctx.httpGet(tokenUrl)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d from %s", resp.StatusCode, tokenUrl)
+	}
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitImageRepository(t *testing.T) {
+	registry, repository := splitImageRepository("nginx")
+	assert.Equal(t, []string{"registry-1.docker.io", "library/nginx"}, []string{registry, repository})
+	registry, repository = splitImageRepository("bitnami/nginx")
+	assert.Equal(t, []string{"registry-1.docker.io", "bitnami/nginx"}, []string{registry, repository})
+	registry, repository = splitImageRepository("docker.io/nginx")
+	assert.Equal(t, []string{"registry-1.docker.io", "library/nginx"}, []string{registry, repository})
+	registry, repository = splitImageRepository("ghcr.io/org/app")
+	assert.Equal(t, []string{"ghcr.io", "org/app"}, []string{registry, repository})
+	registry, repository = splitImageRepository("localhost:5000/app")
+	assert.Equal(t, []string{"localhost:5000", "app"}, []string{registry, repository})
+}
+
+func TestVerifyImages(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			assert.Equal(t, "repository:org/app:pull", r.URL.Query().Get("scope"))
+			w.Write([]byte(`{"token":"secret"}`))
+		case "/v2/org/app/manifests/v1":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.Header().Set("Www-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry",scope="repository:org/app:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "HEAD", r.Method)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	originalBaseUrl := imageRegistryBaseUrl
+	imageRegistryBaseUrl = func(registry string) string { return server.URL }
+	defer func() { imageRegistryBaseUrl = originalBaseUrl }()
+
+	ctx := GeneratorContext{}
+	err := verifyImages(ctx, []ImageReference{parseImageReference("ghcr.io/org/app:v1")})
+	assert.NoError(t, err)
+
+	err = verifyImages(ctx, []ImageReference{parseImageReference("ghcr.io/org/app:v1"), parseImageReference("ghcr.io/org/app:v2")})
+	assert.EqualError(t, err, "images could not be found: ghcr.io/org/app:v2")
+	assert.Equal(t, 5, ExitCode(err))
+}
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type InitOptions struct {
+	Registry  string
+	Chart     string
+	Version   string
+	Name      string
+	Namespace string
+	Force     bool
+}
+
+type initConfig struct {
+	Type      string `yaml:"type"`
+	Registry  string `yaml:"registry,omitempty"`
+	Chart     string `yaml:"chart"`
+	Version   string `yaml:"version,omitempty"`
+	Name      string `yaml:"name,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+func InitHelm(dir string, initOpts InitOptions, opts RunOptions) (string, error) {
+	if initOpts.Chart == "" {
+		return "", configErrorf("chart missing")
+	}
+	file := filepath.Join(dir, configFile)
+	if _, err := os.Stat(file); err == nil && !initOpts.Force {
+		return "", configErrorf("%s already exists", file)
+	}
+
+	generator := HelmGenerator{
+		Registry:  initOpts.Registry,
+		Chart:     initOpts.Chart,
+		Version:   initOpts.Version,
+		Name:      initOpts.Name,
+		Namespace: initOpts.Namespace,
+	}
+	ctx, err := newGeneratorContext(dir, Config{}, opts)
+	if err != nil {
+		return "", err
+	}
+	helmPath, err := generator.resolveHelm(ctx)
+	if err != nil {
+		return "", err
+	}
+	chart, err := generator.resolveChart(ctx, helmPath)
+	if err != nil {
+		return "", err
+	}
+	defer chart.Cleanup()
+	stdout, stderr, err := ctx.runCommand(*exec.Command(helmPath, append([]string{"show", "values"}, chart.Args...)...))
+	if err != nil {
+		return "", executionErrorf("executing helm failed: %v\n%s", err, string(stderr))
+	}
+
+	if generator.Version == "" || isSemverConstraint(generator.Version) {
+		generator.Version = chart.Source.Version
+	}
+	content, err := renderInitConfig(generator, chart.Source, stdout)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", fmt.Errorf("writing configuration failed: %v", err)
+	}
+	err = os.WriteFile(file, content, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("writing configuration failed: %v", err)
+	}
+	return file, nil
+}
+
+func renderInitConfig(generator HelmGenerator, source GeneratorSource, defaults []byte) ([]byte, error) {
+	config := initConfig{
+		Type:      "helm",
+		Registry:  generator.Registry,
+		Chart:     generator.Chart,
+		Version:   generator.Version,
+		Name:      generator.Name,
+		Namespace: generator.Namespace,
+	}
+	result := bytes.Buffer{}
+	encoded, err := writeYaml(config)
+	if err != nil {
+		return nil, err
+	}
+	result.Write(encoded)
+	result.WriteString("values:\n")
+	lines := splitDiffLines(strings.TrimRight(string(defaults), "\n"))
+	if len(lines) == 0 {
+		return result.Bytes(), nil
+	}
+	result.WriteString(fmt.Sprintf("  # default values of %s %s, uncomment and adjust as needed\n", source.Chart, source.Version))
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			result.WriteString("  #\n")
+			continue
+		}
+		result.WriteString("  # " + line + "\n")
+	}
+	return result.Bytes(), nil
+}
@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitHelm(t *testing.T) {
+	bin := t.TempDir()
+	helm := filepath.Join(bin, "helm")
+	assert.NoError(t, os.WriteFile(helm, []byte("#!/bin/sh\nif [ \"$1\" = version ]; then echo v3.12.0; exit 0; fi\nprintf 'replicaCount: 1\\n\\nimage:\\n  tag: \"\"\\n'\n"), 0o755))
+	chartDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: app\nversion: 1.2.3\n"), 0o644))
+
+	dir := filepath.Join(t.TempDir(), "vendors", "app")
+	file, err := InitHelm(dir, InitOptions{Chart: chartDir, Namespace: "app"}, RunOptions{HelmBin: helm})
+	if assert.NoError(t, err) {
+		assert.Equal(t, filepath.Join(dir, configFile), file)
+		content, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, "type: helm\nchart: "+chartDir+"\nversion: 1.2.3\nnamespace: app\nvalues:\n  # default values of app 1.2.3, uncomment and adjust as needed\n  # replicaCount: 1\n  #\n  # image:\n  #   tag: \"\"\n", string(content))
+		config, err := LoadConfig(file)
+		assert.NoError(t, err)
+		assert.Equal(t, "1.2.3", config.Generator.(HelmGenerator).Version)
+	}
+
+	_, err = InitHelm(dir, InitOptions{Chart: chartDir}, RunOptions{HelmBin: helm})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "already exists")
+	}
+	_, err = InitHelm(dir, InitOptions{Chart: chartDir, Force: true}, RunOptions{HelmBin: helm})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type HelmLabelsConfig struct {
+	Strip     bool   `yaml:"strip"`
+	ManagedBy string `yaml:"managedBy"`
+}
+
+func rewriteHelmLabels(result GeneratorResult, config HelmLabelsConfig) (*GeneratorResult, error) {
+	return transformGeneratorResult(result, func(document *yaml.Node) (bool, error) {
+		return walkYamlMetadata(document, func(metadata *yaml.Node) bool {
+			labels := yamlMappingValue(metadata, "labels")
+			if labels == nil {
+				return false
+			}
+			changed := false
+			managedBy := yamlMappingValue(labels, "app.kubernetes.io/managed-by")
+			if managedBy != nil && managedBy.Value == "Helm" {
+				if config.ManagedBy != "" {
+					changed = yamlMappingSet(labels, "app.kubernetes.io/managed-by", config.ManagedBy) || changed
+				} else if config.Strip {
+					changed = yamlMappingRemove(labels, "app.kubernetes.io/managed-by") || changed
+				}
+			}
+			if config.Strip {
+				changed = yamlMappingRemove(labels, "helm.sh/chart") || changed
+				heritage := yamlMappingValue(labels, "heritage")
+				if heritage != nil && heritage.Value == "Helm" {
+					changed = yamlMappingRemove(labels, "heritage") || changed
+				}
+			}
+			return changed
+		}), nil
+	})
+}
+
+type ProvenanceConfig struct {
+	Labels      bool   `yaml:"labels"`
+	Annotations bool   `yaml:"annotations"`
+	Prefix      string `yaml:"prefix"`
+}
+
+func addProvenance(result GeneratorResult, config ProvenanceConfig) (*GeneratorResult, error) {
+	return addProvenanceWithSource(result, config, result.Source)
+}
+
+func addProvenanceWithSource(result GeneratorResult, config ProvenanceConfig, source *GeneratorSource) (*GeneratorResult, error) {
+	if result.Source != nil {
+		source = result.Source
+	}
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = "kustomization-generator"
+	}
+
+	annotated := result
+	annotated.Children = nil
+	if source != nil {
+		values := map[string]string{
+			"chart": strings.Trim(source.Chart+"-"+source.Version, "-"),
+		}
+		if source.AppVersion != "" {
+			values["app-version"] = source.AppVersion
+		}
+		keys := []string{"chart", "app-version"}
+		resources, err := transformGeneratorResult(GeneratorResult{Resources: result.Resources}, func(document *yaml.Node) (bool, error) {
+			metadata := yamlMappingEnsure(yamlDocumentRoot(document), "metadata")
+			changed := false
+			for _, key := range keys {
+				value, ok := values[key]
+				if !ok {
+					continue
+				}
+				if config.Labels {
+					changed = yamlMappingSet(yamlMappingEnsure(metadata, "labels"), prefix+"/"+key, sanitizeLabelValue(value)) || changed
+				}
+				if config.Annotations {
+					changed = yamlMappingSet(yamlMappingEnsure(metadata, "annotations"), prefix+"/"+key, value) || changed
+				}
+			}
+			return changed, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		annotated.Resources = resources.Resources
+	}
+	for _, child := range result.Children {
+		childResult, err := addProvenanceWithSource(child.Result, config, source)
+		if err != nil {
+			return nil, err
+		}
+		child.Result = *childResult
+		annotated.Children = append(annotated.Children, child)
+	}
+	return &annotated, nil
+}
+
+func sanitizeLabelValue(value string) string {
+	invalidRegex := regexp.MustCompile("[^A-Za-z0-9._-]+")
+	result := invalidRegex.ReplaceAllString(value, "_")
+	if len(result) > 63 {
+		result = result[:63]
+	}
+	return strings.Trim(result, "._-")
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteHelmLabels(t *testing.T) {
+	input := GeneratorResult{
+		Resources: []GeneratorResource{
+			{
+				File: "app-deployment.yaml",
+				Content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app: app
+    app.kubernetes.io/managed-by: Helm
+    helm.sh/chart: app-1.2.3
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+        helm.sh/chart: app-1.2.3
+`,
+			},
+		},
+	}
+
+	actual, err := rewriteHelmLabels(input, HelmLabelsConfig{Strip: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+`, actual.Resources[0].Content)
+	}
+
+	actual, err = rewriteHelmLabels(input, HelmLabelsConfig{Strip: true, ManagedBy: "kustomize"})
+	if assert.NoError(t, err) {
+		assert.Contains(t, actual.Resources[0].Content, "    app.kubernetes.io/managed-by: kustomize\n")
+		assert.NotContains(t, actual.Resources[0].Content, "helm.sh/chart")
+	}
+}
+
+func TestAddProvenance(t *testing.T) {
+	input := GeneratorResult{
+		Resources: []GeneratorResource{
+			{File: "database-secret.yaml", Content: mockResource("Secret", "database")},
+		},
+		Source: &GeneratorSource{Chart: "nginx", Version: "15.1.0", AppVersion: "1.25.2+build"},
+	}
+
+	actual, err := addProvenance(input, ProvenanceConfig{Labels: true, Annotations: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `apiVersion: v1
+kind: Secret
+metadata:
+  name: database
+  labels:
+    kustomization-generator/chart: nginx-15.1.0
+    kustomization-generator/app-version: 1.25.2_build
+  annotations:
+    kustomization-generator/chart: nginx-15.1.0
+    kustomization-generator/app-version: 1.25.2+build
+`, actual.Resources[0].Content)
+	}
+
+	input.Source = nil
+	actual, err = addProvenance(input, ProvenanceConfig{Labels: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, input.Resources, actual.Resources)
+	}
+}
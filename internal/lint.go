@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var kubernetesNamespaceRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+func LintConfig(dir string, opts RunOptions) ([]string, error) {
+	file := opts.ConfigFile
+	if file == "" {
+		file = filepath.Join(dir, configFile)
+	}
+	bytes, err := readConfigFile(file)
+	if err != nil {
+		return []string{fmt.Sprintf("unable to load configuration: %v", err)}, nil
+	}
+	findings := lintUnknownFields(bytes)
+	config, err := parseConfig(bytes)
+	if err != nil {
+		return append(findings, fmt.Sprintf("unable to load configuration: %v", err)), nil
+	}
+	findings = append(findings, lintConfigValues(dir, *config)...)
+	patterns, err := readGeneratorIgnore(os.DirFS(dir))
+	if err != nil {
+		findings = append(findings, fmt.Sprintf("unable to read %s: %v", ignoreFile, err))
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			findings = append(findings, fmt.Sprintf("invalid %s pattern %s: %v", ignoreFile, pattern, err))
+		}
+	}
+	if _, err := applyEnvironment(*config, opts.Environment); err != nil {
+		findings = append(findings, err.Error())
+	}
+	return findings, nil
+}
+
+func lintUnknownFields(bytes []byte) []string {
+	node := yaml.Node{}
+	err := yaml.Unmarshal(bytes, &node)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	root := documentRoot(&node)
+	if root == nil || root.Kind != yaml.MappingNode {
+		return nil
+	}
+	generator, err := parseGenerator(bytes)
+	if err != nil {
+		return nil
+	}
+	types := []reflect.Type{reflect.TypeOf(Config{})}
+	findings := []string{}
+	if multi, ok := (*generator).(MultiGenerator); ok {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != "generators" || root.Content[i+1].Kind != yaml.SequenceNode {
+				continue
+			}
+			for j, entry := range root.Content[i+1].Content {
+				if j >= len(multi.Generators) {
+					break
+				}
+				entryTypes := []reflect.Type{reflect.TypeOf(multi.Generators[j].Generator), reflect.TypeOf(struct {
+					Type      string `yaml:"type"`
+					Name      string `yaml:"name"`
+					OutputDir string `yaml:"outputDir"`
+				}{})}
+				for _, finding := range lintMappingFields(entry, entryTypes) {
+					findings = append(findings, fmt.Sprintf("generator %d: %s", j+1, finding))
+				}
+			}
+		}
+		types = append(types, reflect.TypeOf(struct {
+			Generators interface{} `yaml:"generators"`
+		}{}))
+	} else {
+		types = append(types, reflect.TypeOf(*generator))
+	}
+	return append(findings, lintMappingFields(root, types)...)
+}
+
+func lintMappingFields(node *yaml.Node, types []reflect.Type) []string {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	findings := []string{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		fieldType, ok := findYamlField(types, key.Value)
+		if !ok {
+			findings = append(findings, fmt.Sprintf("line %d: unknown field %s", key.Line, key.Value))
+			continue
+		}
+		if fieldType.Kind() == reflect.Interface {
+			continue
+		}
+		value := reflect.New(fieldType)
+		content, err := yaml.Marshal(node.Content[i+1])
+		if err != nil {
+			continue
+		}
+		decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+		decoder.KnownFields(true)
+		err = decoder.Decode(value.Interface())
+		if err != nil {
+			findings = append(findings, fmt.Sprintf("field %s (line %d): %s", key.Value, key.Line, strings.TrimPrefix(err.Error(), "yaml: unmarshal errors:\n  ")))
+		}
+	}
+	return findings
+}
+
+func findYamlField(types []reflect.Type, name string) (reflect.Type, bool) {
+	for _, t := range types {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			continue
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if tag == "" || tag == "-" {
+				continue
+			}
+			if tag == name {
+				return field.Type, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func lintConfigValues(dir string, config Config) []string {
+	findings := []string{}
+	for _, selector := range append(append([]ResourceSelector{}, config.Include...), config.Exclude...) {
+		if _, err := path.Match(selector.Name, ""); err != nil {
+			findings = append(findings, fmt.Sprintf("invalid name pattern %s: %v", selector.Name, err))
+		}
+	}
+	if config.Sops != nil && config.Sops.EncryptedRegex != "" {
+		if _, err := regexp.Compile(config.Sops.EncryptedRegex); err != nil {
+			findings = append(findings, fmt.Sprintf("invalid sops encryptedRegex %s: %v", config.Sops.EncryptedRegex, err))
+		}
+	}
+	if config.Seal != nil && config.Seal.Cert != "" && !strings.Contains(config.Seal.Cert, "://") {
+		findings = append(findings, lintFileExists(dir, "seal cert", config.Seal.Cert)...)
+	}
+	if config.Policies != nil {
+		for _, file := range append(append([]string{}, config.Policies.Paths...), config.Policies.Data...) {
+			findings = append(findings, lintFileExists(dir, "policy", file)...)
+		}
+	}
+	for _, generator := range collectHelmGenerators(config.Generator) {
+		findings = append(findings, lintHelmGenerator(dir, generator)...)
+	}
+	return findings
+}
+
+func lintHelmGenerator(dir string, g HelmGenerator) []string {
+	findings := []string{}
+	if g.Name != "" {
+		if err := validateHelmReleaseName(g.Name); err != nil {
+			findings = append(findings, err.Error())
+		}
+	}
+	if g.Namespace != "" && (len(g.Namespace) > 63 || !kubernetesNamespaceRegex.MatchString(g.Namespace)) {
+		findings = append(findings, fmt.Sprintf("invalid namespace %s: must be a lowercase RFC 1123 label of at most 63 characters", g.Namespace))
+	}
+	if g.Version != "" {
+		if _, err := parseSemver(g.Version); err != nil && !isSemverConstraint(g.Version) {
+			findings = append(findings, fmt.Sprintf("invalid version %s", g.Version))
+		}
+	}
+	if g.Registry == "" && g.Chart != "" {
+		findings = append(findings, lintFileExists(dir, "local chart", g.Chart)...)
+	}
+	keys := []string{}
+	files := map[string]string{}
+	var collect func(prefix string, values map[string]interface{})
+	collect = func(prefix string, values map[string]interface{}) {
+		for key, value := range values {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				collect(prefix+key+".", v)
+			case string:
+				keys = append(keys, prefix+key)
+				files[prefix+key] = v
+			}
+		}
+	}
+	collect("", g.SetFile)
+	sort.Strings(keys)
+	for _, key := range keys {
+		findings = append(findings, lintFileExists(dir, "setFile "+key, files[key])...)
+	}
+	return findings
+}
+
+func lintFileExists(dir string, description string, file string) []string {
+	if _, err := os.Stat(resolvePath(dir, file)); err != nil {
+		return []string{fmt.Sprintf("%s %s does not exist", description, file)}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintConfig(t *testing.T) {
+	dir := t.TempDir()
+	values := filepath.Join(dir, "values.txt")
+	assert.NoError(t, os.WriteFile(values, []byte("value"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte(`type: helm
+registry: https://charts.example.com
+chart: app
+version: 1.0.0
+name: app
+namespace: default
+setFile:
+  config: `+values+`
+exclude:
+  - name: "app-*"
+`), 0o644))
+	findings, err := LintConfig(dir, RunOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestLintConfigRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "chart"), 0o755))
+	for _, file := range []string{"values.txt", "cert.pem", "policy.rego"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte("content"), 0o644))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte(`type: helm
+chart: chart
+name: app
+setFile:
+  config: values.txt
+seal:
+  cert: cert.pem
+policies:
+  paths:
+    - policy.rego
+`), 0o644))
+	findings, err := LintConfig(dir, RunOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestLintConfigFindings(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte(`type: helm
+registry: https://charts.example.com
+chart: app
+version: 1.0.0
+name: App
+namespace: Default_NS
+unknownKey: true
+setFile:
+  config: `+filepath.Join(dir, "missing.txt")+`
+exclude:
+  - name: "app-["
+    nmae: typo
+sops:
+  encryptedRegex: "^(data"
+`), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ignoreFile), []byte("foo[\n"), 0o644))
+	findings, err := LintConfig(dir, RunOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, findings, 8)
+	joined := ""
+	for _, finding := range findings {
+		joined += finding + "\n"
+	}
+	assert.Contains(t, joined, "unknown field unknownKey")
+	assert.Contains(t, joined, "field nmae not found")
+	assert.Contains(t, joined, "invalid name pattern app-[")
+	assert.Contains(t, joined, "invalid sops encryptedRegex")
+	assert.Contains(t, joined, "invalid namespace Default_NS")
+	assert.Contains(t, joined, "missing.txt does not exist")
+	assert.Contains(t, joined, "invalid .generatorignore pattern foo[")
+}
+
+func TestLintConfigMulti(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte(`type: multi
+generators:
+  - type: helm
+    name: first
+    registry: https://charts.example.com
+    chart: app
+    version: 1.0.0
+    outputDir: first
+  - type: helm
+    name: second
+    registry: https://charts.example.com
+    chart: app
+    versoin: 1.0.0
+`), 0o644))
+	findings, err := LintConfig(dir, RunOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, findings, 1) {
+		assert.Contains(t, findings[0], "generator 2: line 13: unknown field versoin")
+	}
+}
+
+func TestLintConfigExamples(t *testing.T) {
+	dirs, err := FindConfigDirs("../example")
+	assert.NoError(t, err)
+	for _, dir := range dirs {
+		findings, err := LintConfig(dir, RunOptions{})
+		assert.NoError(t, err)
+		assert.Empty(t, findings, dir)
+	}
+}
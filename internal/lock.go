@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockPollInterval = 100 * time.Millisecond
+	lockStaleAfter   = 10 * time.Minute
+)
+
+func acquireFileLock(ctx GeneratorContext, file string) (func(), error) {
+	err := os.MkdirAll(filepath.Dir(file), 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("acquiring lock %s failed: %v", file, err)
+	}
+	for {
+		lockFile, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			fmt.Fprintf(lockFile, "%d\n", os.Getpid())
+			lockFile.Close()
+			return func() { os.Remove(file) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("acquiring lock %s failed: %v", file, err)
+		}
+		if info, err := os.Stat(file); err == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			ctx.log().Warn("removing stale lock", "file", file)
+			os.Remove(file)
+			continue
+		}
+		select {
+		case <-ctx.context().Done():
+			return nil, fmt.Errorf("acquiring lock %s failed: %v", file, ctx.context().Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
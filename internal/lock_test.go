@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireFileLock(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nested", "test.lock")
+	release, err := acquireFileLock(GeneratorContext{}, file)
+	assert.NoError(t, err)
+	assert.FileExists(t, file)
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	_, err = acquireFileLock(GeneratorContext{Context: timeoutCtx}, file)
+	assert.Error(t, err)
+
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		release()
+		close(released)
+	}()
+	release2, err := acquireFileLock(GeneratorContext{}, file)
+	assert.NoError(t, err)
+	<-released
+	release2()
+	assert.NoFileExists(t, file)
+}
+
+func TestAcquireFileLockStale(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.lock")
+	assert.NoError(t, os.WriteFile(file, []byte("1\n"), 0o644))
+	past := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(file, past, past))
+	release, err := acquireFileLock(GeneratorContext{}, file)
+	assert.NoError(t, err)
+	release()
+}
@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+func NewLogger(w io.Writer, format string, verbose bool) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: slog.LevelWarn}
+	if verbose {
+		opts.Level = slog.LevelInfo
+	}
+	switch format {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format %s", format)
+	}
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func (ctx GeneratorContext) log() *slog.Logger {
+	if ctx.Logger == nil {
+		return discardLogger()
+	}
+	return ctx.Logger
+}
@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogger(t *testing.T) {
+	output := strings.Builder{}
+	logger, err := NewLogger(&output, "text", false)
+	if assert.NoError(t, err) {
+		logger.Info("chart resolved")
+		logger.Warn("chart is deprecated")
+		assert.NotContains(t, output.String(), "chart resolved")
+		assert.Contains(t, output.String(), "chart is deprecated")
+	}
+
+	output.Reset()
+	logger, err = NewLogger(&output, "json", true)
+	if assert.NoError(t, err) {
+		logger.Info("chart resolved")
+		assert.Contains(t, output.String(), `"msg":"chart resolved"`)
+	}
+
+	_, err = NewLogger(&output, "xml", false)
+	assert.Error(t, err)
+}
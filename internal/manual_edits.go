@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+func validateManualEdits(mode string) error {
+	if mode != "" && mode != "warn" && mode != "error" && mode != "ignore" {
+		return configErrorf("unsupported manualEdits %s (expected warn, error or ignore)", mode)
+	}
+	return nil
+}
+
+func hashWrittenFiles(files map[string][]byte) map[string]string {
+	hashes := map[string]string{}
+	for name, content := range files {
+		if name == metadataFile {
+			continue
+		}
+		hash := sha256.Sum256(content)
+		hashes[name] = outputDigestPrefix + hex.EncodeToString(hash[:])
+	}
+	return hashes
+}
+
+func detectManualEdits(fsys fs.FS) ([]string, error) {
+	report, err := readMetadataReport(fsys)
+	if err != nil || report == nil {
+		return nil, err
+	}
+	current := map[string][]byte{}
+	for name := range report.Files {
+		content, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s failed: %v", name, err)
+		}
+		current[name] = content
+	}
+	edited := []string{}
+	for name, hash := range hashWrittenFiles(current) {
+		if hash != report.Files[name] {
+			edited = append(edited, name)
+		}
+	}
+	sort.Strings(edited)
+	return edited, nil
+}
+
+func checkManualEdits(ctx GeneratorContext, fsys fs.FS, mode string) error {
+	if mode == "ignore" {
+		return nil
+	}
+	edited, err := detectManualEdits(fsys)
+	if err != nil {
+		return err
+	}
+	if len(edited) == 0 {
+		return nil
+	}
+	if mode == "error" {
+		return validationErrorf("generated files were modified manually: %s", strings.Join(edited, ", "))
+	}
+	for _, name := range edited {
+		ctx.log().Warn("generated file was modified manually and will be overwritten", "file", name)
+	}
+	return nil
+}
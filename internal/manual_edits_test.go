@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectManualEdits(t *testing.T) {
+	fsys := NewMemoryFS()
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "a-configmap.yaml", Content: mockResource("ConfigMap", "a")},
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "b-configmap.yaml", Content: mockResource("ConfigMap", "b")},
+		},
+	}
+	opts := writeOptions{Metadata: &MetadataReport{Type: "helm"}}
+	_, err := write(fsys, result, opts)
+	assert.NoError(t, err)
+
+	edited, err := detectManualEdits(fsys)
+	assert.NoError(t, err)
+	assert.Empty(t, edited)
+
+	assert.NoError(t, fsys.WriteFile("resources/b-configmap.yaml", []byte("hotfix: true\n"), 0o644))
+	assert.NoError(t, fsys.Remove("resources/a-configmap.yaml"))
+	edited, err = detectManualEdits(fsys)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"resources/b-configmap.yaml"}, edited)
+
+	assert.NoError(t, checkManualEdits(GeneratorContext{}, fsys, ""))
+	assert.NoError(t, checkManualEdits(GeneratorContext{}, fsys, "ignore"))
+	err = checkManualEdits(GeneratorContext{}, fsys, "error")
+	if assert.Error(t, err) {
+		assert.Equal(t, 5, ExitCode(err))
+		assert.Contains(t, err.Error(), "resources/b-configmap.yaml")
+	}
+
+	_, err = write(fsys, result, opts)
+	assert.NoError(t, err)
+	edited, err = detectManualEdits(fsys)
+	assert.NoError(t, err)
+	assert.Empty(t, edited)
+}
+
+func TestDetectManualEditsWithoutMetadata(t *testing.T) {
+	edited, err := detectManualEdits(NewMemoryFS())
+	assert.NoError(t, err)
+	assert.Empty(t, edited)
+}
+
+func TestValidateManualEdits(t *testing.T) {
+	assert.NoError(t, validateManualEdits(""))
+	assert.NoError(t, validateManualEdits("error"))
+	assert.Error(t, validateManualEdits("overwrite"))
+}
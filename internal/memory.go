@@ -0,0 +1,13 @@
+package internal
+
+func GenerateInMemory(dir string, opts RunOptions) ([]GeneratorResource, *Kustomization, error) {
+	_, config, result, err := render(dir, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, kustomization, err := renderOutputKustomization(*result, newWriteOptions(*config))
+	if err != nil {
+		return nil, nil, err
+	}
+	return orderResources(*result), kustomization, nil
+}
@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateInMemory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(mockResource("ConfigMap", "app") + "---\n" + mockResource("Namespace", "app")))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := fmt.Sprintf("type: download\nurl: %s\n", server.URL)
+	resources, kustomization, err := GenerateInMemory(dir, RunOptions{ConfigFile: "-", Stdin: strings.NewReader(config)})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Namespace", File: "app-namespace.yaml", Content: mockResource("Namespace", "app")},
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "app-configmap.yaml", Content: mockResource("ConfigMap", "app")},
+		}, resources)
+		assert.Equal(t, &Kustomization{Resources: []string{"crds", "namespaces", "resources"}}, kustomization)
+	}
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	_, err = os.Stat(filepath.Join(dir, "kustomization.yaml"))
+	assert.True(t, os.IsNotExist(err))
+}
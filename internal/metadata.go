@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+)
+
+const metadataFile = ".kustomization-helm.meta.yaml"
+
+type MetadataReport struct {
+	Type        string            `yaml:"type"`
+	ToolVersion string            `yaml:"toolVersion,omitempty"`
+	GeneratedAt string            `yaml:"generatedAt"`
+	Sources     []MetadataSource  `yaml:"sources,omitempty"`
+	Files       map[string]string `yaml:"files,omitempty"`
+}
+
+type MetadataSource struct {
+	Dir        string `yaml:"dir"`
+	Chart      string `yaml:"chart,omitempty"`
+	Version    string `yaml:"version,omitempty"`
+	AppVersion string `yaml:"appVersion,omitempty"`
+	Digest     string `yaml:"digest,omitempty"`
+}
+
+func newMetadataReport(config Config, toolVersion string, result GeneratorResult, now time.Time) MetadataReport {
+	return MetadataReport{
+		Type:        config.Type,
+		ToolVersion: toolVersion,
+		GeneratedAt: now.UTC().Format(time.RFC3339),
+		Sources:     collectMetadataSources(".", result),
+	}
+}
+
+func readMetadataReport(fsys fs.FS) (*MetadataReport, error) {
+	bytes, err := fs.ReadFile(fsys, metadataFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading metadata report failed: %v", err)
+	}
+	report := MetadataReport{}
+	err = readYaml(bytes, &report)
+	if err != nil {
+		return nil, fmt.Errorf("reading metadata report failed: %v", err)
+	}
+	return &report, nil
+}
+
+func keepMetadataTimestamp(report MetadataReport, previous *MetadataReport) MetadataReport {
+	if previous == nil {
+		return report
+	}
+	current := report
+	current.GeneratedAt = previous.GeneratedAt
+	currentBytes, err := writeYaml(current)
+	if err != nil {
+		return report
+	}
+	previousBytes, err := writeYaml(previous)
+	if err != nil || !bytes.Equal(currentBytes, previousBytes) {
+		return report
+	}
+	return current
+}
+
+func collectMetadataSources(dir string, result GeneratorResult) []MetadataSource {
+	sources := []MetadataSource{}
+	if result.Source != nil {
+		sources = append(sources, MetadataSource{
+			Dir:        dir,
+			Chart:      result.Source.Chart,
+			Version:    result.Source.Version,
+			AppVersion: result.Source.AppVersion,
+			Digest:     result.Source.Digest,
+		})
+	}
+	for _, child := range result.Children {
+		sources = append(sources, collectMetadataSources(path.Join(dir, child.Dir), child.Result)...)
+	}
+	return sources
+}
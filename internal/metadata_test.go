@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMetadataReport(t *testing.T) {
+	result := GeneratorResult{
+		Children: []GeneratorResultChild{
+			{Dir: "app", Result: GeneratorResult{Source: &GeneratorSource{Chart: "app", Version: "1.2.3", AppVersion: "4.5.6", Digest: "sha256:abc"}}},
+			{Dir: "crds", Result: GeneratorResult{}},
+		},
+	}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	report := newMetadataReport(Config{Type: "multi"}, "v1.0.0", result, now)
+	assert.Equal(t, MetadataReport{
+		Type:        "multi",
+		ToolVersion: "v1.0.0",
+		GeneratedAt: "2024-01-02T02:04:05Z",
+		Sources: []MetadataSource{
+			{Dir: "app", Chart: "app", Version: "1.2.3", AppVersion: "4.5.6", Digest: "sha256:abc"},
+		},
+	}, report)
+}
+
+func TestKeepMetadataTimestamp(t *testing.T) {
+	report := MetadataReport{Type: "helm", GeneratedAt: "2024-02-01T00:00:00Z", Sources: []MetadataSource{}, Files: map[string]string{"kustomization.yaml": "sha256:a"}}
+	assert.Equal(t, report, keepMetadataTimestamp(report, nil))
+
+	fsys := NewMemoryFS()
+	assert.NoError(t, fsys.WriteFile(metadataFile, []byte("type: helm\ngeneratedAt: \"2024-01-01T00:00:00Z\"\nfiles:\n  kustomization.yaml: sha256:a\n"), 0o644))
+	previous, err := readMetadataReport(fsys)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2024-01-01T00:00:00Z", keepMetadataTimestamp(report, previous).GeneratedAt)
+		changed := report
+		changed.Files = map[string]string{"kustomization.yaml": "sha256:b"}
+		assert.Equal(t, "2024-02-01T00:00:00Z", keepMetadataTimestamp(changed, previous).GeneratedAt)
+	}
+
+	previous, err = readMetadataReport(NewMemoryFS())
+	assert.NoError(t, err)
+	assert.Nil(t, previous)
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+type PhaseMetric struct {
+	Dir      string  `json:"dir"`
+	Phase    string  `json:"phase"`
+	Duration float64 `json:"durationSeconds"`
+}
+
+type DirMetric struct {
+	Dir          string  `json:"dir"`
+	Duration     float64 `json:"durationSeconds"`
+	SlowestPhase string  `json:"slowestPhase"`
+}
+
+type Metrics struct {
+	mu     sync.Mutex
+	Phases []PhaseMetric `json:"phases"`
+	Dirs   []DirMetric   `json:"dirs"`
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{Phases: []PhaseMetric{}, Dirs: []DirMetric{}}
+}
+
+func (m *Metrics) recordPhase(dir string, phase string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Phases = append(m.Phases, PhaseMetric{Dir: dir, Phase: phase, Duration: duration.Seconds()})
+}
+
+func (m *Metrics) recordDir(dir string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	slowest := PhaseMetric{}
+	for _, phase := range m.Phases {
+		if phase.Dir == dir && phase.Duration > slowest.Duration {
+			slowest = phase
+		}
+	}
+	m.Dirs = append(m.Dirs, DirMetric{Dir: dir, Duration: duration.Seconds(), SlowestPhase: slowest.Phase})
+}
+
+func (m *Metrics) SlowestDirs() []DirMetric {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := append([]DirMetric{}, m.Dirs...)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Duration > result[j].Duration
+	})
+	return result
+}
+
+func (m *Metrics) WriteFile(file string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(bytes, '\n'), 0o644)
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+	ctx := GeneratorContext{Dir: "a", Metrics: metrics}
+	ctx.Phase("templating")()
+	metrics.recordPhase("a", "index fetch", 2*time.Second)
+	metrics.recordDir("a", 3*time.Second)
+	metrics.recordPhase("b", "templating", 5*time.Second)
+	metrics.recordDir("b", 6*time.Second)
+
+	assert.Len(t, metrics.Phases, 3)
+	assert.Equal(t, []DirMetric{
+		{Dir: "b", Duration: 6, SlowestPhase: "templating"},
+		{Dir: "a", Duration: 3, SlowestPhase: "index fetch"},
+	}, metrics.SlowestDirs())
+
+	file := filepath.Join(t.TempDir(), "metrics.json")
+	assert.NoError(t, metrics.WriteFile(file))
+	bytes, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	written := Metrics{}
+	assert.NoError(t, json.Unmarshal(bytes, &written))
+	assert.Equal(t, "index fetch", written.Phases[1].Phase)
+	assert.Equal(t, 2.0, written.Phases[1].Duration)
+
+	var disabled *Metrics
+	disabled.recordPhase("a", "templating", time.Second)
+	disabled.recordDir("a", time.Second)
+}
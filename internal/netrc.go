@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+type netrcEntry struct {
+	Machine  string
+	Login    string
+	Password string
+}
+
+func netrcFile() string {
+	if file := os.Getenv("NETRC"); file != "" {
+		return file
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+func parseNetrc(content string) []netrcEntry {
+	entries := []netrcEntry{}
+	var current *netrcEntry
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	inMacro := false
+	tokens := []string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inMacro {
+			if strings.TrimSpace(line) == "" {
+				inMacro = false
+			}
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			if fields[i] == "macdef" {
+				inMacro = true
+				break
+			}
+			tokens = append(tokens, fields[i])
+		}
+	}
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "machine":
+			if i+1 < len(tokens) {
+				entries = append(entries, netrcEntry{Machine: tokens[i+1]})
+				current = &entries[len(entries)-1]
+				i++
+			}
+		case "default":
+			entries = append(entries, netrcEntry{})
+			current = &entries[len(entries)-1]
+		case "login", "password", "account":
+			if i+1 < len(tokens) && current != nil {
+				if tokens[i] == "login" {
+					current.Login = tokens[i+1]
+				} else if tokens[i] == "password" {
+					current.Password = tokens[i+1]
+				}
+				i++
+			}
+		}
+	}
+	return entries
+}
+
+func lookupNetrc(file string, host string) *netrcEntry {
+	if file == "" {
+		return nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var fallback *netrcEntry
+	entries := parseNetrc(string(content))
+	for i, entry := range entries {
+		if entry.Machine == host {
+			return &entries[i]
+		}
+		if entry.Machine == "" && fallback == nil {
+			fallback = &entries[i]
+		}
+	}
+	return fallback
+}
+
+func applyNetrcCredentials(repository Repository, file string) Repository {
+	if repository.Username != "" || repository.Password != "" || repository.AuthEnv != "" {
+		return repository
+	}
+	if !strings.HasPrefix(repository.Url, "https://") && !strings.HasPrefix(repository.Url, "http://") {
+		return repository
+	}
+	u, err := url.Parse(repository.Url)
+	if err != nil {
+		return repository
+	}
+	entry := lookupNetrc(file, u.Hostname())
+	if entry == nil {
+		return repository
+	}
+	repository.Username = entry.Login
+	repository.Password = entry.Password
+	return repository
+}
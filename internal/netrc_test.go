@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNetrc(t *testing.T) {
+	entries := parseNetrc(`# comment
+machine charts.example.com login ci password secret
+machine other.example.com
+  login other
+  account ignored
+  password other-secret
+
+macdef init
+  cd /pub
+  binary
+
+default login anonymous password guest
+`)
+	assert.Equal(t, []netrcEntry{
+		{Machine: "charts.example.com", Login: "ci", Password: "secret"},
+		{Machine: "other.example.com", Login: "other", Password: "other-secret"},
+		{Login: "anonymous", Password: "guest"},
+	}, entries)
+}
+
+func TestApplyNetrcCredentials(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".netrc")
+	assert.NoError(t, os.WriteFile(file, []byte("machine charts.example.com login ci password secret\n"), 0o600))
+
+	assert.Equal(t, Repository{Url: "https://charts.example.com/stable", Username: "ci", Password: "secret"}, applyNetrcCredentials(Repository{Url: "https://charts.example.com/stable"}, file))
+	assert.Equal(t, Repository{Url: "https://charts.example.com", Username: "own"}, applyNetrcCredentials(Repository{Url: "https://charts.example.com", Username: "own"}, file))
+	assert.Equal(t, Repository{Url: "https://charts.other.com"}, applyNetrcCredentials(Repository{Url: "https://charts.other.com"}, file))
+	assert.Equal(t, Repository{Url: "oci://charts.example.com/app"}, applyNetrcCredentials(Repository{Url: "oci://charts.example.com/app"}, file))
+	assert.Equal(t, Repository{Url: "https://charts.example.com"}, applyNetrcCredentials(Repository{Url: "https://charts.example.com"}, filepath.Join(t.TempDir(), "missing")))
+}
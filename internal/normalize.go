@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+var normalizedTopLevelKeys = []string{"apiVersion", "kind", "metadata"}
+
+var yaml11BoolRegex = regexp.MustCompile(`^(y|Y|yes|Yes|YES|n|N|no|No|NO|on|On|ON|off|Off|OFF)$`)
+
+func normalizeGeneratorResult(result GeneratorResult) (*GeneratorResult, error) {
+	return transformGeneratorResult(result, func(document *yaml.Node) (bool, error) {
+		root := yamlDocumentRoot(document)
+		normalizeYamlNode(root, normalizedTopLevelKeys)
+		return true, nil
+	})
+}
+
+func normalizeYamlNode(node *yaml.Node, priorityKeys []string) {
+	if node.Kind == yaml.ScalarNode || node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode {
+		node.Style = 0
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && yaml11BoolRegex.MatchString(node.Value) {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			normalizeYamlNode(item, nil)
+		}
+	case yaml.MappingNode:
+		type pair struct {
+			key   *yaml.Node
+			value *yaml.Node
+		}
+		pairs := []pair{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			normalizeYamlNode(node.Content[i], nil)
+			normalizeYamlNode(node.Content[i+1], nil)
+			pairs = append(pairs, pair{key: node.Content[i], value: node.Content[i+1]})
+		}
+		priority := func(key string) int {
+			for i, priorityKey := range priorityKeys {
+				if key == priorityKey {
+					return i
+				}
+			}
+			return len(priorityKeys)
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			pi, pj := priority(pairs[i].key.Value), priority(pairs[j].key.Value)
+			if pi != pj {
+				return pi < pj
+			}
+			return pairs[i].key.Value < pairs[j].key.Value
+		})
+		node.Content = node.Content[:0]
+		for _, p := range pairs {
+			node.Content = append(node.Content, p.key, p.value)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeGeneratorResult(t *testing.T) {
+	input := GeneratorResult{
+		Resources: []GeneratorResource{
+			{
+				File: "config-configmap.yaml",
+				Content: `kind: ConfigMap
+metadata: {name: config, labels: {b: "2", a: "1"}}
+apiVersion: v1
+data:
+    version: "1.20"
+    enabled: 'on'
+    list: [b, a]
+`,
+			},
+		},
+	}
+
+	actual, err := normalizeGeneratorResult(input)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    a: "1"
+    b: "2"
+  name: config
+data:
+  enabled: "on"
+  list:
+    - b
+    - a
+  version: "1.20"
+`, actual.Resources[0].Content)
+	}
+}
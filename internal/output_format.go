@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func validateOutputFormat(config Config) error {
+	switch config.OutputFormat {
+	case "", "yaml":
+		return nil
+	case "json":
+		if config.Header {
+			return configErrorf("header is not supported with outputFormat json")
+		}
+		if config.Sops != nil {
+			return configErrorf("sops is not supported with outputFormat json")
+		}
+		return nil
+	default:
+		return configErrorf("unsupported outputFormat %s", config.OutputFormat)
+	}
+}
+
+func convertResourcesToJson(result GeneratorResult) (*GeneratorResult, error) {
+	return mapGeneratorResources(result, func(resource GeneratorResource) (GeneratorResource, error) {
+		document := yaml.Node{}
+		err := yaml.Unmarshal([]byte(resource.Content), &document)
+		if err == nil && len(document.Content) == 0 {
+			err = fmt.Errorf("empty document")
+		}
+		var content interface{}
+		if err == nil {
+			content, err = yamlNodeToJsonValue(&document)
+		}
+		if err != nil {
+			return resource, fmt.Errorf("converting resource %s to json failed: %v", resource.File, err)
+		}
+		bytes, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return resource, fmt.Errorf("converting resource %s to json failed: %v", resource.File, err)
+		}
+		resource.Content = string(bytes) + "\n"
+		resource.File = strings.TrimSuffix(strings.TrimSuffix(resource.File, ".yaml"), ".yml") + ".json"
+		return resource, nil
+	})
+}
+
+func yamlNodeToJsonValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return yamlNodeToJsonValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlNodeToJsonValue(node.Alias)
+	case yaml.MappingNode:
+		result := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := yamlNodeToJsonValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			result[node.Content[i].Value] = value
+		}
+		return result, nil
+	case yaml.SequenceNode:
+		result := []interface{}{}
+		for _, item := range node.Content {
+			value, err := yamlNodeToJsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	default:
+		if node.ShortTag() == "!!timestamp" || node.ShortTag() == "!!binary" {
+			return node.Value, nil
+		}
+		var value interface{}
+		err := node.Decode(&value)
+		return value, err
+	}
+}
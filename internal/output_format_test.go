@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOutputFormat(t *testing.T) {
+	assert.NoError(t, validateOutputFormat(Config{}))
+	assert.NoError(t, validateOutputFormat(Config{OutputFormat: "yaml", Header: true}))
+	assert.NoError(t, validateOutputFormat(Config{OutputFormat: "json"}))
+	assert.Error(t, validateOutputFormat(Config{OutputFormat: "json", Header: true}))
+	assert.Error(t, validateOutputFormat(Config{OutputFormat: "json", Sops: &SopsConfig{}}))
+	assert.Error(t, validateOutputFormat(Config{OutputFormat: "toml"}))
+}
+
+func TestRenderOutputJson(t *testing.T) {
+	result := GeneratorResult{
+		Resources: []GeneratorResource{{
+			ApiVersion: "v1",
+			Kind:       "ConfigMap",
+			File:       "app-configmap.yaml",
+			Content:    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  enabled: \"yes\"\n  date: \"2021-01-01\"\n  version: \"1.20\"\n",
+		}},
+	}
+	files, err := renderOutput(result, writeOptions{Format: "json"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{
+  "apiVersion": "v1",
+  "data": {
+    "date": "2021-01-01",
+    "enabled": "yes",
+    "version": "1.20"
+  },
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "app"
+  }
+}
+`, string(files["resources/app-configmap.json"]))
+		assert.NotContains(t, files, "resources/app-configmap.yaml")
+		assert.Contains(t, string(files["resources/kustomization.yaml"]), "- app-configmap.json")
+	}
+}
+
+func TestConvertResourcesToJson(t *testing.T) {
+	result, err := convertResourcesToJson(GeneratorResult{Resources: []GeneratorResource{{File: "a.yaml", Content: "date: 2021-01-01\nhex: 0x10\n1: one\nlist: [true, null, 1.5]\nbase: &base\n  a: 1\nref: *base\n"}}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "a.json", result.Resources[0].File)
+		assert.JSONEq(t, `{"date": "2021-01-01", "hex": 16, "1": "one", "list": [true, null, 1.5], "base": {"a": 1}, "ref": {"a": 1}}`, result.Resources[0].Content)
+	}
+}
+
+func TestConvertResourcesToJsonFileNames(t *testing.T) {
+	result, err := convertResourcesToJson(GeneratorResult{Resources: []GeneratorResource{{File: "a.yaml", Content: "a: 1\n"}, {File: "b.yml", Content: "b: 1\n"}}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "a.json", result.Resources[0].File)
+		assert.Equal(t, "b.json", result.Resources[1].File)
+	}
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultOwnershipLabel = "kustomization-generator/owner"
+
+var labelValueRegex = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)?$`)
+var labelKeyRegex = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
+type OwnershipConfig struct {
+	Label string `yaml:"label"`
+	Value string `yaml:"value"`
+}
+
+func validateOwnership(config *OwnershipConfig) error {
+	if config == nil {
+		return nil
+	}
+	if config.Label != "" && !labelKeyRegex.MatchString(config.Label) {
+		return configErrorf("invalid ownership label %s", config.Label)
+	}
+	if config.Value != "" && (len(config.Value) > 63 || !labelValueRegex.MatchString(config.Value)) {
+		return configErrorf("invalid ownership value %s: must be a valid label value of at most 63 characters", config.Value)
+	}
+	return nil
+}
+
+func ownershipValue(dir string, generator Generator, configType string) string {
+	name := configType
+	if helm, ok := generator.(HelmGenerator); ok {
+		name = helm.Name
+		if name == "" {
+			name = helm.Chart
+		}
+		if name == "" {
+			name = path.Base(helm.Registry)
+		}
+	}
+	owner := strings.Trim(ownershipDir(dir)+"/"+name, "/")
+	value := sanitizeLabelValue(owner)
+	if len(owner) > 63 {
+		hash := sha256.Sum256([]byte(owner))
+		if len(value) > 54 {
+			value = value[:54]
+		}
+		value = strings.Trim(value, "._-") + "-" + hex.EncodeToString(hash[:])[:8]
+	}
+	return value
+}
+
+func ownershipDir(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.ToSlash(dir)
+	}
+	for current := abs; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			rel, err := filepath.Rel(current, abs)
+			if err != nil || rel == "." {
+				return ""
+			}
+			return filepath.ToSlash(rel)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return filepath.ToSlash(filepath.Clean(dir))
+		}
+		current = parent
+	}
+}
+
+func addOwnership(result GeneratorResult, config OwnershipConfig, value string) (*GeneratorResult, error) {
+	label := config.Label
+	if label == "" {
+		label = defaultOwnershipLabel
+	}
+	if config.Value != "" {
+		value = config.Value
+	}
+	return transformGeneratorResult(result, func(document *yaml.Node) (bool, error) {
+		metadata := yamlMappingEnsure(yamlDocumentRoot(document), "metadata")
+		return yamlMappingSet(yamlMappingEnsure(metadata, "labels"), label, value), nil
+	})
+}
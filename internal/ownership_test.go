@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOwnership(t *testing.T) {
+	assert.NoError(t, validateOwnership(nil))
+	assert.NoError(t, validateOwnership(&OwnershipConfig{}))
+	assert.NoError(t, validateOwnership(&OwnershipConfig{Label: "applyset.kubernetes.io/part-of", Value: "applyset-abc-v1"}))
+	assert.Error(t, validateOwnership(&OwnershipConfig{Label: "invalid label"}))
+	assert.Error(t, validateOwnership(&OwnershipConfig{Value: "vendors/app"}))
+}
+
+func TestOwnershipValue(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0o755))
+	dir := filepath.Join(root, "vendors", "cert-manager")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+
+	assert.Equal(t, "vendors_cert-manager_cert-manager", ownershipValue(dir, HelmGenerator{Chart: "cert-manager"}, "helm"))
+	assert.Equal(t, "vendors_cert-manager_certs", ownershipValue(dir, HelmGenerator{Chart: "cert-manager", Name: "certs"}, "helm"))
+	assert.Equal(t, "vendors_cert-manager_podinfo", ownershipValue(dir, HelmGenerator{Registry: "oci://ghcr.io/stefanprodan/charts/podinfo"}, "helm"))
+	assert.Equal(t, "vendors_cert-manager_kustomize", ownershipValue(dir, KustomizeGenerator{}, "kustomize"))
+	assert.Equal(t, "helm", ownershipValue(root, HelmGenerator{Name: "helm"}, "helm"))
+
+	long := ownershipValue(filepath.Join(root, "a-very-long-directory-name-for-a-vendored-chart", "with-another-nested-directory"), HelmGenerator{Chart: "app"}, "helm")
+	assert.Len(t, long, 63)
+	assert.Regexp(t, `^a-very-long-directory-name-for-a-vendored-chart_with-a-[0-9a-f]{8}$`, long)
+}
+
+func TestAddOwnership(t *testing.T) {
+	result := GeneratorResult{
+		Resources: []GeneratorResource{{File: "a.yaml", Content: mockResource("ConfigMap", "a")}},
+		Children:  []GeneratorResultChild{{Dir: "child", Result: GeneratorResult{Resources: []GeneratorResource{{File: "b.yaml", Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n  labels:\n    app: b\n"}}}}},
+	}
+	owned, err := addOwnership(result, OwnershipConfig{}, "vendors_app_app")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n  labels:\n    kustomization-generator/owner: vendors_app_app\n", owned.Resources[0].Content)
+		assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n  labels:\n    app: b\n    kustomization-generator/owner: vendors_app_app\n", owned.Children[0].Result.Resources[0].Content)
+	}
+	owned, err = addOwnership(result, OwnershipConfig{Label: "applyset.kubernetes.io/part-of", Value: "applyset-abc-v1"}, "vendors_app_app")
+	if assert.NoError(t, err) {
+		assert.Contains(t, owned.Resources[0].Content, "applyset.kubernetes.io/part-of: applyset-abc-v1\n")
+	}
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func updateParentKustomization(dir string, parent string) (string, bool, error) {
+	parentDir := filepath.Join(dir, parent)
+	entry, err := filepath.Rel(parentDir, dir)
+	if err != nil {
+		return "", false, configErrorf("invalid parentKustomization %s: %v", parent, err)
+	}
+	entry = filepath.ToSlash(entry)
+	if entry == "." || entry == ".." || strings.HasPrefix(entry, "../") {
+		return "", false, configErrorf("invalid parentKustomization %s: must be an ancestor of the target directory", parent)
+	}
+	file := filepath.Join(parentDir, "kustomization.yaml")
+	for _, name := range []string{"kustomization.yml", "Kustomization"} {
+		if _, err := os.Stat(filepath.Join(parentDir, name)); err == nil {
+			file = filepath.Join(parentDir, name)
+		}
+	}
+
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		content = []byte("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\n")
+	} else if err != nil {
+		return "", false, fmt.Errorf("updating parent kustomization failed: %v", err)
+	}
+	node := yaml.Node{}
+	err = yaml.Unmarshal(content, &node)
+	if err != nil {
+		return "", false, fmt.Errorf("updating parent kustomization %s failed: %v", file, err)
+	}
+	root := documentRoot(&node)
+	if root == nil || root.Kind != yaml.MappingNode {
+		return "", false, fmt.Errorf("updating parent kustomization %s failed: expected a mapping", file)
+	}
+
+	var resources *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "resources" {
+			resources = root.Content[i+1]
+		}
+	}
+	if resources == nil {
+		resources = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "resources"}, resources)
+	}
+	if resources.Kind != yaml.SequenceNode {
+		if resources.Tag != "!!null" {
+			return "", false, fmt.Errorf("updating parent kustomization %s failed: resources must be a list", file)
+		}
+		resources.Kind = yaml.SequenceNode
+		resources.Tag = "!!seq"
+		resources.Value = ""
+	}
+	for _, existing := range resources.Content {
+		if path.Clean(existing.Value) == entry {
+			return file, false, nil
+		}
+	}
+	resources.Content = append(resources.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: entry})
+
+	updated, err := writeYaml(&node)
+	if err != nil {
+		return "", false, fmt.Errorf("updating parent kustomization %s failed: %v", file, err)
+	}
+	err = os.MkdirAll(parentDir, 0o755)
+	if err == nil {
+		err = os.WriteFile(file, updated, 0o644)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("updating parent kustomization %s failed: %v", file, err)
+	}
+	return file, true, nil
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateParentKustomization(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "vendors", "cert-manager")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+
+	file, updated, err := updateParentKustomization(dir, "..")
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, filepath.Join(root, "vendors", "kustomization.yaml"), file)
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n  - cert-manager\n", string(content))
+
+	assert.NoError(t, os.WriteFile(file, []byte("# vendored charts\nresources:\n  - ./ingress-nginx # keep\n  - cert-manager/\nnamespace: vendors\n"), 0o644))
+	_, updated, err = updateParentKustomization(dir, "..")
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	assert.NoError(t, os.WriteFile(file, []byte("# vendored charts\nresources:\n  - ./ingress-nginx # keep\nnamespace: vendors\n"), 0o644))
+	_, updated, err = updateParentKustomization(dir, "..")
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	content, err = os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "# vendored charts\nresources:\n  - ./ingress-nginx # keep\n  - cert-manager\nnamespace: vendors\n", string(content))
+
+	_, updated, err = updateParentKustomization(dir, "../..")
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	content, err = os.ReadFile(filepath.Join(root, "kustomization.yaml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "  - vendors/cert-manager\n")
+
+	for _, parent := range []string{".", "sub", "../other"} {
+		_, _, err = updateParentKustomization(dir, parent)
+		if assert.Error(t, err, parent) {
+			assert.Contains(t, err.Error(), "must be an ancestor")
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type PostPatch struct {
+	Target *ResourceSelector `yaml:"target"`
+	Patch  string            `yaml:"patch"`
+}
+
+type jsonPatchOperation struct {
+	Op    string    `yaml:"op"`
+	Path  string    `yaml:"path"`
+	From  string    `yaml:"from"`
+	Value yaml.Node `yaml:"value"`
+}
+
+type parsedPostPatch struct {
+	target     ResourceSelector
+	operations []jsonPatchOperation
+	merge      *yaml.Node
+}
+
+func applyPostPatches(result GeneratorResult, patches []PostPatch) (*GeneratorResult, error) {
+	parsed := []parsedPostPatch{}
+	for i, patch := range patches {
+		p, err := parsePostPatch(patch)
+		if err != nil {
+			return nil, configErrorf("parsing post patch %d failed: %v", i, err)
+		}
+		parsed = append(parsed, *p)
+	}
+	matches := make([]int, len(parsed))
+	patched, err := transformGeneratorResult(result, func(document *yaml.Node) (bool, error) {
+		resource := KubernetesResource{}
+		err := document.Decode(&resource)
+		if err != nil {
+			return false, err
+		}
+		changed := false
+		for i, patch := range parsed {
+			if !patch.target.Matches(resource) {
+				continue
+			}
+			matches[i]++
+			root := yamlDocumentRoot(document)
+			if patch.merge != nil {
+				*root = *mergeYamlNodes(root, patch.merge)
+			}
+			for _, operation := range patch.operations {
+				err := applyJsonPatchOperation(root, operation)
+				if err != nil {
+					return false, configErrorf("applying post patch %d failed: %s %s: %v", i, operation.Op, operation.Path, err)
+				}
+			}
+			changed = true
+		}
+		return changed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, count := range matches {
+		if count == 0 {
+			return nil, configErrorf("post patch %d does not match any resource", i)
+		}
+	}
+	return patched, nil
+}
+
+func parsePostPatch(patch PostPatch) (*parsedPostPatch, error) {
+	document := yaml.Node{}
+	err := yaml.Unmarshal([]byte(patch.Patch), &document)
+	if err != nil {
+		return nil, err
+	}
+	root := yamlDocumentRoot(&document)
+	switch root.Kind {
+	case yaml.SequenceNode:
+		if patch.Target == nil {
+			return nil, fmt.Errorf("json patches require a target")
+		}
+		operations := []jsonPatchOperation{}
+		err := root.Decode(&operations)
+		if err != nil {
+			return nil, err
+		}
+		for _, operation := range operations {
+			switch operation.Op {
+			case "add", "replace", "test":
+				if operation.Value.Kind == 0 {
+					return nil, fmt.Errorf("%s %s is missing a value", operation.Op, operation.Path)
+				}
+			case "move", "copy":
+				if operation.From == "" {
+					return nil, fmt.Errorf("%s %s is missing from", operation.Op, operation.Path)
+				}
+			case "remove":
+			default:
+				return nil, fmt.Errorf("unsupported operation %s", operation.Op)
+			}
+		}
+		return &parsedPostPatch{target: *patch.Target, operations: operations}, nil
+	case yaml.MappingNode:
+		target := ResourceSelector{}
+		if patch.Target != nil {
+			target = *patch.Target
+		} else {
+			resource := KubernetesResource{}
+			err := root.Decode(&resource)
+			if err != nil {
+				return nil, err
+			}
+			if !resource.NonEmpty() {
+				return nil, fmt.Errorf("strategic merge patches without target require apiVersion, kind and metadata.name")
+			}
+			target = ResourceSelector{ApiVersion: resource.ApiVersion, Kind: resource.Kind, Name: resource.Metadata.Name, Namespace: resource.Metadata.Namespace}
+		}
+		return &parsedPostPatch{target: target, merge: root}, nil
+	default:
+		return nil, fmt.Errorf("patch must be a list of json patch operations or a strategic merge patch")
+	}
+}
+
+var strategicMergeKeys = map[string][]string{
+	"containers":                {"name"},
+	"initContainers":            {"name"},
+	"ephemeralContainers":       {"name"},
+	"volumes":                   {"name"},
+	"env":                       {"name"},
+	"imagePullSecrets":          {"name"},
+	"volumeMounts":              {"mountPath"},
+	"volumeDevices":             {"devicePath"},
+	"ports":                     {"containerPort", "port"},
+	"hostAliases":               {"ip"},
+	"topologySpreadConstraints": {"topologyKey"},
+	"conditions":                {"type"},
+	"ownerReferences":           {"uid"},
+	"resourceClaims":            {"name"},
+}
+
+func mergeYamlNodes(dst *yaml.Node, src *yaml.Node) *yaml.Node {
+	return mergeYamlNodesAt("", dst, src)
+}
+
+func mergeYamlNodesAt(field string, dst *yaml.Node, src *yaml.Node) *yaml.Node {
+	if dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode {
+		result := copyYamlNode(dst)
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i].Value, src.Content[i+1]
+			if value.Tag == "!!null" {
+				yamlMappingRemove(result, key)
+				continue
+			}
+			existing := yamlMappingValue(result, key)
+			if existing == nil {
+				result.Content = append(result.Content, copyYamlNode(src.Content[i]), copyYamlNode(value))
+				continue
+			}
+			*existing = *mergeYamlNodesAt(key, existing, value)
+		}
+		return result
+	}
+	if mergeKey := strategicMergeKey(field, dst, src); mergeKey != "" {
+		result := copyYamlNode(dst)
+		for _, item := range src.Content {
+			key := yamlMappingValue(item, mergeKey).Value
+			merged := false
+			for j, existing := range result.Content {
+				if yamlMappingValue(existing, mergeKey).Value == key {
+					result.Content[j] = mergeYamlNodesAt("", existing, item)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				result.Content = append(result.Content, copyYamlNode(item))
+			}
+		}
+		return result
+	}
+	return copyYamlNode(src)
+}
+
+func strategicMergeKey(field string, dst *yaml.Node, src *yaml.Node) string {
+	if dst.Kind != yaml.SequenceNode || src.Kind != yaml.SequenceNode {
+		return ""
+	}
+	for _, key := range strategicMergeKeys[field] {
+		if yamlSequenceHasKey(dst, key) && yamlSequenceHasKey(src, key) {
+			return key
+		}
+	}
+	return ""
+}
+
+func yamlSequenceHasKey(node *yaml.Node, key string) bool {
+	for _, item := range node.Content {
+		value := yamlMappingValue(item, key)
+		if value == nil || value.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+func copyYamlNode(node *yaml.Node) *yaml.Node {
+	result := *node
+	result.Content = nil
+	for _, child := range node.Content {
+		result.Content = append(result.Content, copyYamlNode(child))
+	}
+	return &result
+}
+
+func applyJsonPatchOperation(root *yaml.Node, operation jsonPatchOperation) error {
+	switch operation.Op {
+	case "add":
+		return jsonPatchAdd(root, operation.Path, copyYamlNode(&operation.Value))
+	case "remove":
+		_, err := jsonPatchRemove(root, operation.Path)
+		return err
+	case "replace":
+		existing, err := jsonPatchGet(root, operation.Path)
+		if err != nil {
+			return err
+		}
+		*existing = *copyYamlNode(&operation.Value)
+		return nil
+	case "move":
+		value, err := jsonPatchRemove(root, operation.From)
+		if err != nil {
+			return err
+		}
+		return jsonPatchAdd(root, operation.Path, value)
+	case "copy":
+		value, err := jsonPatchGet(root, operation.From)
+		if err != nil {
+			return err
+		}
+		return jsonPatchAdd(root, operation.Path, copyYamlNode(value))
+	case "test":
+		value, err := jsonPatchGet(root, operation.Path)
+		if err != nil {
+			return err
+		}
+		var actual, expected interface{}
+		if err := value.Decode(&actual); err != nil {
+			return err
+		}
+		if err := operation.Value.Decode(&expected); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			return fmt.Errorf("test failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported operation %s", operation.Op)
+	}
+}
+
+func splitJsonPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %s", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func jsonPatchChild(node *yaml.Node, token string) (*yaml.Node, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		value := yamlMappingValue(node, token)
+		if value == nil {
+			return nil, fmt.Errorf("key %s does not exist", token)
+		}
+		return value, nil
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || index >= len(node.Content) {
+			return nil, fmt.Errorf("index %s is out of range", token)
+		}
+		return node.Content[index], nil
+	default:
+		return nil, fmt.Errorf("cannot traverse into scalar at %s", token)
+	}
+}
+
+func jsonPatchParent(root *yaml.Node, pointer string) (*yaml.Node, string, error) {
+	tokens, err := splitJsonPointer(pointer)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(tokens) == 0 {
+		return nil, "", fmt.Errorf("cannot modify the document root")
+	}
+	current := root
+	for _, token := range tokens[:len(tokens)-1] {
+		current, err = jsonPatchChild(current, token)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return current, tokens[len(tokens)-1], nil
+}
+
+func jsonPatchGet(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	tokens, err := splitJsonPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := root
+	for _, token := range tokens {
+		current, err = jsonPatchChild(current, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return current, nil
+}
+
+func jsonPatchAdd(root *yaml.Node, pointer string, value *yaml.Node) error {
+	parent, token, err := jsonPatchParent(root, pointer)
+	if err != nil {
+		return err
+	}
+	switch parent.Kind {
+	case yaml.MappingNode:
+		if existing := yamlMappingValue(parent, token); existing != nil {
+			*existing = *value
+			return nil
+		}
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: token}, value)
+		return nil
+	case yaml.SequenceNode:
+		if token == "-" {
+			parent.Content = append(parent.Content, value)
+			return nil
+		}
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || index > len(parent.Content) {
+			return fmt.Errorf("index %s is out of range", token)
+		}
+		parent.Content = append(parent.Content[:index], append([]*yaml.Node{value}, parent.Content[index:]...)...)
+		return nil
+	default:
+		return fmt.Errorf("cannot add to scalar")
+	}
+}
+
+func jsonPatchRemove(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	parent, token, err := jsonPatchParent(root, pointer)
+	if err != nil {
+		return nil, err
+	}
+	value, err := jsonPatchChild(parent, token)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Kind == yaml.MappingNode {
+		yamlMappingRemove(parent, token)
+	} else {
+		index, _ := strconv.Atoi(token)
+		parent.Content = append(parent.Content[:index], parent.Content[index+1:]...)
+	}
+	return value, nil
+}
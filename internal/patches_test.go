@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestApplyPostPatches(t *testing.T) {
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app:1.0.0
+        - name: sidecar
+          image: sidecar:1.0.0
+      nodeSelector:
+        disk: ssd
+`
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "apps/v1", Kind: "Deployment", File: "app-deployment.yaml", Content: deployment},
+			{ApiVersion: "v1", Kind: "Service", File: "app-service.yaml", Content: mockResource("Service", "app")},
+		},
+	}
+	patched, err := applyPostPatches(result, []PostPatch{
+		{
+			Target: &ResourceSelector{Kind: "Deployment", Name: "app"},
+			Patch: `- op: replace
+  path: /spec/replicas
+  value: 3
+- op: add
+  path: /spec/template/spec/tolerations
+  value:
+    - key: dedicated
+      operator: Exists
+- op: test
+  path: /spec/template/spec/containers/1/name
+  value: sidecar
+`,
+		},
+		{
+			Patch: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          resources:
+            limits:
+              memory: 256Mi
+      nodeSelector: null
+`,
+		},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app:1.0.0
+          resources:
+            limits:
+              memory: 256Mi
+        - name: sidecar
+          image: sidecar:1.0.0
+      tolerations:
+        - key: dedicated
+          operator: Exists
+`, patched.Resources[0].Content)
+		assert.Equal(t, result.Resources[1], patched.Resources[1])
+	}
+
+	_, err = applyPostPatches(result, []PostPatch{{Target: &ResourceSelector{Kind: "Deployment"}, Patch: "- op: test\n  path: /spec/replicas\n  value: 2\n"}})
+	if assert.Error(t, err) {
+		assert.Equal(t, 2, ExitCode(err))
+	}
+	_, err = applyPostPatches(result, []PostPatch{{Target: &ResourceSelector{Kind: "StatefulSet"}, Patch: "- op: remove\n  path: /spec/replicas\n"}})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "does not match any resource")
+	}
+	_, err = applyPostPatches(result, []PostPatch{{Patch: "- op: remove\n  path: /spec/replicas\n"}})
+	assert.Error(t, err)
+}
+
+func TestMergeYamlNodesUsesStrategicMergeKeys(t *testing.T) {
+	parse := func(content string) *yaml.Node {
+		document := yaml.Node{}
+		assert.NoError(t, yaml.Unmarshal([]byte(content), &document))
+		return yamlDocumentRoot(&document)
+	}
+	dst := parse(`containers:
+  - name: app
+    ports:
+      - containerPort: 80
+        name: http
+    volumeMounts:
+      - name: data
+        mountPath: /data
+      - name: data
+        mountPath: /cache
+        subPath: cache
+hostAliases:
+  - ip: 10.0.0.1
+    hostnames: [a]
+custom:
+  - name: a
+    value: 1
+`)
+	src := parse(`containers:
+  - name: app
+    ports:
+      - containerPort: 80
+        protocol: TCP
+    volumeMounts:
+      - name: data
+        mountPath: /cache
+        readOnly: true
+hostAliases:
+  - ip: 10.0.0.2
+    hostnames: [b]
+custom:
+  - name: b
+`)
+	merged, err := writeYaml(mergeYamlNodes(dst, src))
+	assert.NoError(t, err)
+	assert.Equal(t, `containers:
+  - name: app
+    ports:
+      - containerPort: 80
+        name: http
+        protocol: TCP
+    volumeMounts:
+      - name: data
+        mountPath: /data
+      - name: data
+        mountPath: /cache
+        subPath: cache
+        readOnly: true
+hostAliases:
+  - ip: 10.0.0.1
+    hostnames: [a]
+  - ip: 10.0.0.2
+    hostnames: [b]
+custom:
+  - name: b
+`, string(merged))
+}
+
+func TestApplyJsonPatchOperation(t *testing.T) {
+	root := yamlDocumentRoot(mustParseYamlNode(t, "a:\n  b: [1, 2]\n  c~d: x\n"))
+	assert.NoError(t, applyJsonPatchOperation(root, jsonPatchOperation{Op: "add", Path: "/a/b/-", Value: *yamlDocumentRoot(mustParseYamlNode(t, "3"))}))
+	assert.NoError(t, applyJsonPatchOperation(root, jsonPatchOperation{Op: "add", Path: "/a/b/0", Value: *yamlDocumentRoot(mustParseYamlNode(t, "0"))}))
+	assert.NoError(t, applyJsonPatchOperation(root, jsonPatchOperation{Op: "move", From: "/a/c~0d", Path: "/e"}))
+	assert.NoError(t, applyJsonPatchOperation(root, jsonPatchOperation{Op: "copy", From: "/e", Path: "/a/f"}))
+	assert.NoError(t, applyJsonPatchOperation(root, jsonPatchOperation{Op: "remove", Path: "/a/b/1"}))
+	assert.Error(t, applyJsonPatchOperation(root, jsonPatchOperation{Op: "remove", Path: "/a/missing"}))
+	assert.Error(t, applyJsonPatchOperation(root, jsonPatchOperation{Op: "add", Path: "/a/b/9", Value: *root}))
+	content, err := writeYaml(root)
+	assert.NoError(t, err)
+	assert.Equal(t, "a:\n  b: [0, 2, 3]\n  f: x\ne: x\n", string(content))
+}
+
+func mustParseYamlNode(t *testing.T, content string) *yaml.Node {
+	node := yaml.Node{}
+	assert.NoError(t, yaml.Unmarshal([]byte(content), &node))
+	return &node
+}
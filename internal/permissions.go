@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"io/fs"
+	"strconv"
+)
+
+const (
+	defaultFileMode fs.FileMode = 0o644
+	defaultDirMode  fs.FileMode = 0o755
+)
+
+type PermissionsConfig struct {
+	Files string `yaml:"files"`
+	Dirs  string `yaml:"dirs"`
+}
+
+type outputModes struct {
+	File fs.FileMode
+	Dir  fs.FileMode
+}
+
+func newOutputModes(config *PermissionsConfig) (outputModes, error) {
+	result := outputModes{}
+	if config == nil {
+		return result, nil
+	}
+	var err error
+	result.File, err = parseFileMode(config.Files)
+	if err != nil {
+		return result, configErrorf("invalid permissions files %s: %v", config.Files, err)
+	}
+	result.Dir, err = parseFileMode(config.Dirs)
+	if err != nil {
+		return result, configErrorf("invalid permissions dirs %s: %v", config.Dirs, err)
+	}
+	return result, nil
+}
+
+func parseFileMode(value string) (fs.FileMode, error) {
+	if value == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if mode > 0o777 {
+		return 0, configErrorf("mode must not exceed 0777")
+	}
+	return fs.FileMode(mode), nil
+}
+
+func (m outputModes) fileMode() fs.FileMode {
+	if m.File != 0 {
+		return m.File
+	}
+	return defaultFileMode
+}
+
+func (m outputModes) dirMode() fs.FileMode {
+	if m.Dir != 0 {
+		return m.Dir
+	}
+	return defaultDirMode
+}
+
+func enforceMode(fsys OutputFS, name string, mode fs.FileMode) error {
+	if mode == 0 {
+		return nil
+	}
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm() == mode {
+		return nil
+	}
+	return fsys.Chmod(name, mode)
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigPermissions(t *testing.T) {
+	config, err := LoadConfigFromReader(strings.NewReader("type: download\nurl: https://domain.com/manifest.yaml\npermissions:\n  files: \"0640\"\n  dirs: \"750\"\n"))
+	if assert.NoError(t, err) {
+		modes, err := newOutputModes(config.Permissions)
+		assert.NoError(t, err)
+		assert.Equal(t, outputModes{File: 0o640, Dir: 0o750}, modes)
+	}
+
+	_, err = LoadConfigFromReader(strings.NewReader("type: download\nurl: https://domain.com/manifest.yaml\npermissions:\n  files: \"0899\"\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid permissions files 0899")
+	}
+
+	_, err = LoadConfigFromReader(strings.NewReader("type: download\nurl: https://domain.com/manifest.yaml\npermissions:\n  dirs: \"1777\"\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid permissions dirs 1777")
+	}
+}
+
+func TestSyncFilesEnforcesModes(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "resources"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "resources", "unchanged.yaml"), []byte("a"), 0o600))
+	files := map[string][]byte{
+		"resources/unchanged.yaml": []byte("a"),
+		"resources/new.yaml":       []byte("b"),
+		"crds/new.yaml":            []byte("c"),
+	}
+	_, err := syncFiles(NewDirFS(dir), files, outputModes{File: 0o640, Dir: 0o750})
+	assert.NoError(t, err)
+	for _, name := range []string{"resources/unchanged.yaml", "resources/new.yaml", "crds/new.yaml"} {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		if assert.NoError(t, err) {
+			assert.Equal(t, fs.FileMode(0o640), info.Mode().Perm(), name)
+		}
+	}
+	for _, name := range []string{"resources", "crds"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if assert.NoError(t, err) {
+			assert.Equal(t, fs.FileMode(0o750), info.Mode().Perm(), name)
+		}
+	}
+}
+
+func TestSyncFilesKeepsModesByDefault(t *testing.T) {
+	fsys := NewMemoryFS()
+	assert.NoError(t, fsys.WriteFile("unchanged.yaml", []byte("a"), 0o600))
+	_, err := syncFiles(fsys, map[string][]byte{"unchanged.yaml": []byte("a"), "new.yaml": []byte("b")}, outputModes{})
+	assert.NoError(t, err)
+	info, err := fs.Stat(fsys, "unchanged.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o600), info.Mode().Perm())
+	info, err = fs.Stat(fsys, "new.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o644), info.Mode().Perm())
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type PolicyConfig struct {
+	Paths      []string `yaml:"paths"`
+	Namespaces []string `yaml:"namespaces"`
+	Data       []string `yaml:"data"`
+}
+
+type conftestOutput []struct {
+	Filename  string `json:"filename"`
+	Namespace string `json:"namespace"`
+	Warnings  []struct {
+		Msg string `json:"msg"`
+	} `json:"warnings"`
+	Failures []struct {
+		Msg string `json:"msg"`
+	} `json:"failures"`
+}
+
+func checkGeneratorResultPolicies(ctx GeneratorContext, result GeneratorResult, config PolicyConfig) error {
+	resources := result.AllResources()
+	if len(resources) == 0 {
+		return nil
+	}
+	conftestPath, err := exec.LookPath("conftest")
+	if err != nil {
+		return executionErrorf("executing conftest failed: executable not found")
+	}
+	conftestArgs := []string{
+		"test",
+		"--output", "json",
+		"--no-color",
+	}
+	for _, policyPath := range config.Paths {
+		conftestArgs = append(conftestArgs, "--policy", resolvePath(ctx.Dir, policyPath))
+	}
+	if len(config.Namespaces) > 0 {
+		for _, namespace := range config.Namespaces {
+			conftestArgs = append(conftestArgs, "--namespace", namespace)
+		}
+	} else {
+		conftestArgs = append(conftestArgs, "--all-namespaces")
+	}
+	for _, dataPath := range config.Data {
+		conftestArgs = append(conftestArgs, "--data", resolvePath(ctx.Dir, dataPath))
+	}
+	conftestArgs = append(conftestArgs, "-")
+
+	contents := []string{}
+	for _, resource := range resources {
+		contents = append(contents, resource.Content)
+	}
+	cmd := exec.Command(conftestPath, conftestArgs...)
+	cmd.Stdin = bytes.NewBufferString(strings.Join(contents, "---\n"))
+	conftestStdout, conftestStderr, err := ctx.runCommand(*cmd)
+
+	output := conftestOutput{}
+	if jsonErr := json.Unmarshal(conftestStdout, &output); jsonErr != nil {
+		if err != nil {
+			return executionErrorf("executing conftest failed: %v\n%s", err, string(conftestStderr))
+		}
+		return fmt.Errorf("parsing conftest output failed: %v", jsonErr)
+	}
+	messages := []string{}
+	findings := []ValidationFinding{}
+	for _, file := range output {
+		for _, warning := range file.Warnings {
+			ctx.log().Warn("policy warning", "namespace", file.Namespace, "message", warning.Msg)
+		}
+		for _, failure := range file.Failures {
+			messages = append(messages, fmt.Sprintf("%s: %s", file.Namespace, failure.Msg))
+			findings = append(findings, ValidationFinding{
+				Rule:    "policy/" + file.Namespace,
+				Message: failure.Msg,
+				File:    findingPath(ctx.Dir, configFile),
+				Line:    configKeyLine(ctx.Dir, "policies"),
+			})
+		}
+	}
+	ctx.Findings.record(findings...)
+	if len(messages) > 0 {
+		return validationErrorf("checking policies failed:\n%s", strings.Join(messages, "\n"))
+	}
+	if err != nil {
+		return executionErrorf("executing conftest failed: %v\n%s", err, string(conftestStderr))
+	}
+	return nil
+}
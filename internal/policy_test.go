@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckGeneratorResultPolicies(t *testing.T) {
+	testCases := []struct {
+		name      string
+		stdout    string
+		stderr    string
+		exitCode  int
+		err       string
+		exitClass int
+		findings  []string
+		warnings  []string
+	}{
+		{
+			name: "success",
+			stdout: `[
+	{
+		"filename": "",
+		"namespace": "main",
+		"successes": 2
+	}
+]`,
+		},
+		{
+			name: "failures",
+			stdout: `[
+	{
+		"filename": "",
+		"namespace": "main",
+		"successes": 0,
+		"failures": [
+			{
+				"msg": "Deployment app must not run as root"
+			},
+			{
+				"msg": "Deployment app is missing resource limits",
+				"metadata": {
+					"query": "data.main.deny"
+				}
+			}
+		]
+	},
+	{
+		"filename": "",
+		"namespace": "labels",
+		"successes": 0,
+		"failures": [
+			{
+				"msg": "ConfigMap app is missing label team"
+			}
+		]
+	}
+]`,
+			exitCode:  1,
+			err:       "checking policies failed:\nmain: Deployment app must not run as root\nmain: Deployment app is missing resource limits\nlabels: ConfigMap app is missing label team",
+			exitClass: 5,
+			findings:  []string{"policy/main: Deployment app must not run as root", "policy/main: Deployment app is missing resource limits", "policy/labels: ConfigMap app is missing label team"},
+		},
+		{
+			name: "warnings",
+			stdout: `[
+	{
+		"filename": "",
+		"namespace": "main",
+		"successes": 1,
+		"warnings": [
+			{
+				"msg": "Deployment app uses the latest tag"
+			}
+		]
+	}
+]`,
+			warnings: []string{"Deployment app uses the latest tag"},
+		},
+		{
+			name:      "invalid policy",
+			stderr:    "Error: running test: load: loading policies: get compiler: 1 error occurred: policy/deny.rego:3: rego_parse_error: unexpected eof token\n",
+			exitCode:  1,
+			err:       "executing conftest failed: exit status 1\nError: running test: load: loading policies",
+			exitClass: 4,
+		},
+		{
+			name:   "unexpected output",
+			stdout: "PASS - - main - 2 tests\n",
+			err:    "parsing conftest output failed",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			bin := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(bin, "stdout"), []byte(testCase.stdout), 0o644))
+			assert.NoError(t, os.WriteFile(filepath.Join(bin, "stderr"), []byte(testCase.stderr), 0o644))
+			assert.NoError(t, os.WriteFile(filepath.Join(bin, "conftest"), []byte("#!/bin/sh\ncat > /dev/null\ncat \""+bin+"/stdout\"\ncat \""+bin+"/stderr\" >&2\nexit "+strconv.Itoa(testCase.exitCode)+"\n"), 0o755))
+			t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			logs := strings.Builder{}
+			logger, _ := NewLogger(&logs, "text", true)
+			findings := NewValidationFindings()
+			ctx := GeneratorContext{Context: context.Background(), Logger: logger, Findings: findings}
+			result := GeneratorResult{Resources: []GeneratorResource{{File: "app-configmap.yaml", Content: mockResource("ConfigMap", "app")}}}
+			err := checkGeneratorResultPolicies(ctx, result, PolicyConfig{Paths: []string{"policy"}})
+			if testCase.err == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), testCase.err)
+				if testCase.exitClass != 0 {
+					assert.Equal(t, testCase.exitClass, ExitCode(err))
+				}
+			}
+			recorded := []string{}
+			for _, finding := range findings.Findings {
+				recorded = append(recorded, finding.Rule+": "+finding.Message)
+			}
+			assert.Equal(t, append([]string{}, testCase.findings...), recorded)
+			for _, warning := range testCase.warnings {
+				assert.Contains(t, logs.String(), "policy warning")
+				assert.Contains(t, logs.String(), warning)
+			}
+		})
+	}
+}
+
+func TestCheckGeneratorResultPoliciesArgs(t *testing.T) {
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "conftest"), []byte("#!/bin/sh\necho \"$@\" > \""+argsFile+"\"\ncat > /dev/null\necho '[]'\n"), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result := GeneratorResult{Resources: []GeneratorResource{{File: "app-configmap.yaml", Content: mockResource("ConfigMap", "app")}}}
+	assert.NoError(t, checkGeneratorResultPolicies(GeneratorContext{Context: context.Background()}, result, PolicyConfig{Paths: []string{"a", "b"}, Data: []string{"data"}}))
+	args, _ := os.ReadFile(argsFile)
+	assert.Equal(t, "test --output json --no-color --policy a --policy b --all-namespaces --data data -", strings.TrimSpace(string(args)))
+
+	assert.NoError(t, checkGeneratorResultPolicies(GeneratorContext{Context: context.Background()}, result, PolicyConfig{Paths: []string{"a"}, Namespaces: []string{"main", "labels"}}))
+	args, _ = os.ReadFile(argsFile)
+	assert.Equal(t, "test --output json --no-color --policy a --namespace main --namespace labels -", strings.TrimSpace(string(args)))
+
+	assert.NoError(t, checkGeneratorResultPolicies(GeneratorContext{Context: context.Background(), Dir: "/config"}, result, PolicyConfig{Paths: []string{"policy", "/policies"}, Data: []string{"data"}}))
+	args, _ = os.ReadFile(argsFile)
+	assert.Equal(t, "test --output json --no-color --policy /config/policy --policy /policies --all-namespaces --data /config/data -", strings.TrimSpace(string(args)))
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+type ProgressReporter struct {
+	w    io.Writer
+	live bool
+	mu   sync.Mutex
+}
+
+func NewProgressReporter(w io.Writer) *ProgressReporter {
+	live := false
+	if f, ok := w.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			live = true
+		}
+	}
+	return &ProgressReporter{w: w, live: live}
+}
+
+func (p *ProgressReporter) Start(dir string, phase string) {
+	if p == nil || !p.live {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, "\r\033[K%s: %s...", dir, phase)
+}
+
+func (p *ProgressReporter) Finish(dir string, phase string, duration time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.live {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+	fmt.Fprintf(p.w, "%s: %s done in %s\n", dir, phase, duration.Round(time.Millisecond))
+}
+
+func (ctx GeneratorContext) Phase(phase string) func() {
+	start := time.Now()
+	ctx.Progress.Start(ctx.Dir, phase)
+	return func() {
+		duration := time.Since(start)
+		ctx.Progress.Finish(ctx.Dir, phase, duration)
+		ctx.Metrics.recordPhase(ctx.Dir, phase, duration)
+		ctx.log().Debug("phase finished", "phase", phase, "duration", duration)
+	}
+}
@@ -0,0 +1,20 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratorContextPhase(t *testing.T) {
+	output := bytes.Buffer{}
+	ctx := GeneratorContext{Dir: "vendors/app", Progress: NewProgressReporter(&output)}
+	done := ctx.Phase("templating")
+	assert.Equal(t, "", output.String())
+	done()
+	assert.Regexp(t, `^vendors/app: templating done in \S+\n$`, output.String())
+
+	ctx = GeneratorContext{}
+	ctx.Phase("templating")()
+}
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type generationMetricKey struct {
+	Dir   string
+	Chart string
+}
+
+type generationMetric struct {
+	Successes   int
+	Failures    int
+	DurationSum float64
+	LastSuccess time.Time
+	LastFailed  bool
+}
+
+type GenerationMetrics struct {
+	mu      sync.Mutex
+	metrics map[generationMetricKey]*generationMetric
+}
+
+func NewGenerationMetrics() *GenerationMetrics {
+	return &GenerationMetrics{metrics: map[generationMetricKey]*generationMetric{}}
+}
+
+func (m *GenerationMetrics) record(dir string, charts []string, duration time.Duration, err error, now time.Time) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(charts) == 0 {
+		charts = []string{""}
+	}
+	for _, chart := range charts {
+		key := generationMetricKey{Dir: dir, Chart: chart}
+		metric, ok := m.metrics[key]
+		if !ok {
+			metric = &generationMetric{}
+			m.metrics[key] = metric
+		}
+		metric.DurationSum += duration.Seconds()
+		metric.LastFailed = err != nil
+		if err != nil {
+			metric.Failures++
+		} else {
+			metric.Successes++
+			metric.LastSuccess = now
+		}
+	}
+}
+
+func (m *GenerationMetrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := []generationMetricKey{}
+	for key := range m.metrics {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Dir != keys[j].Dir {
+			return keys[i].Dir < keys[j].Dir
+		}
+		return keys[i].Chart < keys[j].Chart
+	})
+	labels := func(key generationMetricKey) string {
+		return fmt.Sprintf(`dir="%s",chart="%s"`, escapePrometheusLabel(key.Dir), escapePrometheusLabel(key.Chart))
+	}
+
+	out := strings.Builder{}
+	out.WriteString("# HELP kustomization_generator_generations_total Number of generations per dir, chart and result.\n")
+	out.WriteString("# TYPE kustomization_generator_generations_total counter\n")
+	for _, key := range keys {
+		metric := m.metrics[key]
+		fmt.Fprintf(&out, "kustomization_generator_generations_total{%s,result=\"success\"} %d\n", labels(key), metric.Successes)
+		fmt.Fprintf(&out, "kustomization_generator_generations_total{%s,result=\"failure\"} %d\n", labels(key), metric.Failures)
+	}
+	out.WriteString("# HELP kustomization_generator_generation_duration_seconds Duration of generations per dir and chart.\n")
+	out.WriteString("# TYPE kustomization_generator_generation_duration_seconds summary\n")
+	for _, key := range keys {
+		metric := m.metrics[key]
+		fmt.Fprintf(&out, "kustomization_generator_generation_duration_seconds_sum{%s} %g\n", labels(key), metric.DurationSum)
+		fmt.Fprintf(&out, "kustomization_generator_generation_duration_seconds_count{%s} %d\n", labels(key), metric.Successes+metric.Failures)
+	}
+	out.WriteString("# HELP kustomization_generator_last_generation_success Whether the last generation per dir and chart succeeded.\n")
+	out.WriteString("# TYPE kustomization_generator_last_generation_success gauge\n")
+	for _, key := range keys {
+		success := 1
+		if m.metrics[key].LastFailed {
+			success = 0
+		}
+		fmt.Fprintf(&out, "kustomization_generator_last_generation_success{%s} %d\n", labels(key), success)
+	}
+	out.WriteString("# HELP kustomization_generator_last_success_timestamp_seconds Unix time of the last successful generation per dir and chart.\n")
+	out.WriteString("# TYPE kustomization_generator_last_success_timestamp_seconds gauge\n")
+	for _, key := range keys {
+		metric := m.metrics[key]
+		if metric.LastSuccess.IsZero() {
+			continue
+		}
+		fmt.Fprintf(&out, "kustomization_generator_last_success_timestamp_seconds{%s} %d\n", labels(key), metric.LastSuccess.Unix())
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func (m *GenerationMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/metrics" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.Write(w)
+}
+
+func escapePrometheusLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func configuredChartNames(dir string, opts RunOptions) []string {
+	config, err := loadRunConfig(dir, opts)
+	if err != nil {
+		return nil
+	}
+	charts := []string{}
+	seen := map[string]bool{}
+	for _, g := range collectHelmGenerators(config.Generator) {
+		chart := g.Chart
+		if chart == "" && strings.HasPrefix(g.Registry, "oci://") {
+			chart = path.Base(g.Registry)
+		}
+		if !seen[chart] {
+			seen[chart] = true
+			charts = append(charts, chart)
+		}
+	}
+	return charts
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerationMetrics(t *testing.T) {
+	metrics := NewGenerationMetrics()
+	now := time.Unix(1700000000, 0)
+	metrics.record("vendors/a", []string{"app"}, 2*time.Second, nil, now)
+	metrics.record("vendors/a", []string{"app"}, time.Second, errors.New("failed"), now.Add(time.Minute))
+	metrics.record("vendors/\"b\"", nil, 500*time.Millisecond, nil, now)
+
+	output := strings.Builder{}
+	assert.NoError(t, metrics.Write(&output))
+	assert.Equal(t, `# HELP kustomization_generator_generations_total Number of generations per dir, chart and result.
+# TYPE kustomization_generator_generations_total counter
+kustomization_generator_generations_total{dir="vendors/\"b\"",chart="",result="success"} 1
+kustomization_generator_generations_total{dir="vendors/\"b\"",chart="",result="failure"} 0
+kustomization_generator_generations_total{dir="vendors/a",chart="app",result="success"} 1
+kustomization_generator_generations_total{dir="vendors/a",chart="app",result="failure"} 1
+# HELP kustomization_generator_generation_duration_seconds Duration of generations per dir and chart.
+# TYPE kustomization_generator_generation_duration_seconds summary
+kustomization_generator_generation_duration_seconds_sum{dir="vendors/\"b\"",chart=""} 0.5
+kustomization_generator_generation_duration_seconds_count{dir="vendors/\"b\"",chart=""} 1
+kustomization_generator_generation_duration_seconds_sum{dir="vendors/a",chart="app"} 3
+kustomization_generator_generation_duration_seconds_count{dir="vendors/a",chart="app"} 2
+# HELP kustomization_generator_last_generation_success Whether the last generation per dir and chart succeeded.
+# TYPE kustomization_generator_last_generation_success gauge
+kustomization_generator_last_generation_success{dir="vendors/\"b\"",chart=""} 1
+kustomization_generator_last_generation_success{dir="vendors/a",chart="app"} 0
+# HELP kustomization_generator_last_success_timestamp_seconds Unix time of the last successful generation per dir and chart.
+# TYPE kustomization_generator_last_success_timestamp_seconds gauge
+kustomization_generator_last_success_timestamp_seconds{dir="vendors/\"b\"",chart=""} 1700000000
+kustomization_generator_last_success_timestamp_seconds{dir="vendors/a",chart="app"} 1700000000
+`, output.String())
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, output.String(), recorder.Body.String())
+	recorder = httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/other", nil))
+	assert.Equal(t, 404, recorder.Code)
+}
+
+func TestRecordGenerationMetricsIsNilSafe(t *testing.T) {
+	var metrics *GenerationMetrics
+	metrics.record("dir", []string{"app"}, time.Second, nil, time.Now())
+}
+
+func TestGenerationMetricsPerChart(t *testing.T) {
+	metrics := NewGenerationMetrics()
+	metrics.record("vendors/a", []string{"app", "redis"}, time.Second, nil, time.Unix(1700000000, 0))
+
+	output := strings.Builder{}
+	assert.NoError(t, metrics.Write(&output))
+	assert.Contains(t, output.String(), "kustomization_generator_last_generation_success{dir=\"vendors/a\",chart=\"app\"} 1\n")
+	assert.Contains(t, output.String(), "kustomization_generator_last_generation_success{dir=\"vendors/a\",chart=\"redis\"} 1\n")
+	assert.NotContains(t, output.String(), "app,redis")
+}
@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (g HelmGenerator) renderCacheKey(ctx GeneratorContext, helmPath string, chart resolvedHelmChart, valuesBytes []byte) string {
+	if g.Cluster != nil || g.PreserveChartFiles || g.Notes {
+		return ""
+	}
+	chartIdentity := chart.Source.Digest
+	if chartIdentity == "" && chart.LocalDir != "" {
+		digest, err := hashChartDir(chart.LocalDir)
+		if err != nil {
+			ctx.log().Debug("hashing chart failed", "dir", chart.LocalDir, "error", err)
+			return ""
+		}
+		chartIdentity = digest
+	}
+	if chartIdentity == "" && (chart.Source.Version == "" || isSemverConstraint(chart.Source.Version)) {
+		return ""
+	}
+	helmVersion, err := detectHelmVersion(ctx, helmPath)
+	if err != nil {
+		return ""
+	}
+	args := []string{}
+	for _, arg := range g.templateArgs(ctx, "values.yaml", chart.Args) {
+		if chart.LocalDir != "" {
+			arg = strings.ReplaceAll(arg, chart.LocalDir, "chart")
+		}
+		args = append(args, arg)
+	}
+	input, err := json.Marshal(struct {
+		Helm     semver
+		Chart    string
+		Source   GeneratorSource
+		Args     []string
+		Values   string
+		SetFiles map[string]interface{}
+		Release  *HelmReleaseConfig
+	}{*helmVersion, chartIdentity, chart.Source, args, string(valuesBytes), readSetFiles(absolutizePaths(ctx.Dir, g.SetFile)), g.Release})
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(input)
+	return hex.EncodeToString(hash[:])
+}
+
+func hashChartDir(dir string) (string, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(name)] = content
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hashOutputFiles(files, nil), nil
+}
+
+func renderCacheFile(cacheDir string, key string) string {
+	return filepath.Join(cacheDir, "renders", key+".yaml.gz")
+}
+
+func loadRenderCache(ctx GeneratorContext, key string) ([]byte, bool) {
+	if key == "" {
+		return nil, false
+	}
+	cacheDir, err := ctx.cacheDir()
+	if err != nil {
+		return nil, false
+	}
+	file, err := os.Open(renderCacheFile(cacheDir, key))
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, false
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false
+	}
+	ctx.log().Info("render restored from cache", "key", key)
+	return content, true
+}
+
+func storeRenderCache(ctx GeneratorContext, key string, content []byte) {
+	if key == "" {
+		return
+	}
+	cacheDir, err := ctx.cacheDir()
+	if err != nil {
+		return
+	}
+	file := renderCacheFile(cacheDir, key)
+	compressed := bytes.Buffer{}
+	writer := gzip.NewWriter(&compressed)
+	_, err = writer.Write(content)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(file), 0o755)
+	}
+	if err == nil {
+		err = writeFileAtomic(file, compressed.Bytes())
+	}
+	if err != nil {
+		ctx.log().Debug("caching render failed", "key", key, "error", err)
+	}
+}
+
+func writeFileAtomic(file string, content []byte) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(file), ".render-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	_, err = tempFile.Write(content)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tempFile.Name(), 0o644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), file)
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelmGeneratorRenderCache(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	helm := fakeHelm(t, `echo "$1" >> `+calls+`
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --values) values="$2"; shift ;;
+  esac
+  shift
+done
+printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n%s\n' "$(sed 's/^/  /' "$values")"
+`)
+	chartDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: app\nversion: 1.2.3\n"), 0o644))
+	ctx := GeneratorContext{Context: context.Background(), HelmBin: helm, CacheDir: t.TempDir(), RenderCache: true}
+	templateCalls := func() int {
+		content, _ := os.ReadFile(calls)
+		return strings.Count(string(content), "template")
+	}
+	render := func(g HelmGenerator) string {
+		result, err := g.Generate(ctx)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		return result.Resources[0].Content
+	}
+
+	g := HelmGenerator{Chart: chartDir, Name: "app", Values: map[string]interface{}{"replicas": 1}}
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  replicas: 1\n", render(g))
+	assert.Equal(t, 1, templateCalls())
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  replicas: 1\n", render(g))
+	assert.Equal(t, 1, templateCalls())
+
+	g.Values = map[string]interface{}{"replicas": 2}
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  replicas: 2\n", render(g))
+	assert.Equal(t, 2, templateCalls())
+
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "values.yaml"), []byte("replicas: 0\n"), 0o644))
+	render(g)
+	assert.Equal(t, 3, templateCalls())
+
+	setFile := filepath.Join(t.TempDir(), "config.txt")
+	assert.NoError(t, os.WriteFile(setFile, []byte("a"), 0o644))
+	g.SetFile = map[string]interface{}{"config": setFile}
+	render(g)
+	assert.Equal(t, 4, templateCalls())
+	render(g)
+	assert.Equal(t, 4, templateCalls())
+	assert.NoError(t, os.WriteFile(setFile, []byte("b"), 0o644))
+	render(g)
+	assert.Equal(t, 5, templateCalls())
+
+	ctx.RenderCache = false
+	render(g)
+	assert.Equal(t, 6, templateCalls())
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type UsageReport struct {
+	Charts []ChartUsage `yaml:"charts"`
+}
+
+type ChartUsage struct {
+	Chart    string   `yaml:"chart"`
+	Registry string   `yaml:"registry,omitempty"`
+	Version  string   `yaml:"version"`
+	Dirs     []string `yaml:"dirs"`
+}
+
+type chartUsageKey struct {
+	Chart    string
+	Registry string
+	Version  string
+}
+
+func BuildUsageReport(root string) (*UsageReport, error) {
+	dirs, err := FindConfigDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	usages := map[chartUsageKey][]string{}
+	for _, dir := range dirs {
+		file := filepath.Join(dir, configFile)
+		config, err := LoadConfig(file)
+		if err != nil {
+			return nil, ClassifiedError{Class: ErrorClassConfig, File: file, Err: err}
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		registries := map[string]string{}
+		configured := []chartUsageKey{}
+		for _, g := range collectHelmGenerators(config.Generator) {
+			chart := g.Chart
+			if chart == "" && strings.HasPrefix(g.Registry, "oci://") {
+				chart = path.Base(g.Registry)
+			}
+			registries[chart] = g.Registry
+			configured = append(configured, chartUsageKey{Chart: chart, Registry: g.Registry, Version: g.Version})
+		}
+		sources, err := readMetadataSources(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(sources) == 0 {
+			for _, usage := range configured {
+				usages[usage] = append(usages[usage], rel)
+			}
+			continue
+		}
+		for _, source := range sources {
+			if source.Chart == "" {
+				continue
+			}
+			usage := chartUsageKey{Chart: source.Chart, Registry: registries[source.Chart], Version: source.Version}
+			usages[usage] = append(usages[usage], path.Join(rel, source.Dir))
+		}
+	}
+
+	report := UsageReport{Charts: []ChartUsage{}}
+	for usage, dirs := range usages {
+		sort.Strings(dirs)
+		report.Charts = append(report.Charts, ChartUsage{Chart: usage.Chart, Registry: usage.Registry, Version: usage.Version, Dirs: dirs})
+	}
+	sort.Slice(report.Charts, func(i, j int) bool {
+		a, b := report.Charts[i], report.Charts[j]
+		if a.Chart != b.Chart {
+			return a.Chart < b.Chart
+		}
+		if a.Registry != b.Registry {
+			return a.Registry < b.Registry
+		}
+		return a.Version < b.Version
+	})
+	return &report, nil
+}
+
+func readMetadataSources(dir string) ([]MetadataSource, error) {
+	file := filepath.Join(dir, metadataFile)
+	bytes, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	report := MetadataReport{}
+	err = readYaml(bytes, &report)
+	if err != nil {
+		return nil, ClassifiedError{Class: ErrorClassValidation, File: file, Err: err}
+	}
+	return report.Sources, nil
+}
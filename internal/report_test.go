@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildUsageReport(t *testing.T) {
+	root := t.TempDir()
+	write := func(file string, content string) {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, file)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(root, file), []byte(content), 0o644))
+	}
+	write("prod/cert-manager/"+configFile, "type: helm\nregistry: https://charts.jetstack.io\nchart: cert-manager\nversion: ^1.12.0\n")
+	write("prod/cert-manager/"+metadataFile, "type: helm\nsources:\n  - dir: .\n    chart: cert-manager\n    version: 1.12.3\n")
+	write("staging/cert-manager/"+configFile, "type: helm\nregistry: https://charts.jetstack.io\nchart: cert-manager\nversion: 1.12.3\n")
+	write("staging/apps/"+configFile, `type: multi
+generators:
+  - type: helm
+    name: redis
+    registry: oci://registry-1.docker.io/bitnamicharts/redis
+    version: 18.0.0
+  - type: download
+    url: https://example.com/manifest.yaml
+`)
+
+	report, err := BuildUsageReport(root)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []ChartUsage{
+			{Chart: "cert-manager", Registry: "https://charts.jetstack.io", Version: "1.12.3", Dirs: []string{"prod/cert-manager", "staging/cert-manager"}},
+			{Chart: "redis", Registry: "oci://registry-1.docker.io/bitnamicharts/redis", Version: "18.0.0", Dirs: []string{"staging/apps"}},
+		}, report.Charts)
+	}
+}
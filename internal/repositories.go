@@ -0,0 +1,259 @@
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const repositoriesFile = "repositories.yaml"
+
+type RepositoriesConfig struct {
+	Repositories map[string]Repository `yaml:"repositories"`
+}
+
+type Repository struct {
+	Url      string `yaml:"url"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Type     string `yaml:"type"`
+	AuthEnv  string `yaml:"authEnv"`
+}
+
+func LoadRepositories(file string) (*RepositoriesConfig, error) {
+	bytes, err := readConfigFile(file)
+	if err != nil {
+		return nil, err
+	}
+	result := RepositoriesConfig{}
+	err = readYaml(bytes, &result)
+	if err != nil {
+		return nil, err
+	}
+	for name, repository := range result.Repositories {
+		if repository.Url == "" {
+			return nil, configErrorf("repository %s is missing an url", name)
+		}
+		if repository.Type != "" && repository.Type != "chartmuseum" {
+			return nil, configErrorf("repository %s has unsupported type %s", name, repository.Type)
+		}
+		if strings.Contains(name, "://") {
+			return nil, configErrorf("repository name %s must not contain a scheme", name)
+		}
+	}
+	return &result, nil
+}
+
+func findRepositoriesFile(dir string) (string, error) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		file := filepath.Join(current, repositoriesFile)
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", nil
+		}
+		current = parent
+	}
+}
+
+func loadRunRepositories(dir string, opts RunOptions) (map[string]Repository, error) {
+	file := opts.RepositoriesFile
+	if file == "" {
+		found, err := findRepositoriesFile(dir)
+		if err != nil {
+			return nil, err
+		}
+		file = found
+	}
+	if file == "" {
+		return nil, nil
+	}
+	repositories, err := LoadRepositories(file)
+	if err != nil {
+		return nil, ClassifiedError{Class: ErrorClassConfig, File: file, Err: fmt.Errorf("unable to load repositories: %v", err)}
+	}
+	return repositories.Repositories, nil
+}
+
+func (ctx GeneratorContext) resolveRepository(registry string) (*Repository, error) {
+	if registry == "" || strings.Contains(registry, "://") {
+		repository := applyNetrcCredentials(Repository{Url: registry}, netrcFile())
+		return &repository, nil
+	}
+	repository, ok := ctx.Repositories[registry]
+	if !ok {
+		return nil, configErrorf("registry %s is not a known repository", registry)
+	}
+	repository = applyNetrcCredentials(repository, netrcFile())
+	return &repository, nil
+}
+
+func (r Repository) hasCredentials() bool {
+	return r.Username != "" || r.Password != ""
+}
+
+func (r Repository) registryConfigArgs(ctx GeneratorContext) ([]string, func(), error) {
+	if !r.hasCredentials() && r.AuthEnv == "" {
+		return nil, func() {}, nil
+	}
+	dir, err := os.MkdirTemp(ctx.TempDir, ".kustomization-generator-*-registry")
+	if err != nil {
+		return nil, nil, fmt.Errorf("writing registry config failed: %v", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	file, err := writeRegistryConfig(dir, []Repository{r})
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return []string{"--registry-config", file}, cleanup, nil
+}
+
+func writeRegistryConfig(dir string, repositories []Repository) (string, error) {
+	auths := map[string]interface{}{}
+	for _, r := range repositories {
+		host := strings.SplitN(strings.TrimPrefix(r.Url, "oci://"), "/", 2)[0]
+		if r.AuthEnv != "" {
+			auths[host] = map[string]string{"registrytoken": os.Getenv(r.AuthEnv)}
+			continue
+		}
+		auths[host] = map[string]string{"auth": base64.StdEncoding.EncodeToString([]byte(r.Username + ":" + r.Password))}
+	}
+	content, err := json.Marshal(map[string]interface{}{"auths": auths})
+	if err != nil {
+		return "", fmt.Errorf("writing registry config failed: %v", err)
+	}
+	file := filepath.Join(dir, "config.json")
+	err = os.WriteFile(file, content, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("writing registry config failed: %v", err)
+	}
+	return file, nil
+}
+
+func (ctx GeneratorContext) resolveDependencyRepository(registry string) (*Repository, error) {
+	for _, repository := range ctx.Repositories {
+		if strings.TrimSuffix(repository.Url, "/") == strings.TrimSuffix(registry, "/") {
+			repository = applyNetrcCredentials(repository, netrcFile())
+			return &repository, nil
+		}
+	}
+	return ctx.resolveRepository(registry)
+}
+
+type helmRepositoryEntry struct {
+	Name     string `yaml:"name"`
+	Url      string `yaml:"url"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	CaFile   string `yaml:"caFile,omitempty"`
+}
+
+func (ctx GeneratorContext) dependencyBuildArgs(chart helmLocalChart) ([]string, func(), error) {
+	entries := []helmRepositoryEntry{}
+	registries := []Repository{}
+	for i, dependency := range chart.Dependencies {
+		name := ""
+		registry := dependency.Repository
+		if strings.HasPrefix(registry, "@") || strings.HasPrefix(registry, "alias:") {
+			name = strings.TrimPrefix(strings.TrimPrefix(registry, "@"), "alias:")
+			registry = name
+		}
+		if registry == "" || strings.HasPrefix(registry, "file://") {
+			continue
+		}
+		repository, err := ctx.resolveDependencyRepository(registry)
+		if err != nil {
+			return nil, nil, fmt.Errorf("dependency %s: %w", dependency.Name, err)
+		}
+		if repository.AuthEnv != "" {
+			return nil, nil, configErrorf("dependency %s: authEnv is not supported for chart dependencies", dependency.Name)
+		}
+		if strings.HasPrefix(repository.Url, "oci://") {
+			if repository.hasCredentials() {
+				registries = append(registries, *repository)
+			}
+			continue
+		}
+		if name == "" {
+			name = fmt.Sprintf("dependency-%d", i)
+		}
+		entries = append(entries, helmRepositoryEntry{Name: name, Url: repository.Url, Username: repository.Username, Password: repository.Password, CaFile: ctx.CaBundle})
+	}
+	if len(entries) == 0 && len(registries) == 0 {
+		return nil, func() {}, nil
+	}
+	dir, err := os.MkdirTemp(ctx.TempDir, ".kustomization-generator-*-repositories")
+	if err != nil {
+		return nil, nil, fmt.Errorf("writing repository config failed: %v", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	args := []string{}
+	if len(entries) > 0 {
+		content, err := writeYaml(map[string]interface{}{"apiVersion": "", "repositories": entries})
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, repositoriesFile), content, 0o600)
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("writing repository config failed: %v", err)
+		}
+		args = append(args, "--repository-config", filepath.Join(dir, repositoriesFile), "--repository-cache", filepath.Join(dir, "cache"))
+	}
+	if len(registries) > 0 {
+		file, err := writeRegistryConfig(dir, registries)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		args = append(args, "--registry-config", file)
+	}
+	return args, cleanup, nil
+}
+
+func (ctx GeneratorContext) repositoryGet(repository Repository, rawUrl string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx.context(), "GET", rawUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if repository.isSameHost(req.URL) {
+		if repository.AuthEnv != "" {
+			req.Header.Set("Authorization", "Bearer "+os.Getenv(repository.AuthEnv))
+		} else if repository.Username != "" || repository.Password != "" {
+			req.SetBasicAuth(repository.Username, repository.Password)
+		}
+	}
+	return ctx.httpDo(req)
+}
+
+func (r Repository) isSameHost(u *url.URL) bool {
+	repositoryUrl, err := url.Parse(r.Url)
+	if err != nil {
+		return false
+	}
+	return repositoryUrl.Host == u.Host
+}
+
+func (r Repository) checkAuthEnv() error {
+	if r.AuthEnv == "" {
+		return nil
+	}
+	if os.Getenv(r.AuthEnv) == "" {
+		return configErrorf("environment variable %s for registry %s is not set", r.AuthEnv, r.Url)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRunRepositories(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "vendors", "app")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0o755))
+
+	repositories, err := loadRunRepositories(dir, RunOptions{})
+	assert.NoError(t, err)
+	assert.Nil(t, repositories)
+
+	t.Setenv("BITNAMI_PASSWORD", "secret")
+	assert.NoError(t, os.WriteFile(filepath.Join(root, repositoriesFile), []byte("repositories:\n  bitnami:\n    url: https://charts.bitnami.com/bitnami\n    username: user\n    password: ${BITNAMI_PASSWORD}\n"), 0o644))
+	repositories, err = loadRunRepositories(dir, RunOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Repository{"bitnami": {Url: "https://charts.bitnami.com/bitnami", Username: "user", Password: "secret"}}, repositories)
+
+	other := filepath.Join(root, "other.yaml")
+	assert.NoError(t, os.WriteFile(other, []byte("repositories:\n  broken: {}\n"), 0o644))
+	_, err = loadRunRepositories(dir, RunOptions{RepositoriesFile: other})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "repository broken is missing an url")
+		assert.Equal(t, 2, ExitCode(err))
+	}
+}
+
+func TestResolveRepository(t *testing.T) {
+	ctx := GeneratorContext{Repositories: map[string]Repository{"bitnami": {Url: "https://charts.bitnami.com/bitnami"}}}
+
+	repository, err := ctx.resolveRepository("bitnami")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://charts.bitnami.com/bitnami", repository.Url)
+
+	repository, err = ctx.resolveRepository("oci://ghcr.io/org/chart")
+	assert.NoError(t, err)
+	assert.Equal(t, "oci://ghcr.io/org/chart", repository.Url)
+
+	repository, err = ctx.resolveRepository("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", repository.Url)
+
+	_, err = ctx.resolveRepository("unknown")
+	assert.Error(t, err)
+}
+
+func TestRetrieveHelmChartArchiveWithCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("entries:\n  app:\n    - version: 1.0.0\n      urls: [app-1.0.0.tgz]\n"))
+	}))
+	defer server.Close()
+
+	entry, urls, err := retrieveHelmChartArchive(GeneratorContext{}, Repository{Url: server.URL, Username: "user", Password: "secret"}, "app", "1.0.0", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", entry.Version)
+	assert.Equal(t, []string{server.URL + "/app-1.0.0.tgz"}, urls)
+}
+
+func TestRepositoryGetBearerToken(t *testing.T) {
+	t.Setenv("KUSTOMIZATION_GENERATOR_TEST_TOKEN", "token")
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "", r.Header.Get("Authorization"))
+	}))
+	defer other.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	repository := Repository{Url: server.URL, AuthEnv: "KUSTOMIZATION_GENERATOR_TEST_TOKEN"}
+	assert.NoError(t, repository.checkAuthEnv())
+	resp, err := GeneratorContext{}.repositoryGet(repository, server.URL+"/index.yaml")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+	resp, err = GeneratorContext{}.repositoryGet(repository, other.URL+"/app-1.0.0.tgz")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+	}
+
+	err = Repository{Url: server.URL, AuthEnv: "KUSTOMIZATION_GENERATOR_TEST_MISSING"}.checkAuthEnv()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "KUSTOMIZATION_GENERATOR_TEST_MISSING")
+	}
+}
+
+func TestRepositoryRegistryConfigArgs(t *testing.T) {
+	args, cleanup, err := Repository{Url: "oci://ghcr.io/org/charts/app"}.registryConfigArgs(GeneratorContext{})
+	assert.NoError(t, err)
+	assert.Empty(t, args)
+	cleanup()
+
+	args, cleanup, err = Repository{Url: "oci://ghcr.io/org/charts/app", Username: "user", Password: "secret"}.registryConfigArgs(GeneratorContext{TempDir: t.TempDir()})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, args, 2) {
+		assert.Equal(t, "--registry-config", args[0])
+		assert.NotContains(t, args, "secret")
+		info, err := os.Stat(args[1])
+		if assert.NoError(t, err) {
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		}
+		content, err := os.ReadFile(args[1])
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"auths":{"ghcr.io":{"auth":"dXNlcjpzZWNyZXQ="}}}`, string(content))
+		cleanup()
+		assert.NoFileExists(t, args[1])
+	}
+
+	t.Setenv("KUSTOMIZATION_GENERATOR_TEST_TOKEN", "token")
+	args, cleanup, err = Repository{Url: "oci://ghcr.io/org/charts/app", AuthEnv: "KUSTOMIZATION_GENERATOR_TEST_TOKEN"}.registryConfigArgs(GeneratorContext{TempDir: t.TempDir()})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, args, 2) {
+		content, err := os.ReadFile(args[1])
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"auths":{"ghcr.io":{"registrytoken":"token"}}}`, string(content))
+		cleanup()
+	}
+}
+
+func TestDependencyBuildArgs(t *testing.T) {
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "missing"))
+	ctx := GeneratorContext{TempDir: t.TempDir(), Repositories: map[string]Repository{
+		"private": {Url: "https://charts.example.com", Username: "user", Password: "secret"},
+		"ghcr":    {Url: "oci://ghcr.io/org/charts", Username: "user", Password: "token"},
+	}}
+	chart := helmLocalChart{}
+	assert.NoError(t, readYaml([]byte(`dependencies:
+- name: local
+  repository: file://../local
+- name: app
+  repository: "@private"
+- name: public
+  repository: https://charts.bitnami.com/bitnami
+- name: oci
+  repository: oci://ghcr.io/org/charts
+`), &chart))
+	args, cleanup, err := ctx.dependencyBuildArgs(chart)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer cleanup()
+	if assert.Len(t, args, 6) {
+		assert.Equal(t, "--repository-config", args[0])
+		assert.Equal(t, "--repository-cache", args[2])
+		assert.Equal(t, "--registry-config", args[4])
+		assert.NotContains(t, args, "secret")
+		content, err := os.ReadFile(args[1])
+		assert.NoError(t, err)
+		assert.Equal(t, `apiVersion: ""
+repositories:
+  - name: private
+    url: https://charts.example.com
+    username: user
+    password: secret
+  - name: dependency-2
+    url: https://charts.bitnami.com/bitnami
+`, string(content))
+		content, err = os.ReadFile(args[5])
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"auths":{"ghcr.io":{"auth":"dXNlcjp0b2tlbg=="}}}`, string(content))
+	}
+
+	unknown := helmLocalChart{}
+	assert.NoError(t, readYaml([]byte("dependencies:\n- name: app\n  repository: \"@unknown\"\n"), &unknown))
+	_, _, err = ctx.dependencyBuildArgs(unknown)
+	if assert.Error(t, err) {
+		assert.Equal(t, 2, ExitCode(err))
+		assert.Contains(t, err.Error(), "dependency app")
+	}
+}
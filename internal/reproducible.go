@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func generationTime() time.Time {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		if seconds, err := strconv.ParseInt(epoch, 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+	}
+	return time.Now()
+}
+
+func pinReproducible(ctx GeneratorContext, generator Generator, first GeneratorResult) (*GeneratorResult, error) {
+	done := ctx.Phase("reproducibility check")
+	defer done()
+	second, err := generator.Generate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := diffGeneratorResults(first, *second)
+	if err != nil {
+		return nil, err
+	}
+	if len(diffs) == 0 {
+		return &first, nil
+	}
+	structural := []string{}
+	for _, diff := range diffs {
+		if diff.Change != "changed" {
+			structural = append(structural, diff.Resource)
+		}
+	}
+	if len(structural) > 0 {
+		return nil, validationErrorf("output is not reproducible, these resources are only rendered sometimes: %s", strings.Join(structural, ", "))
+	}
+	secondContents, err := indexResourceContents(*second)
+	if err != nil {
+		return nil, err
+	}
+	committed, err := loadCommittedResources(ctx.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return pinGeneratorResult(ctx, ".", first, secondContents, committed)
+}
+
+func pinGeneratorResult(ctx GeneratorContext, dir string, result GeneratorResult, second map[string]string, committed map[string]*yaml.Node) (*GeneratorResult, error) {
+	pinned := result
+	pinned.Resources = nil
+	pinned.Children = nil
+	for _, resource := range result.Resources {
+		id, err := identifyResource(resource)
+		if err != nil {
+			return nil, fmt.Errorf("reading resource %s failed: %v", resource.File, err)
+		}
+		name := id.String()
+		if dir != "." {
+			name = dir + ": " + name
+		}
+		if second[name] != resource.Content {
+			resource, err = pinResource(ctx, name, resource, second[name], committed[id.String()])
+			if err != nil {
+				return nil, err
+			}
+		}
+		pinned.Resources = append(pinned.Resources, resource)
+	}
+	for _, child := range result.Children {
+		childResult, err := pinGeneratorResult(ctx, path.Join(dir, child.Dir), child.Result, second, committed)
+		if err != nil {
+			return nil, err
+		}
+		child.Result = *childResult
+		pinned.Children = append(pinned.Children, child)
+	}
+	return &pinned, nil
+}
+
+func pinResource(ctx GeneratorContext, name string, resource GeneratorResource, second string, committed *yaml.Node) (GeneratorResource, error) {
+	if committed == nil {
+		ctx.log().Warn("resource is not reproducible and has no committed version to pin it to", "resource", name)
+		return resource, nil
+	}
+	firstDocument := yaml.Node{}
+	secondDocument := yaml.Node{}
+	err := yaml.Unmarshal([]byte(resource.Content), &firstDocument)
+	if err == nil {
+		err = yaml.Unmarshal([]byte(second), &secondDocument)
+	}
+	if err != nil {
+		return resource, fmt.Errorf("pinning resource %s failed: %v", name, err)
+	}
+	changed, unpinned := pinYamlNode(yamlDocumentRoot(&firstDocument), yamlDocumentRoot(&secondDocument), committed)
+	if unpinned {
+		ctx.log().Warn("resource has values that are not reproducible and missing in the committed version", "resource", name)
+	}
+	if !changed {
+		return resource, nil
+	}
+	content, err := writeYaml(&firstDocument)
+	if err != nil {
+		return resource, fmt.Errorf("pinning resource %s failed: %v", name, err)
+	}
+	ctx.log().Info("pinned values that are not reproducible to the committed version", "resource", name)
+	resource.Content = string(content)
+	return resource, nil
+}
+
+func pinYamlNode(first *yaml.Node, second *yaml.Node, committed *yaml.Node) (bool, bool) {
+	if second != nil && yamlNodesEqual(first, second) {
+		return false, false
+	}
+	if committed == nil || second == nil {
+		return false, true
+	}
+	changed, unpinned := false, false
+	switch {
+	case first.Kind == yaml.MappingNode && second.Kind == yaml.MappingNode && committed.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(first.Content); i += 2 {
+			key := first.Content[i].Value
+			c, u := pinYamlNode(first.Content[i+1], yamlMappingValue(second, key), yamlMappingValue(committed, key))
+			changed, unpinned = changed || c, unpinned || u
+		}
+	case first.Kind == yaml.SequenceNode && second.Kind == yaml.SequenceNode && committed.Kind == yaml.SequenceNode &&
+		len(first.Content) == len(second.Content) && len(first.Content) == len(committed.Content):
+		for i := range first.Content {
+			c, u := pinYamlNode(first.Content[i], second.Content[i], committed.Content[i])
+			changed, unpinned = changed || c, unpinned || u
+		}
+	default:
+		*first = *committed
+		changed = true
+	}
+	return changed, unpinned
+}
+
+func yamlNodesEqual(a *yaml.Node, b *yaml.Node) bool {
+	if a.Kind != b.Kind || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !yamlNodesEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func loadCommittedResources(dir string) (map[string]*yaml.Node, error) {
+	result := map[string]*yaml.Node{}
+	if dir == "" {
+		return result, nil
+	}
+	fsys := os.DirFS(dir)
+	files, _, err := listOutputFiles(fsys, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading committed resources failed: %v", err)
+	}
+	ambiguous := map[string]bool{}
+	for _, name := range files {
+		if (!strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".json")) || path.Base(name) == "kustomization.yaml" || name == metadataFile {
+			continue
+		}
+		bytes, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("reading committed resources failed: %v", err)
+		}
+		id, err := identifyResource(GeneratorResource{Content: string(bytes)})
+		if err != nil || id.Kind == "" {
+			continue
+		}
+		document := yaml.Node{}
+		if yaml.Unmarshal(bytes, &document) != nil {
+			continue
+		}
+		root := yamlDocumentRoot(&document)
+		if yamlMappingValue(root, "sops") != nil {
+			continue
+		}
+		key := id.String()
+		if _, exists := result[key]; exists {
+			ambiguous[key] = true
+		}
+		result[key] = root
+	}
+	for key := range ambiguous {
+		delete(result, key)
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingGenerator struct {
+	calls *int
+}
+
+func (g countingGenerator) Generate(ctx GeneratorContext) (*GeneratorResult, error) {
+	*g.calls++
+	return &GeneratorResult{Resources: []GeneratorResource{{
+		File:    "secret.yaml",
+		Content: mockResource("Secret", "app") + fmt.Sprintf("data:\n  password: %d\n", *g.calls),
+	}}}, nil
+}
+
+func TestGenerationTime(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	assert.Equal(t, time.Unix(1700000000, 0), generationTime())
+}
+
+func TestPinReproducible(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+	generator := countingGenerator{calls: &calls}
+	first, err := generator.Generate(GeneratorContext{})
+	assert.NoError(t, err)
+	pinned, err := pinReproducible(GeneratorContext{Dir: dir}, generator, *first)
+	if assert.NoError(t, err) {
+		assert.Equal(t, *first, *pinned)
+	}
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "resources"), 0o755))
+	committed := generationHeader + mockResource("Secret", "app") + "  labels:\n    app: committed\ndata:\n  password: \"7\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "resources", "app-secret.yaml"), []byte(committed), 0o644))
+	first, err = generator.Generate(GeneratorContext{})
+	assert.NoError(t, err)
+	pinned, err = pinReproducible(GeneratorContext{Dir: dir}, generator, *first)
+	if assert.NoError(t, err) {
+		assert.Equal(t, mockResource("Secret", "app")+"data:\n  password: \"7\"\n", pinned.Resources[0].Content)
+	}
+}
+
+type flakyGenerator struct {
+	calls *int
+}
+
+func (g flakyGenerator) Generate(ctx GeneratorContext) (*GeneratorResult, error) {
+	*g.calls++
+	result := &GeneratorResult{Resources: []GeneratorResource{{File: "a.yaml", Content: mockResource("ConfigMap", "a")}}}
+	if *g.calls%2 == 0 {
+		result.Resources = append(result.Resources, GeneratorResource{File: "b.yaml", Content: mockResource("ConfigMap", "b")})
+	}
+	return result, nil
+}
+
+func TestPinReproducibleRejectsStructuralChanges(t *testing.T) {
+	calls := 0
+	generator := flakyGenerator{calls: &calls}
+	first, err := generator.Generate(GeneratorContext{})
+	assert.NoError(t, err)
+	_, err = pinReproducible(GeneratorContext{}, generator, *first)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "ConfigMap b")
+		assert.Equal(t, 5, ExitCode(err))
+	}
+}
@@ -1,54 +1,458 @@
 package internal
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"time"
 )
 
 const configFile = "kustomization-generator.yaml"
 
-func Run(dir string) error {
-	file := path.Join(dir, configFile)
-	generator, err := LoadGenerator(file)
-	if err != nil {
-		return fmt.Errorf("unable to load configuration: %v", err)
+type RunOptions struct {
+	Context          context.Context
+	Logger           *slog.Logger
+	Progress         *ProgressReporter
+	HelmBin          string
+	CacheDir         string
+	TempDir          string
+	ToolVersion      string
+	VerifyImages     bool
+	Output           string
+	Stdout           io.Writer
+	ConfigFile       string
+	Stdin            io.Reader
+	GitCommit        bool
+	RepositoriesFile string
+	UserConfig       *UserConfig
+	Environment      string
+	Metrics          *Metrics
+	OutputFS         OutputFS
+	RenderCache      bool
+	BundledCharts    bool
+	IndexCache       *HelmIndexCache
+	Strict           bool
+	Stats            *OutputStats
+	Generations      *GenerationMetrics
+	Findings         *ValidationFindings
+}
+
+func Run(dir string, opts RunOptions) error {
+	start := time.Now()
+	err := run(dir, opts)
+	if opts.Generations != nil {
+		opts.Generations.record(dir, configuredChartNames(dir, opts), time.Since(start), err, time.Now())
 	}
+	return err
+}
 
-	kustomizationWithEmbeddedResources, err := (*generator).Generate()
+func run(dir string, opts RunOptions) error {
+	start := time.Now()
+	config, err := loadRunConfig(dir, opts)
+	if err != nil {
+		return err
+	}
+	ctx, err := newGeneratorContext(dir, *config, opts)
 	if err != nil {
 		return err
 	}
+	logger := ctx.log()
+	if config.Hooks != nil {
+		err = runHooks(ctx, "pre", config.Hooks.Pre, *config, nil)
+		if err != nil {
+			return err
+		}
+	}
+	kustomizationWithEmbeddedResources, err := renderWithContext(ctx, *config, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Output == "-" {
+		stdout := opts.Stdout
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+		err = streamResources(stdout, *kustomizationWithEmbeddedResources)
+		if err != nil {
+			return fmt.Errorf("streaming resources failed: %v", err)
+		}
+		ctx.Metrics.recordDir(dir, time.Since(start))
+		logger.Info("generator finished", "type", config.Type, "duration", time.Since(start))
+		return nil
+	}
+	if opts.Output != "" {
+		return configErrorf("unsupported output %s", opts.Output)
+	}
 
-	clear(dir)
-	err = write(dir, *kustomizationWithEmbeddedResources)
+	if err := ctx.context().Err(); err != nil {
+		return ClassifiedError{Class: ErrorClassInterrupted, Err: fmt.Errorf("interrupted before writing: %v", err)}
+	}
+	done := ctx.Phase("writing")
+	writeOpts := newWriteOptions(*config)
+	if config.Metadata {
+		report := newMetadataReport(*config, opts.ToolVersion, *kustomizationWithEmbeddedResources, generationTime())
+		writeOpts.Metadata = &report
+	}
+	fsys := opts.OutputFS
+	if fsys == nil {
+		fsys = NewDirFS(dir)
+	}
+	if config.Metadata {
+		writeOpts.PreviousMetadata, err = readMetadataReport(fsys)
+		if err != nil {
+			done()
+			return err
+		}
+		err = checkManualEdits(ctx, fsys, config.ManualEdits)
+		if err != nil {
+			done()
+			return err
+		}
+	}
+	var charts []ChartStats
+	if opts.Stats != nil {
+		files, err := renderOutput(*kustomizationWithEmbeddedResources, writeOpts)
+		if err == nil {
+			charts, err = collectChartStats(fsys, *kustomizationWithEmbeddedResources, files)
+		}
+		if err != nil {
+			done()
+			return fmt.Errorf("collecting output statistics failed: %v", err)
+		}
+	}
+	stats, err := write(fsys, *kustomizationWithEmbeddedResources, writeOpts)
+	done()
 	if err != nil {
 		return err
 	}
+	opts.Stats.record(dir, charts)
+	logger.Info("files written", "written", stats.Written, "unchanged", stats.Unchanged, "removed", stats.Removed)
+	extraFiles := []string{}
+	if config.ParentKustomization != "" && opts.OutputFS == nil {
+		file, updated, err := updateParentKustomization(dir, config.ParentKustomization)
+		if err != nil {
+			return err
+		}
+		if updated {
+			logger.Info("parent kustomization updated", "file", file)
+		}
+		extraFiles = append(extraFiles, file)
+	}
+	if config.Hooks != nil {
+		err = runHooks(ctx, "post", config.Hooks.Post, *config, kustomizationWithEmbeddedResources)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.GitCommit {
+		err = gitCommitOutput(ctx, *config, opts, extraFiles...)
+		if err != nil {
+			return err
+		}
+	}
+	ctx.Metrics.recordDir(dir, time.Since(start))
+	logger.Info("generator finished", "type", config.Type, "duration", time.Since(start))
 
 	return nil
 }
 
-func clear(dir string) {
-	fds, err := os.ReadDir(dir)
+func render(dir string, opts RunOptions) (*GeneratorContext, *Config, *GeneratorResult, error) {
+	config, err := loadRunConfig(dir, opts)
 	if err != nil {
-		return
+		return nil, nil, nil, err
 	}
-	for _, fd := range fds {
-		if fd.Name() == configFile {
-			continue
+	ctx, result, err := renderConfig(dir, *config, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ctx, config, result, nil
+}
+
+func loadRunConfig(dir string, opts RunOptions) (*Config, error) {
+	var config *Config
+	var err error
+	file := opts.ConfigFile
+	if file == "-" {
+		stdin := opts.Stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		config, err = LoadConfigFromReader(stdin)
+	} else {
+		if file == "" {
+			file = filepath.Join(dir, configFile)
+		}
+		config, err = LoadConfig(file)
+	}
+	if err != nil {
+		return nil, ClassifiedError{Class: ErrorClassConfig, File: file, Err: fmt.Errorf("unable to load configuration: %v", err)}
+	}
+	config, err = applyEnvironment(*config, opts.Environment)
+	if err != nil {
+		return nil, ClassifiedError{Class: ErrorClassConfig, File: file, Err: err}
+	}
+	return config, nil
+}
+
+func renderConfig(dir string, config Config, opts RunOptions) (*GeneratorContext, *GeneratorResult, error) {
+	ctx, err := newGeneratorContext(dir, config, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := renderWithContext(ctx, config, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ctx, result, nil
+}
+
+func newGeneratorContext(dir string, config Config, opts RunOptions) (GeneratorContext, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = discardLogger()
+	}
+	userConfig := UserConfig{}
+	if opts.UserConfig != nil {
+		userConfig = *opts.UserConfig
+	}
+	repositories, err := loadRunRepositories(dir, opts)
+	if err != nil {
+		return GeneratorContext{}, err
+	}
+	transport, err := userConfig.httpTransport()
+	if err != nil {
+		return GeneratorContext{}, err
+	}
+	return GeneratorContext{
+		Context:       opts.Context,
+		Dir:           dir,
+		Logger:        logger.With("dir", dir),
+		Progress:      opts.Progress,
+		Timeouts:      config.Timeouts,
+		HelmBin:       opts.HelmBin,
+		CacheDir:      opts.CacheDir,
+		TempDir:       opts.TempDir,
+		Repositories:  mergeRepositories(userConfig.Repositories, repositories),
+		Transport:     transport,
+		Proxy:         userConfig.Proxy,
+		CaBundle:      userConfig.CaBundle,
+		HelmArgs:      userConfig.HelmArgs,
+		Metrics:       opts.Metrics,
+		Findings:      opts.Findings,
+		RenderCache:   opts.RenderCache,
+		BundledCharts: opts.BundledCharts,
+		IndexCache:    opts.IndexCache,
+		Strict:        opts.Strict,
+	}, nil
+}
+
+func renderWithContext(ctx GeneratorContext, config Config, opts RunOptions) (*GeneratorResult, error) {
+	ctx.log().Info("generator started", "type", config.Type)
+	result, err := generate(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if opts.VerifyImages {
+		done := ctx.Phase("image verification")
+		images, err := collectImages(".", "", *result)
+		if err == nil {
+			err = verifyImages(ctx, images)
+		}
+		done()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func generate(ctx GeneratorContext, config Config) (*GeneratorResult, error) {
+	result, err := config.Generator.Generate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if config.Reproducible {
+		result, err = pinReproducible(ctx, config.Generator, *result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.OutputDir != "" {
+		result = &GeneratorResult{
+			Children: []GeneratorResultChild{{Dir: config.OutputDir, Result: *result}},
+		}
+	}
+	result, err = filterGeneratorResult(*result, config.Include, config.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	result, err = resolveConflicts(*result, config.Conflicts)
+	if err != nil {
+		return nil, err
+	}
+	if config.HelmLabels != nil {
+		result, err = rewriteHelmLabels(*result, *config.HelmLabels)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.Provenance != nil {
+		result, err = addProvenance(*result, *config.Provenance)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.Ownership != nil {
+		result, err = addOwnership(*result, *config.Ownership, ownershipValue(ctx.Dir, config.Generator, config.Type))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(config.PostPatches) > 0 {
+		result, err = applyPostPatches(*result, config.PostPatches)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.Normalize {
+		result, err = normalizeGeneratorResult(*result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.Validate != nil {
+		err = validateGeneratorResult(ctx, *result, *config.Validate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.Policies != nil {
+		err = checkGeneratorResultPolicies(ctx, *result, *config.Policies)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.ExternalSecrets != nil {
+		result, err = convertExternalSecrets(ctx, *result, *config.ExternalSecrets)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.Seal != nil {
+		result, err = sealSecrets(ctx, *result, *config.Seal)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.Sops != nil {
+		result, err = encryptSecretsWithSops(ctx, *result, *config.Sops)
+		if err != nil {
+			return nil, err
 		}
+	}
+	return result, nil
+}
+
+type syncStats struct {
+	Written   int
+	Unchanged int
+	Removed   int
+}
+
+type writeOptions struct {
+	Component        bool
+	Replacements     []interface{}
+	SortOptions      *SortOptions
+	GeneratorOptions *GeneratorOptions
+	Modes            outputModes
+	Header           bool
+	Format           string
+	Metadata         *MetadataReport
+	PreviousMetadata *MetadataReport
+}
+
+func newWriteOptions(config Config) writeOptions {
+	modes, _ := newOutputModes(config.Permissions)
+	return writeOptions{
+		Component:        config.Component,
+		Replacements:     config.Replacements,
+		SortOptions:      config.SortOptions,
+		GeneratorOptions: config.GeneratorOptions,
+		Modes:            modes,
+		Header:           config.Header,
+		Format:           config.OutputFormat,
+	}
+}
+
+func write(fsys OutputFS, result GeneratorResult, opts writeOptions) (*syncStats, error) {
+	files, err := renderOutput(result, opts)
+	if err != nil {
+		return nil, fmt.Errorf("writing kustomization failed: %v", err)
+	}
+	stats, err := syncFiles(fsys, files, opts.Modes)
+	if err != nil {
+		return nil, fmt.Errorf("writing kustomization failed: %v", err)
+	}
+	return stats, nil
+}
+
+func renderOutput(result GeneratorResult, opts writeOptions) (map[string][]byte, error) {
+	files, _, err := renderOutputKustomization(result, opts)
+	return files, err
+}
 
-		fp := path.Join(dir, fd.Name())
-		if fd.IsDir() {
-			os.RemoveAll(fp)
-		} else {
-			os.Remove(fp)
+func renderOutputKustomization(result GeneratorResult, opts writeOptions) (map[string][]byte, *Kustomization, error) {
+	files := map[string][]byte{}
+	if opts.Format == "json" {
+		converted, err := convertResourcesToJson(result)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = *converted
+	}
+	if opts.Header {
+		result = addGenerationHeaders(result, nil)
+	}
+	kustomization, err := renderFiles("", result, files)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Component {
+		kustomization.ApiVersion = "kustomize.config.k8s.io/v1alpha1"
+		kustomization.Kind = "Component"
+	}
+	kustomization.Replacements = opts.Replacements
+	kustomization.SortOptions = opts.SortOptions
+	kustomization.GeneratorOptions = opts.GeneratorOptions
+	err = renderYamlFile("kustomization.yaml", kustomization, files)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Header {
+		addKustomizationHeaders(files)
+	}
+	if opts.Metadata != nil {
+		report := *opts.Metadata
+		report.Files = hashWrittenFiles(files)
+		report = keepMetadataTimestamp(report, opts.PreviousMetadata)
+		err = renderYamlFile(metadataFile, report, files)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
+	return files, kustomization, nil
 }
 
-func write(dir string, result GeneratorResult) error {
+func renderFiles(dir string, result GeneratorResult, files map[string][]byte) (*Kustomization, error) {
 	buckets := []struct {
 		name          string
 		filter        func(resource GeneratorResource) bool
@@ -77,25 +481,31 @@ func write(dir string, result GeneratorResult) error {
 
 	kustomization := Kustomization{}
 
+	for name, content := range result.Files {
+		files[path.Join(dir, name)] = content
+	}
+	for _, child := range result.Children {
+		_, err := renderFiles(path.Join(dir, child.Dir), child.Result, files)
+		if err != nil {
+			return nil, err
+		}
+		kustomization.Resources = append(kustomization.Resources, child.Dir)
+	}
+	if len(result.Children) > 0 && len(result.Resources) == 0 {
+		return &kustomization, renderYamlFile(path.Join(dir, "kustomization.yaml"), kustomization, files)
+	}
+
 	for i := range buckets {
 		bucket := &buckets[i]
 		bucket.dir = path.Join(dir, bucket.name)
-		err := os.MkdirAll(bucket.dir, 0o755)
-		if err != nil {
-			return fmt.Errorf("writing kustomization failed: %v", err)
-		}
 	}
 
-	for _, resource := range result.Resources {
+	for _, resource := range sortResourcesBySyncOrder(result.Resources) {
 		for i := range buckets {
 			bucket := &buckets[i]
 			if bucket.filter(resource) {
 				bucket.kustomization.Resources = append(bucket.kustomization.Resources, resource.File)
-				resourcePath := path.Join(bucket.dir, resource.File)
-				err := os.WriteFile(resourcePath, []byte(resource.Content), 0o644)
-				if err != nil {
-					return fmt.Errorf("writing kustomization failed: %v", err)
-				}
+				files[path.Join(bucket.dir, resource.File)] = []byte(resource.Content)
 				break
 			}
 		}
@@ -103,20 +513,191 @@ func write(dir string, result GeneratorResult) error {
 
 	for _, bucket := range buckets {
 		kustomization.Resources = append(kustomization.Resources, bucket.name)
-		err := writeYamlFile(path.Join(bucket.dir, "kustomization.yaml"), bucket.kustomization)
+		err := renderYamlFile(path.Join(bucket.dir, "kustomization.yaml"), bucket.kustomization, files)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &kustomization, renderYamlFile(path.Join(dir, "kustomization.yaml"), kustomization, files)
+}
+
+func streamResources(w io.Writer, result GeneratorResult) error {
+	for i, resource := range orderResources(result) {
+		if i > 0 {
+			_, err := io.WriteString(w, "---\n")
+			if err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, resource.Content)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func orderResources(result GeneratorResult) []GeneratorResource {
+	resources := sortResourcesBySyncOrder(result.AllResources())
+	ordered := []GeneratorResource{}
+	for _, resource := range resources {
+		if resource.ApiVersion == "apiextensions.k8s.io/v1" && resource.Kind == "CustomResourceDefinition" {
+			ordered = append(ordered, resource)
+		}
+	}
+	for _, resource := range resources {
+		if resource.ApiVersion == "v1" && resource.Kind == "Namespace" {
+			ordered = append(ordered, resource)
+		}
+	}
+	for _, resource := range resources {
+		if !(resource.ApiVersion == "apiextensions.k8s.io/v1" && resource.Kind == "CustomResourceDefinition") && !(resource.ApiVersion == "v1" && resource.Kind == "Namespace") {
+			ordered = append(ordered, resource)
+		}
+	}
+	return ordered
+}
+
+func renderYamlFile(file string, v interface{}, files map[string][]byte) error {
+	bytes, err := writeYaml(v)
+	if err != nil {
+		return err
+	}
+	files[file] = bytes
+	return nil
+}
+
+func syncFiles(fsys OutputFS, files map[string][]byte, modes outputModes) (*syncStats, error) {
+	tx := newTransactionFS(fsys)
+	stats, err := applyFiles(tx, files, modes)
+	if err != nil {
+		rollbackErr := tx.rollback()
+		if rollbackErr != nil {
+			return nil, fmt.Errorf("%v (rolling back failed: %v)", err, rollbackErr)
+		}
+		return nil, err
+	}
+	return stats, nil
+}
+
+func applyFiles(fsys OutputFS, files map[string][]byte, modes outputModes) (*syncStats, error) {
+	stats := syncStats{}
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ignore, err := readGeneratorIgnore(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := map[string]bool{}
+	for _, name := range names {
+		if isIgnoredFile(name, ignore) {
+			continue
+		}
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+		existing, err := fs.ReadFile(fsys, name)
+		if err == nil && bytes.Equal(existing, files[name]) {
+			err = enforceMode(fsys, name, modes.File)
+			if err != nil {
+				return nil, err
+			}
+			stats.Unchanged++
+			continue
+		}
+		err = fsys.MkdirAll(path.Dir(name), modes.dirMode())
+		if err != nil {
+			return nil, err
+		}
+		err = fsys.WriteFile(name, files[name], modes.fileMode())
+		if err != nil {
+			return nil, err
+		}
+		err = enforceMode(fsys, name, modes.File)
 		if err != nil {
-			return fmt.Errorf("writing kustomization failed: %v", err)
+			return nil, err
+		}
+		stats.Written++
+	}
+	for dir := range dirs {
+		err = enforceMode(fsys, dir, modes.Dir)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	err := os.MkdirAll(dir, 0o755)
+	removed, err := prune(fsys, files, ignore)
 	if err != nil {
-		return fmt.Errorf("writing kustomization failed: %v", err)
+		return nil, err
 	}
-	err = writeYamlFile(path.Join(dir, "kustomization.yaml"), kustomization)
+	stats.Removed = removed
+	return &stats, nil
+}
+
+func prune(fsys OutputFS, files map[string][]byte, ignore []string) (int, error) {
+	removed := 0
+	existing, dirs, err := listOutputFiles(fsys, ignore)
 	if err != nil {
-		return fmt.Errorf("writing kustomization failed: %v", err)
+		return removed, err
+	}
+	for _, name := range existing {
+		if _, ok := files[name]; !ok {
+			removed++
+			err := fsys.Remove(name)
+			if err != nil {
+				return removed, err
+			}
+		}
 	}
 
-	return nil
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := fs.ReadDir(fsys, dirs[i])
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return removed, err
+		}
+		if len(entries) == 0 {
+			err = fsys.Remove(dirs[i])
+			if err != nil {
+				return removed, err
+			}
+		}
+	}
+	return removed, nil
+}
+
+func listOutputFiles(fsys fs.FS, ignore []string) ([]string, []string, error) {
+	files := []string{}
+	dirs := []string{}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." || name == configFile || name == valuesDocsFile {
+			return nil
+		}
+		if d.IsDir() {
+			if _, err := fs.Stat(fsys, path.Join(name, configFile)); err == nil {
+				return fs.SkipDir
+			}
+			dirs = append(dirs, name)
+			return nil
+		}
+		if isIgnoredFile(name, ignore) {
+			return nil
+		}
+		files = append(files, name)
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return files, dirs, nil
+	}
+	return files, dirs, err
 }
@@ -1,27 +1,153 @@
 package internal
 
 import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestRunDownload(t *testing.T) {
-	err := Run("../example/download")
+	err := Run("../example/download", RunOptions{})
 	assert.NoError(t, err)
 }
 
 func TestRunHelm(t *testing.T) {
-	err := Run("../example/helm")
+	err := Run("../example/helm", RunOptions{})
 	assert.NoError(t, err)
 }
 
 func TestRunHelmOci(t *testing.T) {
-	err := Run("../example/helm-oci")
+	err := Run("../example/helm-oci", RunOptions{})
 	assert.NoError(t, err)
 }
 
 func TestRunKustomize(t *testing.T) {
-	err := Run("../example/kustomize")
+	err := Run("../example/kustomize", RunOptions{})
 	assert.NoError(t, err)
 }
+
+func TestWriteSkipsUnchangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte("type: download\n"), 0o644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "stale"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "stale", "file.yaml"), []byte("stale"), 0o644))
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "database-secret.yaml", Content: mockResource("Secret", "database")},
+		},
+	}
+
+	stats, err := write(NewDirFS(dir), result, writeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, syncStats{Written: 5, Removed: 1}, *stats)
+	assert.NoDirExists(t, filepath.Join(dir, "stale"))
+	assert.FileExists(t, filepath.Join(dir, configFile))
+	content, err := os.ReadFile(filepath.Join(dir, "resources", "database-secret.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, mockResource("Secret", "database"), string(content))
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, "resources", "database-secret.yaml"), past, past))
+	stats, err = write(NewDirFS(dir), result, writeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, syncStats{Unchanged: 5}, *stats)
+	info, err := os.Stat(filepath.Join(dir, "resources", "database-secret.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, past, info.ModTime())
+}
+
+func TestRenderFilesUsesForwardSlashes(t *testing.T) {
+	files := map[string][]byte{}
+	result := GeneratorResult{
+		Children: []GeneratorResultChild{
+			{
+				Dir: "release",
+				Result: GeneratorResult{
+					Resources: []GeneratorResource{
+						{ApiVersion: "v1", Kind: "Secret", File: "database-secret.yaml", Content: mockResource("Secret", "database")},
+					},
+				},
+			},
+		},
+	}
+	_, err := renderFiles("", result, files)
+	assert.NoError(t, err)
+	assert.Contains(t, files, "release/resources/database-secret.yaml")
+	assert.Equal(t, "resources:\n  - release\n", string(files["kustomization.yaml"]))
+	assert.Equal(t, "resources:\n  - crds\n  - namespaces\n  - resources\n", string(files["release/kustomization.yaml"]))
+}
+
+func TestWriteComponent(t *testing.T) {
+	fsys := NewMemoryFS()
+	_, err := write(fsys, GeneratorResult{}, writeOptions{Component: true})
+	assert.NoError(t, err)
+	content, err := fs.ReadFile(fsys, "kustomization.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "apiVersion: kustomize.config.k8s.io/v1alpha1\nkind: Component\nresources:\n  - crds\n  - namespaces\n  - resources\n", string(content))
+}
+
+func TestWriteReplacements(t *testing.T) {
+	fsys := NewMemoryFS()
+	replacements := []interface{}{
+		map[string]interface{}{
+			"source": map[string]interface{}{"kind": "Service", "name": "app"},
+			"targets": []interface{}{
+				map[string]interface{}{
+					"select":     map[string]interface{}{"kind": "Ingress"},
+					"fieldPaths": []interface{}{"spec.rules.0.http.paths.0.backend.service.name"},
+				},
+			},
+		},
+	}
+	_, err := write(fsys, GeneratorResult{}, writeOptions{Replacements: replacements})
+	assert.NoError(t, err)
+	content, err := fs.ReadFile(fsys, "kustomization.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, `resources:
+  - crds
+  - namespaces
+  - resources
+replacements:
+  - source:
+      kind: Service
+      name: app
+    targets:
+      - fieldPaths:
+          - spec.rules.0.http.paths.0.backend.service.name
+        select:
+          kind: Ingress
+`, string(content))
+}
+
+func TestStreamResources(t *testing.T) {
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "database-secret.yaml", Content: mockResource("Secret", "database")},
+		},
+		Children: []GeneratorResultChild{
+			{Dir: "app", Result: GeneratorResult{Resources: []GeneratorResource{
+				{ApiVersion: "v1", Kind: "Namespace", File: "app-namespace.yaml", Content: mockResource("Namespace", "app")},
+			}}},
+		},
+	}
+	output := strings.Builder{}
+	assert.NoError(t, streamResources(&output, result))
+	assert.Equal(t, mockResource("Namespace", "app")+"---\n"+mockResource("Secret", "database"), output.String())
+}
+
+func TestRenderFilesIncludesExtraFiles(t *testing.T) {
+	files := map[string][]byte{}
+	result := GeneratorResult{
+		Children: []GeneratorResultChild{
+			{Dir: "app", Result: GeneratorResult{Files: map[string][]byte{"chart/LICENSE": []byte("MIT")}}},
+		},
+	}
+	_, err := renderFiles("", result, files)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("MIT"), files["app/chart/LICENSE"])
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+type SandboxConfig struct {
+	Env             []string `yaml:"env"`
+	IsolatedWorkDir bool     `yaml:"isolatedWorkDir"`
+}
+
+func (s SandboxConfig) apply(cmd *exec.Cmd, tempDir string) (func(), error) {
+	home, err := os.MkdirTemp(tempDir, ".kustomization-generator-*-sandbox")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() {
+		os.RemoveAll(home)
+	}
+
+	env := []string{
+		"HOME=" + home,
+		"HELM_CACHE_HOME=" + filepath.Join(home, "cache"),
+		"HELM_CONFIG_HOME=" + filepath.Join(home, "config"),
+		"HELM_DATA_HOME=" + filepath.Join(home, "data"),
+	}
+	for _, name := range append([]string{"PATH"}, s.Env...) {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	cmd.Env = env
+	if s.IsolatedWorkDir {
+		workDir := filepath.Join(home, "work")
+		err := os.MkdirAll(workDir, 0o700)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		cmd.Dir = workDir
+	}
+	return cleanup, nil
+}
+
+func absolutizePaths(dir string, values map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range values {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			result[key] = absolutizePaths(dir, v)
+		case string:
+			if abs, err := filepath.Abs(resolvePath(dir, v)); err == nil {
+				result[key] = abs
+			} else {
+				result[key] = v
+			}
+		default:
+			result[key] = v
+		}
+	}
+	return result
+}
+
+func resolvePath(dir string, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(dir, file)
+}
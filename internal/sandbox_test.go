@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSandboxConfigApply(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/secret/kubeconfig")
+	t.Setenv("KUSTOMIZATION_GENERATOR_ALLOWED", "yes")
+	cmd := exec.Command("env")
+	cleanup, err := SandboxConfig{Env: []string{"KUSTOMIZATION_GENERATOR_ALLOWED"}, IsolatedWorkDir: true}.apply(cmd, "")
+	if assert.NoError(t, err) {
+		joined := strings.Join(cmd.Env, "\n")
+		assert.NotContains(t, joined, "KUBECONFIG")
+		assert.Contains(t, joined, "KUSTOMIZATION_GENERATOR_ALLOWED=yes")
+		assert.Contains(t, joined, "PATH="+os.Getenv("PATH"))
+		assert.DirExists(t, cmd.Dir)
+		cleanup()
+		assert.NoDirExists(t, filepath.Dir(cmd.Dir))
+	}
+}
+
+func TestSandboxConfigApplyTempDir(t *testing.T) {
+	tempDir := t.TempDir()
+	cmd := exec.Command("env")
+	cleanup, err := SandboxConfig{IsolatedWorkDir: true}.apply(cmd, tempDir)
+	if assert.NoError(t, err) {
+		defer cleanup()
+		assert.Equal(t, tempDir, filepath.Dir(filepath.Dir(cmd.Dir)))
+	}
+}
@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ValidationFinding struct {
+	Rule    string
+	Message string
+	File    string
+	Line    int
+}
+
+type ValidationFindings struct {
+	mu       sync.Mutex
+	Findings []ValidationFinding
+}
+
+func NewValidationFindings() *ValidationFindings {
+	return &ValidationFindings{Findings: []ValidationFinding{}}
+}
+
+func (f *ValidationFindings) record(findings ...ValidationFinding) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Findings = append(f.Findings, findings...)
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func (f *ValidationFindings) WriteSarif(w io.Writer, toolVersion string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rules := map[string]bool{}
+	results := []sarifResult{}
+	for _, finding := range f.Findings {
+		rules[finding.Rule] = true
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{Uri: finding.File}}}
+		if finding.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line}
+		}
+		results = append(results, sarifResult{
+			RuleId:    finding.Rule,
+			Level:     "error",
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+	ruleIds := []string{}
+	for rule := range rules {
+		ruleIds = append(ruleIds, rule)
+	}
+	sort.Strings(ruleIds)
+	driver := sarifDriver{
+		Name:           "kustomization-generator",
+		Version:        toolVersion,
+		InformationUri: "https://github.com/airfocusio/kustomization-generator",
+		Rules:          []sarifRule{},
+	}
+	for _, rule := range ruleIds {
+		description := "Resource does not match its schema"
+		if namespace, ok := strings.CutPrefix(rule, "policy/"); ok {
+			description = fmt.Sprintf("Resource violates policy %s", namespace)
+		}
+		driver.Rules = append(driver.Rules, sarifRule{Id: rule, ShortDescription: sarifMessage{Text: description}})
+	}
+	bytes, err := json.MarshalIndent(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(bytes, '\n'))
+	return err
+}
+
+func findingPath(dir string, file string) string {
+	result := filepath.Join(dir, filepath.FromSlash(file))
+	if filepath.IsAbs(result) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, result); err == nil && !strings.HasPrefix(rel, "..") {
+				result = rel
+			}
+		}
+	}
+	return filepath.ToSlash(result)
+}
+
+func configKeyLine(dir string, key string) int {
+	bytes, err := os.ReadFile(filepath.Join(dir, configFile))
+	if err != nil {
+		return 0
+	}
+	document := yaml.Node{}
+	if yaml.Unmarshal(bytes, &document) != nil || len(document.Content) == 0 {
+		return 0
+	}
+	root := document.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			return root.Content[i].Line
+		}
+	}
+	return 0
+}
+
+func collectResourceFiles(result GeneratorResult) map[string]string {
+	files := map[string]string{}
+	rendered := map[string][]byte{}
+	if _, err := renderFiles("", result, rendered); err != nil {
+		return files
+	}
+	for name, content := range rendered {
+		if path.Base(name) == "kustomization.yaml" {
+			continue
+		}
+		id, err := identifyResource(GeneratorResource{Content: string(content)})
+		if err != nil || id.Kind == "" {
+			continue
+		}
+		key := id.Kind + "/" + id.Name
+		if existing, exists := files[key]; !exists || name < existing {
+			files[key] = name
+		}
+	}
+	return files
+}
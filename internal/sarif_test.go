@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationFindingsSarif(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte("type: helm\nvalidate:\n  strict: true\npolicies:\n  paths: [policy]\n"), 0o644))
+	bin := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "kubeconform"), []byte(`#!/bin/sh
+echo '{"resources":[{"kind":"Deployment","name":"app","version":"apps/v1","status":"statusInvalid","msg":"unknown field spec.replica"}]}'
+exit 1
+`), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "conftest"), []byte(`#!/bin/sh
+echo '[{"filename":"-","namespace":"main","failures":[{"msg":"containers must not run as root"}]}]'
+exit 1
+`), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	findings := NewValidationFindings()
+	ctx := GeneratorContext{Dir: dir, Findings: findings}
+	result := GeneratorResult{Children: []GeneratorResultChild{{Dir: "app", Result: GeneratorResult{Resources: []GeneratorResource{{ApiVersion: "apps/v1", Kind: "Deployment", File: "app-deployment.yaml", Content: mockResource("Deployment", "app")}}}}}}
+	assert.Error(t, validateGeneratorResult(ctx, result, ValidationConfig{}))
+	assert.Error(t, checkGeneratorResultPolicies(ctx, result, PolicyConfig{}))
+	assert.Equal(t, []ValidationFinding{
+		{Rule: "validation", Message: "Deployment app (apps/v1): unknown field spec.replica", File: findingPath(dir, "app/resources/app-deployment.yaml")},
+		{Rule: "policy/main", Message: "containers must not run as root", File: findingPath(dir, configFile), Line: 4},
+	}, findings.Findings)
+
+	output := strings.Builder{}
+	assert.NoError(t, findings.WriteSarif(&output, "1.0.0"))
+	sarif := sarifLog{}
+	assert.NoError(t, json.Unmarshal([]byte(output.String()), &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	if assert.Len(t, sarif.Runs, 1) {
+		run := sarif.Runs[0]
+		assert.Equal(t, "1.0.0", run.Tool.Driver.Version)
+		assert.Equal(t, []sarifRule{
+			{Id: "policy/main", ShortDescription: sarifMessage{Text: "Resource violates policy main"}},
+			{Id: "validation", ShortDescription: sarifMessage{Text: "Resource does not match its schema"}},
+		}, run.Tool.Driver.Rules)
+		if assert.Len(t, run.Results, 2) {
+			assert.Nil(t, run.Results[0].Locations[0].PhysicalLocation.Region)
+			assert.Equal(t, &sarifRegion{StartLine: 4}, run.Results[1].Locations[0].PhysicalLocation.Region)
+		}
+	}
+}
+
+func TestFindingPath(t *testing.T) {
+	assert.Equal(t, "vendors/app/deployment.yaml", findingPath("vendors/app", "./deployment.yaml"))
+	wd, err := os.Getwd()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "vendors/app/deployment.yaml", findingPath(filepath.Join(wd, "vendors", "app"), "deployment.yaml"))
+	}
+}
+
+func TestCollectResourceFiles(t *testing.T) {
+	crd := "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: apps.example.com\n"
+	result := GeneratorResult{Children: []GeneratorResultChild{{Dir: "output", Result: GeneratorResult{Resources: []GeneratorResource{
+		{ApiVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", File: "apps-crd.yaml", Content: crd},
+		{ApiVersion: "v1", Kind: "Namespace", File: "app-namespace.yaml", Content: mockResource("Namespace", "app")},
+		{ApiVersion: "v1", Kind: "ConfigMap", File: "app-configmap.yaml", Content: mockResource("ConfigMap", "app")},
+	}}}}}
+	assert.Equal(t, map[string]string{
+		"CustomResourceDefinition/apps.example.com": "output/crds/apps-crd.yaml",
+		"Namespace/app": "output/namespaces/app-namespace.yaml",
+		"ConfigMap/app": "output/resources/app-configmap.yaml",
+	}, collectResourceFiles(result))
+}
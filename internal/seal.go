@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type SealConfig struct {
+	Cert                string `yaml:"cert"`
+	Scope               string `yaml:"scope"`
+	ControllerName      string `yaml:"controllerName"`
+	ControllerNamespace string `yaml:"controllerNamespace"`
+	Namespace           string `yaml:"namespace"`
+	ReuseKeyEnv         string `yaml:"reuseKeyEnv"`
+}
+
+func sealSecrets(ctx GeneratorContext, result GeneratorResult, config SealConfig) (*GeneratorResult, error) {
+	if config.Cert == "" {
+		return nil, configErrorf("seal is missing cert")
+	}
+	if config.Scope != "" && config.Scope != "strict" && config.Scope != "namespace-wide" && config.Scope != "cluster-wide" {
+		return nil, configErrorf("unsupported seal scope %s", config.Scope)
+	}
+	kubesealPath, err := exec.LookPath("kubeseal")
+	if err != nil {
+		return nil, executionErrorf("executing kubeseal failed: executable not found")
+	}
+	cert := config.Cert
+	if !strings.Contains(cert, "://") {
+		cert = resolvePath(ctx.Dir, cert)
+	}
+	args := []string{"--cert", cert, "--format", "yaml"}
+	if config.Scope != "" {
+		args = append(args, "--scope", config.Scope)
+	}
+	if config.ControllerName != "" {
+		args = append(args, "--controller-name", config.ControllerName)
+	}
+	if config.ControllerNamespace != "" {
+		args = append(args, "--controller-namespace", config.ControllerNamespace)
+	}
+
+	reuseKey := ""
+	existing := map[string]string{}
+	if config.ReuseKeyEnv != "" {
+		reuseKey = os.Getenv(config.ReuseKeyEnv)
+		if reuseKey == "" {
+			return nil, configErrorf("environment variable %s for seal reuseKeyEnv is not set", config.ReuseKeyEnv)
+		}
+		contents, err := loadExistingResources(ctx.Dir)
+		if err != nil {
+			return nil, err
+		}
+		existing = sealedSecretsByHmac(contents)
+	}
+
+	done := ctx.Phase("sealing secrets")
+	defer done()
+	return mapGeneratorResources(result, func(resource GeneratorResource) (GeneratorResource, error) {
+		if resource.ApiVersion != "v1" || resource.Kind != "Secret" {
+			return resource, nil
+		}
+		file := strings.TrimSuffix(strings.TrimSuffix(resource.File, ".yaml"), "-secret") + "-sealedsecret.yaml"
+		id, err := identifyResource(resource)
+		if err != nil {
+			return resource, fmt.Errorf("sealing %s failed: %v", resource.File, err)
+		}
+		namespace := id.Namespace
+		if namespace == "" {
+			namespace = config.Namespace
+		}
+		if namespace == "" && config.Scope != "cluster-wide" {
+			return resource, configErrorf("sealing %s requires a namespace, set it on the secret or as seal namespace", resource.File)
+		}
+		digest := ""
+		if reuseKey != "" {
+			digest = plaintextSecretHmac(reuseKey, resource.Content, config.Cert, config.Scope, namespace, config.ControllerName, config.ControllerNamespace)
+			if content, ok := existing[digest]; ok {
+				if reused, ok := reuseEncryptedSecret(content, file); ok {
+					return *reused, nil
+				}
+			}
+		}
+		sealArgs := args
+		if namespace != "" {
+			sealArgs = append(append([]string{}, args...), "--namespace", namespace)
+		}
+		cmd := exec.Command(kubesealPath, sealArgs...)
+		cmd.Stdin = bytes.NewReader([]byte(resource.Content))
+		stdout, stderr, err := ctx.runCommand(*cmd)
+		if err != nil {
+			return resource, executionErrorf("executing kubeseal for %s failed: %v\n%s", resource.File, err, string(stderr))
+		}
+		resources, err := splitCombinedKubernetesResources(string(stdout))
+		if err != nil || len(resources) != 1 {
+			return resource, executionErrorf("executing kubeseal for %s failed: unexpected output", resource.File)
+		}
+		sealed := resources[0]
+		sealed.File = file
+		if digest != "" {
+			sealed.Content, err = addPlaintextHmac(sealed.Content, digest)
+			if err != nil {
+				return resource, executionErrorf("executing kubeseal for %s failed: %v", resource.File, err)
+			}
+		}
+		return sealed, nil
+	})
+}
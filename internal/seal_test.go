@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSealSecrets(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncat > /dev/null\necho \"apiVersion: bitnami.com/v1alpha1\"\necho \"kind: SealedSecret\"\necho \"metadata:\"\necho \"  name: app\"\necho \"  annotations:\"\necho \"    args: $*\"\necho \"    random: $$\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "kubeseal"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "app-secret.yaml", Content: mockResource("Secret", "app")},
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "app-configmap.yaml", Content: mockResource("ConfigMap", "app")},
+		},
+	}
+	sealed, err := sealSecrets(GeneratorContext{}, result, SealConfig{Cert: "pub-cert.pem", Scope: "namespace-wide", Namespace: "default"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "bitnami.com/v1alpha1", sealed.Resources[0].ApiVersion)
+		assert.Equal(t, "SealedSecret", sealed.Resources[0].Kind)
+		assert.Equal(t, "app-sealedsecret.yaml", sealed.Resources[0].File)
+		assert.Contains(t, sealed.Resources[0].Content, "args: --cert pub-cert.pem --format yaml --scope namespace-wide --namespace default")
+		assert.NotContains(t, sealed.Resources[0].Content, plaintextHmacAnnotation)
+		assert.Equal(t, result.Resources[1], sealed.Resources[1])
+	}
+
+	sealed, err = sealSecrets(GeneratorContext{Dir: "/config"}, result, SealConfig{Cert: "pub-cert.pem", Namespace: "default"})
+	if assert.NoError(t, err) {
+		assert.Contains(t, sealed.Resources[0].Content, "args: --cert /config/pub-cert.pem --format yaml")
+	}
+	sealed, err = sealSecrets(GeneratorContext{Dir: "/config"}, result, SealConfig{Cert: "https://sealed-secrets.example.com/v1/cert.pem", Namespace: "default"})
+	if assert.NoError(t, err) {
+		assert.Contains(t, sealed.Resources[0].Content, "args: --cert https://sealed-secrets.example.com/v1/cert.pem --format yaml")
+	}
+
+	_, err = sealSecrets(GeneratorContext{}, result, SealConfig{})
+	assert.Error(t, err)
+	_, err = sealSecrets(GeneratorContext{}, result, SealConfig{Cert: "pub-cert.pem", Scope: "global"})
+	assert.Error(t, err)
+	_, err = sealSecrets(GeneratorContext{}, result, SealConfig{Cert: "pub-cert.pem"})
+	if assert.Error(t, err) {
+		assert.Equal(t, 2, ExitCode(err))
+		assert.Contains(t, err.Error(), "requires a namespace")
+	}
+}
+
+func TestSealSecretsReusesUnchangedSecrets(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncat > /dev/null\necho \"apiVersion: bitnami.com/v1alpha1\"\necho \"kind: SealedSecret\"\necho \"metadata:\"\necho \"  name: app\"\necho \"  namespace: default\"\necho \"spec:\"\necho \"  random: $$\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "kubeseal"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	ctx := GeneratorContext{Dir: dir}
+	config := SealConfig{Cert: "pub-cert.pem", ReuseKeyEnv: "SEAL_REUSE_KEY"}
+	_, err := sealSecrets(ctx, GeneratorResult{}, config)
+	if assert.Error(t, err) {
+		assert.Equal(t, 2, ExitCode(err))
+		assert.Contains(t, err.Error(), "SEAL_REUSE_KEY")
+	}
+	t.Setenv("SEAL_REUSE_KEY", "not-in-the-repository")
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "app-secret.yaml", Content: mockResource("Secret", "app") + "  namespace: default\nstringData:\n  password: a\n"},
+		},
+	}
+	sealed, err := sealSecrets(ctx, result, config)
+	assert.NoError(t, err)
+	assert.Contains(t, sealed.Resources[0].Content, plaintextHmacAnnotation+": ")
+	assert.NotContains(t, sealed.Resources[0].Content, plaintextSecretHmac("", result.Resources[0].Content, "pub-cert.pem", "", "default", "", ""))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "resources"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "resources", "app-sealedsecret.yaml"), []byte(generationHeader+sealed.Resources[0].Content), 0o644))
+
+	resealed, err := sealSecrets(ctx, result, config)
+	if assert.NoError(t, err) {
+		assert.Equal(t, sealed.Resources[0], resealed.Resources[0])
+	}
+
+	result.Resources[0].Content = strings.Replace(result.Resources[0].Content, "password: a", "password: b", 1)
+	changed, err := sealSecrets(ctx, result, config)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, sealed.Resources[0].Content, changed.Resources[0].Content)
+	}
+}
+
+func TestSealSecretsWithoutReuseKeyAlwaysReseals(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncat > /dev/null\necho \"apiVersion: bitnami.com/v1alpha1\"\necho \"kind: SealedSecret\"\necho \"metadata:\"\necho \"  name: app\"\necho \"spec:\"\necho \"  random: $$\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "kubeseal"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	ctx := GeneratorContext{Dir: dir}
+	config := SealConfig{Cert: "pub-cert.pem", Namespace: "default"}
+	result := GeneratorResult{Resources: []GeneratorResource{{ApiVersion: "v1", Kind: "Secret", File: "app-secret.yaml", Content: mockResource("Secret", "app")}}}
+	sealed, err := sealSecrets(ctx, result, config)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app-sealedsecret.yaml"), []byte(sealed.Resources[0].Content), 0o644))
+	resealed, err := sealSecrets(ctx, result, config)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, sealed.Resources[0].Content, resealed.Resources[0].Content)
+	}
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const plaintextHmacAnnotation = "kustomization-generator/plaintext-hmac-sha256"
+
+func plaintextSecretHmac(key string, content string, settings ...string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strings.Join(append(settings, content), "\x00")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func loadExistingResources(dir string) ([]string, error) {
+	result := []string{}
+	if dir == "" {
+		return result, nil
+	}
+	fsys := os.DirFS(dir)
+	files, _, err := listOutputFiles(fsys, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading existing secrets failed: %v", err)
+	}
+	for _, name := range files {
+		if !strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		bytes, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("reading existing secrets failed: %v", err)
+		}
+		content := string(bytes)
+		if strings.HasPrefix(content, "# Generated by kustomization-generator") {
+			_, content, _ = strings.Cut(content, "\n")
+		}
+		result = append(result, content)
+	}
+	return result, nil
+}
+
+func sealedSecretsByHmac(contents []string) map[string]string {
+	result := map[string]string{}
+	for _, content := range contents {
+		resource := KubernetesResource{}
+		if yaml.Unmarshal([]byte(content), &resource) != nil {
+			continue
+		}
+		if digest := resource.Metadata.Annotations[plaintextHmacAnnotation]; digest != "" {
+			result[digest] = content
+		}
+	}
+	return result
+}
+
+func sopsSecretsByIdentity(contents []string) map[string]string {
+	result := map[string]string{}
+	ambiguous := map[string]bool{}
+	for _, content := range contents {
+		encrypted := struct {
+			Sops map[string]interface{} `yaml:"sops"`
+		}{}
+		if yaml.Unmarshal([]byte(content), &encrypted) != nil || encrypted.Sops == nil {
+			continue
+		}
+		id, err := identifyResource(GeneratorResource{Content: content})
+		if err != nil || id.Kind != "Secret" {
+			continue
+		}
+		key := id.String()
+		if _, exists := result[key]; exists {
+			ambiguous[key] = true
+		}
+		result[key] = content
+	}
+	for key := range ambiguous {
+		delete(result, key)
+	}
+	return result
+}
+
+func reuseEncryptedSecret(content string, file string) (*GeneratorResource, bool) {
+	resource := KubernetesResource{}
+	if yaml.Unmarshal([]byte(content), &resource) != nil {
+		return nil, false
+	}
+	return &GeneratorResource{ApiVersion: resource.ApiVersion, Kind: resource.Kind, File: file, Content: content}, true
+}
+
+func sameYamlContent(a string, b string) bool {
+	var aValue, bValue interface{}
+	if yaml.Unmarshal([]byte(a), &aValue) != nil || yaml.Unmarshal([]byte(b), &bValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+func addPlaintextHmac(content string, digest string) (string, error) {
+	document := yaml.Node{}
+	err := yaml.Unmarshal([]byte(content), &document)
+	if err != nil {
+		return "", err
+	}
+	metadata := yamlMappingEnsure(yamlDocumentRoot(&document), "metadata")
+	yamlMappingSet(yamlMappingEnsure(metadata, "annotations"), plaintextHmacAnnotation, digest)
+	bytes, err := writeYaml(&document)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
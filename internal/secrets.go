@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const encryptAnnotation = "kustomization-generator/encrypt"
+
+var secretResolvers = map[string]func(ctx GeneratorContext, ref string) (string, error){
+	"vault":  resolveVaultSecret,
+	"aws-sm": resolveAwsSecretsManagerSecret,
+}
+
+func resolveSecretValues(ctx GeneratorContext, secretValues map[string]string) (map[string]string, error) {
+	keys := []string{}
+	for key := range secretValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := map[string]string{}
+	for _, key := range keys {
+		reference := secretValues[key]
+		scheme, ref, ok := strings.Cut(reference, ":")
+		resolver, known := secretResolvers[scheme]
+		if !ok || !known {
+			return nil, configErrorf("secret value %s has unsupported reference %s", key, reference)
+		}
+		value, err := resolver(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		ctx.log().Info("secret value resolved", "key", key, "source", scheme)
+		result[key] = value
+	}
+	return result, nil
+}
+
+func resolveVaultSecret(ctx GeneratorContext, ref string) (string, error) {
+	secretPath, field, ok := strings.Cut(ref, "#")
+	if !ok || secretPath == "" || field == "" {
+		return "", configErrorf("vault reference %s must have the form path#field", ref)
+	}
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", configErrorf("resolving vault secret %s failed: VAULT_ADDR is not set", secretPath)
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			if bytes, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+				token = strings.TrimSpace(string(bytes))
+			}
+		}
+	}
+	url := strings.TrimSuffix(addr, "/") + "/v1/" + strings.TrimPrefix(secretPath, "/")
+	req, err := http.NewRequestWithContext(ctx.context(), "GET", url, nil)
+	if err != nil {
+		return "", networkErrorf("resolving vault secret %s failed: %v", secretPath, err)
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	resp, err := ctx.httpDo(req)
+	if err != nil {
+		return "", networkErrorf("resolving vault secret %s failed: %v", secretPath, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", networkErrorf("resolving vault secret %s failed: %v", secretPath, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", networkErrorf("resolving vault secret %s failed: status %d", secretPath, resp.StatusCode)
+	}
+	secret := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	err = json.Unmarshal(body, &secret)
+	if err != nil {
+		return "", networkErrorf("resolving vault secret %s failed: %v", secretPath, err)
+	}
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", configErrorf("vault secret %s has no field %s", secretPath, field)
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+func resolveAwsSecretsManagerSecret(ctx GeneratorContext, ref string) (string, error) {
+	secretId, field, _ := strings.Cut(ref, "#")
+	if secretId == "" {
+		return "", configErrorf("aws-sm reference %s is missing the secret id", ref)
+	}
+	awsPath, err := exec.LookPath("aws")
+	if err != nil {
+		return "", executionErrorf("executing aws failed: executable not found")
+	}
+	stdout, stderr, err := ctx.runCommand(*exec.Command(awsPath, "secretsmanager", "get-secret-value", "--secret-id", secretId, "--query", "SecretString", "--output", "text"))
+	if err != nil {
+		return "", executionErrorf("executing aws failed: %v\n%s", err, string(stderr))
+	}
+	value := strings.TrimSuffix(string(stdout), "\n")
+	if field == "" {
+		return value, nil
+	}
+	fields := map[string]interface{}{}
+	err = json.Unmarshal([]byte(value), &fields)
+	if err != nil {
+		return "", configErrorf("aws secret %s is not a json object: %v", secretId, err)
+	}
+	fieldValue, ok := fields[field]
+	if !ok {
+		return "", configErrorf("aws secret %s has no field %s", secretId, field)
+	}
+	return fmt.Sprintf("%v", fieldValue), nil
+}
+
+func markSecretResources(result GeneratorResult, secrets map[string]string) (*GeneratorResult, error) {
+	needles := []string{}
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		needles = append(needles, secret, base64.StdEncoding.EncodeToString([]byte(secret)))
+	}
+	if len(needles) == 0 {
+		return &result, nil
+	}
+	return transformGeneratorResult(result, func(document *yaml.Node) (bool, error) {
+		if !yamlContainsAny(document, needles) {
+			return false, nil
+		}
+		metadata := yamlMappingEnsure(yamlDocumentRoot(document), "metadata")
+		return yamlMappingSet(yamlMappingEnsure(metadata, "annotations"), encryptAnnotation, "true"), nil
+	})
+}
+
+func yamlContainsAny(node *yaml.Node, needles []string) bool {
+	if node.Kind == yaml.ScalarNode {
+		for _, needle := range needles {
+			if strings.Contains(node.Value, needle) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, child := range node.Content {
+		if yamlContainsAny(child, needles) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSecretValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" || r.URL.Path != "/v1/secret/data/app" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"s3cr3t"},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "token")
+
+	secrets, err := resolveSecretValues(GeneratorContext{}, map[string]string{"auth.password": "vault:secret/data/app#password"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"auth.password": "s3cr3t"}, secrets)
+
+	_, err = resolveSecretValues(GeneratorContext{}, map[string]string{"auth.password": "vault:secret/data/app#missing"})
+	assert.Error(t, err)
+
+	_, err = resolveSecretValues(GeneratorContext{}, map[string]string{"auth.password": "vault:secret/data/other#password"})
+	assert.Error(t, err)
+
+	_, err = resolveSecretValues(GeneratorContext{}, map[string]string{"auth.password": "plain-text"})
+	if assert.Error(t, err) {
+		assert.Equal(t, 2, ExitCode(err))
+	}
+}
+
+func TestMarkSecretResources(t *testing.T) {
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{File: "secret.yaml", Content: mockResource("Secret", "app") + "data:\n  password: czNjcjN0\n"},
+			{File: "config-map.yaml", Content: mockResource("ConfigMap", "app") + "data:\n  url: postgres://app:s3cr3t@db\n"},
+			{File: "service.yaml", Content: mockResource("Service", "app")},
+		},
+	}
+	marked, err := markSecretResources(result, map[string]string{"auth.password": "s3cr3t"})
+	assert.NoError(t, err)
+	assert.Contains(t, marked.Resources[0].Content, "kustomization-generator/encrypt: \"true\"")
+	assert.Contains(t, marked.Resources[1].Content, "kustomization-generator/encrypt: \"true\"")
+	assert.Equal(t, mockResource("Service", "app"), marked.Resources[2].Content)
+}
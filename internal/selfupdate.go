@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+var selfUpdateReleaseUrl = "https://api.github.com/repos/airfocusio/kustomization-generator/releases/latest"
+
+const selfUpdateChecksumsAsset = "checksums.txt"
+
+type SelfUpdateOptions struct {
+	CurrentVersion string
+	Executable     string
+	Key            string
+	CheckOnly      bool
+	Force          bool
+}
+
+type SelfUpdateResult struct {
+	LatestVersion string
+	Available     bool
+	Updated       bool
+}
+
+type githubRelease struct {
+	TagName string               `json:"tag_name"`
+	Assets  []githubReleaseAsset `json:"assets"`
+}
+
+type githubReleaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadUrl string `json:"browser_download_url"`
+}
+
+func (r githubRelease) assetUrl(name string) (string, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.BrowserDownloadUrl, true
+		}
+	}
+	return "", false
+}
+
+func SelfUpdate(updateOpts SelfUpdateOptions, opts RunOptions) (*SelfUpdateResult, error) {
+	ctx, err := newGeneratorContext(".", Config{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	releaseBytes, err := downloadBytes(ctx, selfUpdateReleaseUrl)
+	if err != nil {
+		return nil, err
+	}
+	release := githubRelease{}
+	err = json.Unmarshal(releaseBytes, &release)
+	if err != nil {
+		return nil, networkErrorf("failed to parse release %s: %v", selfUpdateReleaseUrl, err)
+	}
+	latestVersion := strings.TrimPrefix(release.TagName, "v")
+	result := SelfUpdateResult{LatestVersion: latestVersion}
+	latest, err := parseSemver(latestVersion)
+	if err != nil {
+		return nil, networkErrorf("failed to parse release %s: %v", selfUpdateReleaseUrl, err)
+	}
+	current, err := parseSemver(updateOpts.CurrentVersion)
+	if err != nil && !updateOpts.Force {
+		return nil, configErrorf("current version %s is not a release, use --force to update anyway", updateOpts.CurrentVersion)
+	}
+	if err == nil && current.Compare(*latest) >= 0 && !updateOpts.Force {
+		return &result, nil
+	}
+	result.Available = true
+	if updateOpts.CheckOnly {
+		return &result, nil
+	}
+
+	archiveName := fmt.Sprintf("kustomization-generator_%s_%s_%s.tar.gz", latestVersion, runtime.GOOS, runtime.GOARCH)
+	archiveUrl, ok := release.assetUrl(archiveName)
+	if !ok {
+		return nil, networkErrorf("release %s has no asset %s", release.TagName, archiveName)
+	}
+	checksumsUrl, ok := release.assetUrl(selfUpdateChecksumsAsset)
+	if !ok {
+		return nil, networkErrorf("release %s has no asset %s", release.TagName, selfUpdateChecksumsAsset)
+	}
+
+	done := ctx.Phase("self-update download")
+	defer done()
+	checksums, err := downloadBytes(ctx, checksumsUrl)
+	if err != nil {
+		return nil, err
+	}
+	if updateOpts.Key != "" {
+		err = verifySelfUpdateChecksums(ctx, release, checksums, updateOpts.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	expectedChecksum := ""
+	for _, line := range strings.Split(string(checksums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == archiveName {
+			expectedChecksum = strings.ToLower(fields[0])
+		}
+	}
+	if expectedChecksum == "" {
+		return nil, networkErrorf("failed to download %s: no checksum listed", archiveUrl)
+	}
+	archive, err := downloadBytes(ctx, archiveUrl)
+	if err != nil {
+		return nil, err
+	}
+	actualChecksum := sha256.Sum256(archive)
+	if hex.EncodeToString(actualChecksum[:]) != expectedChecksum {
+		return nil, networkErrorf("failed to download %s: checksum mismatch", archiveUrl)
+	}
+	binary := "kustomization-generator"
+	if runtime.GOOS == "windows" {
+		binary = "kustomization-generator.exe"
+	}
+	content, err := extractHelmBinary(archive, binary, false)
+	if err != nil {
+		return nil, fmt.Errorf("extracting %s failed: %v", archiveName, err)
+	}
+	err = replaceExecutable(updateOpts.Executable, content)
+	if err != nil {
+		return nil, fmt.Errorf("replacing executable failed: %v", err)
+	}
+	ctx.log().Info("updated", "version", latestVersion, "path", updateOpts.Executable)
+	result.Updated = true
+	return &result, nil
+}
+
+func verifySelfUpdateChecksums(ctx GeneratorContext, release githubRelease, checksums []byte, key string) error {
+	signatureUrl, ok := release.assetUrl(selfUpdateChecksumsAsset + ".sig")
+	if !ok {
+		return networkErrorf("release %s has no asset %s.sig", release.TagName, selfUpdateChecksumsAsset)
+	}
+	signature, err := downloadBytes(ctx, signatureUrl)
+	if err != nil {
+		return err
+	}
+	tempDir, err := os.MkdirTemp(ctx.TempDir, ".kustomization-generator-*-self-update")
+	if err != nil {
+		return fmt.Errorf("verifying signature failed: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	checksumsFile := filepath.Join(tempDir, selfUpdateChecksumsAsset)
+	signatureFile := checksumsFile + ".sig"
+	err = os.WriteFile(checksumsFile, checksums, 0o600)
+	if err == nil {
+		err = os.WriteFile(signatureFile, signature, 0o600)
+	}
+	if err != nil {
+		return fmt.Errorf("verifying signature failed: %v", err)
+	}
+	_, stderr, err := ctx.runCommand(*exec.Command("cosign", "verify-blob", "--key", key, "--signature", signatureFile, checksumsFile))
+	if err != nil {
+		return validationErrorf("verifying signature of %s failed: %v\n%s", selfUpdateChecksumsAsset, err, string(stderr))
+	}
+	return nil
+}
+
+func replaceExecutable(executable string, content []byte) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(executable), ".kustomization-generator-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	_, err = tempFile.Write(content)
+	tempFile.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tempFile.Name(), 0o755)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		old := executable + ".old"
+		_ = os.Remove(old)
+		err = os.Rename(executable, old)
+		if err != nil {
+			return err
+		}
+	}
+	return os.Rename(tempFile.Name(), executable)
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfUpdate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("replacing executables is not tested on windows")
+	}
+	binary := []byte("#!/bin/sh\necho 1.2.0\n")
+	archive := bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "kustomization-generator", Mode: 0o755, Size: int64(len(binary))}))
+	_, _ = tarWriter.Write(binary)
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+	checksum := sha256.Sum256(archive.Bytes())
+	archiveName := fmt.Sprintf("kustomization-generator_1.2.0_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/release":
+			fmt.Fprintf(w, `{"tag_name":"v1.2.0","assets":[{"name":%q,"browser_download_url":"%s/archive"},{"name":"checksums.txt","browser_download_url":"%s/checksums"}]}`, archiveName, server.URL, server.URL)
+		case "/archive":
+			_, _ = w.Write(archive.Bytes())
+		case "/checksums":
+			fmt.Fprintf(w, "%s  %s\n", hex.EncodeToString(checksum[:]), archiveName)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	previousUrl := selfUpdateReleaseUrl
+	selfUpdateReleaseUrl = server.URL + "/release"
+	defer func() { selfUpdateReleaseUrl = previousUrl }()
+
+	executable := filepath.Join(t.TempDir(), "kustomization-generator")
+	assert.NoError(t, os.WriteFile(executable, []byte("old"), 0o755))
+
+	result, err := SelfUpdate(SelfUpdateOptions{CurrentVersion: "1.2.0", Executable: executable}, RunOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, SelfUpdateResult{LatestVersion: "1.2.0"}, *result)
+
+	result, err = SelfUpdate(SelfUpdateOptions{CurrentVersion: "1.1.0", Executable: executable, CheckOnly: true}, RunOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, SelfUpdateResult{LatestVersion: "1.2.0", Available: true}, *result)
+
+	_, err = SelfUpdate(SelfUpdateOptions{CurrentVersion: "dev", Executable: executable}, RunOptions{})
+	assert.Error(t, err)
+
+	result, err = SelfUpdate(SelfUpdateOptions{CurrentVersion: "1.1.0", Executable: executable}, RunOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, SelfUpdateResult{LatestVersion: "1.2.0", Available: true, Updated: true}, *result)
+	content, err := os.ReadFile(executable)
+	assert.NoError(t, err)
+	assert.Equal(t, binary, content)
+	info, err := os.Stat(executable)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+}
@@ -0,0 +1,252 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type semver struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+}
+
+var semverRegex = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+func parseSemver(version string) (*semver, error) {
+	match := semverRegex.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return nil, fmt.Errorf("invalid version %s", version)
+	}
+	result := semver{}
+	result.Major, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		result.Minor, _ = strconv.Atoi(match[2])
+	}
+	if match[3] != "" {
+		result.Patch, _ = strconv.Atoi(match[3])
+	}
+	if match[4] != "" {
+		result.Prerelease = strings.Split(match[4], ".")
+	}
+	return &result, nil
+}
+
+func (v semver) Compare(other semver) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	if len(v.Prerelease) == 0 && len(other.Prerelease) > 0 {
+		return 1
+	}
+	if len(v.Prerelease) > 0 && len(other.Prerelease) == 0 {
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		a, b := v.Prerelease[i], other.Prerelease[i]
+		if a == b {
+			continue
+		}
+		aNum, aErr := strconv.Atoi(a)
+		bNum, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case a < b:
+			return -1
+		default:
+			return 1
+		}
+	}
+	if len(v.Prerelease) < len(other.Prerelease) {
+		return -1
+	}
+	if len(v.Prerelease) > len(other.Prerelease) {
+		return 1
+	}
+	return 0
+}
+
+func (v semver) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+type semverRange struct {
+	Lower          *semver
+	LowerInclusive bool
+	Upper          *semver
+	UpperInclusive bool
+	Exclude        *semver
+}
+
+type semverConstraint [][]semverRange
+
+var semverConstraintRegex = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~|\^)?\s*v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?$`)
+
+func isSemverConstraint(version string) bool {
+	if version == "" {
+		return false
+	}
+	match := semverRegex.FindStringSubmatch(strings.TrimSpace(version))
+	if match != nil && match[2] != "" && match[3] != "" {
+		return false
+	}
+	_, err := parseSemverConstraint(version)
+	return err == nil
+}
+
+func parseSemverConstraint(constraint string) (semverConstraint, error) {
+	result := semverConstraint{}
+	for _, alternative := range strings.Split(constraint, "||") {
+		ranges := []semverRange{}
+		fields := strings.Fields(strings.ReplaceAll(alternative, ",", " "))
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			if (field == "=" || field == "!=" || field == ">=" || field == "<=" || field == ">" || field == "<" || field == "~" || field == "^") && i+1 < len(fields) {
+				field = field + fields[i+1]
+				i++
+			}
+			r, err := parseSemverRange(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint %s: %v", constraint, err)
+			}
+			ranges = append(ranges, *r)
+		}
+		if len(ranges) == 0 {
+			ranges = append(ranges, semverRange{})
+		}
+		result = append(result, ranges)
+	}
+	return result, nil
+}
+
+func parseSemverRange(s string) (*semverRange, error) {
+	match := semverConstraintRegex.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("unable to parse %s", s)
+	}
+	op := match[1]
+	version := semver{}
+	parts := 0
+	for i, part := range match[2:5] {
+		if part == "" || part == "x" || part == "X" || part == "*" {
+			break
+		}
+		value, _ := strconv.Atoi(part)
+		switch i {
+		case 0:
+			version.Major = value
+		case 1:
+			version.Minor = value
+		case 2:
+			version.Patch = value
+		}
+		parts++
+	}
+	if match[5] != "" {
+		version.Prerelease = strings.Split(match[5], ".")
+	}
+	next := func(parts int) *semver {
+		switch parts {
+		case 0:
+			return nil
+		case 1:
+			return &semver{Major: version.Major + 1}
+		case 2:
+			return &semver{Major: version.Major, Minor: version.Minor + 1}
+		default:
+			return &semver{Major: version.Major, Minor: version.Minor, Patch: version.Patch + 1}
+		}
+	}
+	lower := version
+	switch op {
+	case "", "=":
+		if parts == 3 {
+			return &semverRange{Lower: &lower, LowerInclusive: true, Upper: &lower, UpperInclusive: true}, nil
+		}
+		if parts == 0 {
+			return &semverRange{}, nil
+		}
+		return &semverRange{Lower: &lower, LowerInclusive: true, Upper: next(parts)}, nil
+	case "!=":
+		return &semverRange{Exclude: &lower}, nil
+	case ">":
+		if parts < 3 {
+			return &semverRange{Lower: next(parts), LowerInclusive: true}, nil
+		}
+		return &semverRange{Lower: &lower}, nil
+	case ">=":
+		return &semverRange{Lower: &lower, LowerInclusive: true}, nil
+	case "<":
+		return &semverRange{Upper: &lower}, nil
+	case "<=":
+		if parts < 3 {
+			return &semverRange{Upper: next(parts)}, nil
+		}
+		return &semverRange{Upper: &lower, UpperInclusive: true}, nil
+	case "~":
+		if parts <= 1 {
+			return &semverRange{Lower: &lower, LowerInclusive: true, Upper: next(parts)}, nil
+		}
+		return &semverRange{Lower: &lower, LowerInclusive: true, Upper: next(2)}, nil
+	default:
+		switch {
+		case version.Major > 0 || parts <= 1:
+			return &semverRange{Lower: &lower, LowerInclusive: true, Upper: next(1)}, nil
+		case version.Minor > 0 || parts == 2:
+			return &semverRange{Lower: &lower, LowerInclusive: true, Upper: next(2)}, nil
+		default:
+			return &semverRange{Lower: &lower, LowerInclusive: true, Upper: next(3)}, nil
+		}
+	}
+}
+
+func (r semverRange) Matches(v semver) bool {
+	if r.Exclude != nil && v.Compare(*r.Exclude) == 0 {
+		return false
+	}
+	if r.Lower != nil {
+		c := v.Compare(*r.Lower)
+		if c < 0 || (c == 0 && !r.LowerInclusive) {
+			return false
+		}
+	}
+	if r.Upper != nil {
+		c := v.Compare(*r.Upper)
+		if c > 0 || (c == 0 && !r.UpperInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c semverConstraint) Matches(v semver) bool {
+	for _, ranges := range c {
+		matches := true
+		for _, r := range ranges {
+			if !r.Matches(v) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
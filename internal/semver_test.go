@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemverCompare(t *testing.T) {
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"v1.0.1",
+		"1.2",
+		"2.0.0+build.1",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, err := parseSemver(ordered[i])
+			assert.NoError(t, err)
+			b, err := parseSemver(ordered[j])
+			assert.NoError(t, err)
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			assert.Equal(t, expected, a.Compare(*b), "%s <=> %s", ordered[i], ordered[j])
+		}
+	}
+
+	_, err := parseSemver("latest")
+	assert.Error(t, err)
+}
+
+func TestIsSemverConstraint(t *testing.T) {
+	assert.False(t, isSemverConstraint(""))
+	assert.False(t, isSemverConstraint("1.2.3"))
+	assert.False(t, isSemverConstraint("v1.2.3-rc.1"))
+	assert.False(t, isSemverConstraint("not-semver"))
+	assert.True(t, isSemverConstraint("1.2"))
+	assert.True(t, isSemverConstraint("1.2.x"))
+	assert.True(t, isSemverConstraint("^1.2.3"))
+	assert.True(t, isSemverConstraint(">=1.2.0 <2.0.0"))
+}
+
+func TestSemverConstraintMatches(t *testing.T) {
+	cases := []struct {
+		constraint string
+		matches    []string
+		misses     []string
+	}{
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.4", "1.2.3-rc.1"}},
+		{"1.2.x", []string{"1.2.0", "1.2.9"}, []string{"1.3.0", "1.1.9"}},
+		{"1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{"*", []string{"0.0.1", "9.9.9"}, []string{}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"2.0.0", "1.2.2"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{">=1.2.0 <2.0.0", []string{"1.2.0", "1.9.9"}, []string{"2.0.0", "1.1.9"}},
+		{">= 1.2, < 2", []string{"1.2.0"}, []string{"2.0.0"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"^1.0.0 != 1.1.0", []string{"1.0.0", "1.2.0"}, []string{"1.1.0"}},
+		{"1.x || >=3.0.0", []string{"1.5.0", "3.1.0"}, []string{"2.0.0"}},
+		{">=1.2.0-rc.1", []string{"1.2.0-rc.2", "1.2.0"}, []string{"1.2.0-rc.0"}},
+	}
+	for _, c := range cases {
+		constraint, err := parseSemverConstraint(c.constraint)
+		if !assert.NoError(t, err, c.constraint) {
+			continue
+		}
+		for _, version := range c.matches {
+			v, err := parseSemver(version)
+			assert.NoError(t, err)
+			assert.True(t, constraint.Matches(*v), "%s should match %s", c.constraint, version)
+		}
+		for _, version := range c.misses {
+			v, err := parseSemver(version)
+			assert.NoError(t, err)
+			assert.False(t, constraint.Matches(*v), "%s should not match %s", c.constraint, version)
+		}
+	}
+
+	_, err := parseSemverConstraint(">=foo")
+	assert.Error(t, err)
+}
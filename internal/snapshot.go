@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type FileChange struct {
+	File      string
+	Change    string
+	Committed []byte
+	Rendered  []byte
+}
+
+func CompareSnapshot(dir string, update bool, opts RunOptions) ([]FileChange, error) {
+	ctx, files, err := renderSnapshot(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := compareOutput(NewDirFS(dir), files)
+	if err != nil {
+		return nil, err
+	}
+	if update && len(changes) > 0 {
+		stats, err := syncFiles(NewDirFS(dir), files, outputModes{})
+		if err != nil {
+			return nil, err
+		}
+		ctx.log().Info("snapshot updated", "written", stats.Written, "removed", stats.Removed)
+	}
+	return changes, nil
+}
+
+func renderSnapshot(dir string, opts RunOptions) (*GeneratorContext, map[string][]byte, error) {
+	ctx, config, result, err := render(dir, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	writeOpts := newWriteOptions(*config)
+	if config.Metadata {
+		report := newMetadataReport(*config, opts.ToolVersion, *result, generationTime())
+		writeOpts.Metadata = &report
+		writeOpts.PreviousMetadata, err = readMetadataReport(os.DirFS(dir))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	files, err := renderOutput(*result, writeOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, files, nil
+}
+
+func compareOutput(fsys fs.FS, files map[string][]byte) ([]FileChange, error) {
+	ignore, err := readGeneratorIgnore(fsys)
+	if err != nil {
+		return nil, err
+	}
+	existing, _, err := listOutputFiles(fsys, ignore)
+	if err != nil {
+		return nil, err
+	}
+	changes := []FileChange{}
+	seen := map[string]bool{}
+	for _, name := range existing {
+		seen[name] = true
+		if name == metadataFile {
+			continue
+		}
+		committed, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		rendered, ok := files[name]
+		if !ok {
+			changes = append(changes, FileChange{File: name, Change: "removed", Committed: committed})
+		} else if !bytes.Equal(committed, rendered) {
+			changes = append(changes, FileChange{File: name, Change: "changed", Committed: committed, Rendered: rendered})
+		}
+	}
+	for name, rendered := range files {
+		if !seen[name] && name != metadataFile && !isIgnoredFile(name, ignore) {
+			changes = append(changes, FileChange{File: name, Change: "added", Rendered: rendered})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].File < changes[j].File
+	})
+	return changes, nil
+}
+
+func FormatFileChanges(dir string, changes []FileChange) string {
+	result := strings.Builder{}
+	for _, change := range changes {
+		file := path.Join(filepath.ToSlash(dir), change.File)
+		result.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", file, file))
+		result.WriteString(unifiedDiff(string(change.Committed), string(change.Rendered)))
+	}
+	return result.String()
+}
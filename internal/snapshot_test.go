@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareOutput(t *testing.T) {
+	fsys := NewMemoryFS()
+	assert.NoError(t, fsys.WriteFile(configFile, []byte("type: download\n"), 0o644))
+	assert.NoError(t, fsys.WriteFile("kustomization.yaml", []byte("resources: []\n"), 0o644))
+	assert.NoError(t, fsys.WriteFile("stale.yaml", []byte("stale"), 0o644))
+	assert.NoError(t, fsys.WriteFile("same.yaml", []byte("same"), 0o644))
+	assert.NoError(t, fsys.WriteFile(metadataFile, []byte("generatedAt: yesterday"), 0o644))
+
+	changes, err := compareOutput(fsys, map[string][]byte{
+		"kustomization.yaml":        []byte("resources:\n  - resources\n"),
+		"same.yaml":                 []byte("same"),
+		"resources/new-secret.yaml": []byte("new"),
+		metadataFile:                []byte("generatedAt: today"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []FileChange{
+		{File: "kustomization.yaml", Change: "changed", Committed: []byte("resources: []\n"), Rendered: []byte("resources:\n  - resources\n")},
+		{File: "resources/new-secret.yaml", Change: "added", Rendered: []byte("new")},
+		{File: "stale.yaml", Change: "removed", Committed: []byte("stale")},
+	}, changes)
+
+	changes, err = compareOutput(NewDirFS(filepath.Join(t.TempDir(), "missing")), map[string][]byte{"kustomization.yaml": []byte("")})
+	assert.NoError(t, err)
+	assert.Equal(t, []FileChange{{File: "kustomization.yaml", Change: "added", Rendered: []byte("")}}, changes)
+}
+
+func TestFormatFileChanges(t *testing.T) {
+	assert.Equal(t, "--- a/vendors/app/kustomization.yaml\n+++ b/vendors/app/kustomization.yaml\n@@ -1,1 +1,2 @@\n-resources: []\n+resources:\n+  - resources\n--- a/vendors/app/stale.yaml\n+++ b/vendors/app/stale.yaml\n@@ -1,1 +0,0 @@\n-stale\n", FormatFileChanges("vendors/app", []FileChange{
+		{File: "kustomization.yaml", Change: "changed", Committed: []byte("resources: []\n"), Rendered: []byte("resources:\n  - resources\n")},
+		{File: "stale.yaml", Change: "removed", Committed: []byte("stale")},
+	}))
+}
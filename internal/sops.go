@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultSopsEncryptedRegex = "^(data|stringData)$"
+
+type SopsConfig struct {
+	Age            []string `yaml:"age"`
+	EncryptedRegex string   `yaml:"encryptedRegex"`
+}
+
+func encryptSecretsWithSops(ctx GeneratorContext, result GeneratorResult, config SopsConfig) (*GeneratorResult, error) {
+	if len(config.Age) == 0 {
+		return nil, configErrorf("sops is missing age recipients")
+	}
+	sopsPath, err := exec.LookPath("sops")
+	if err != nil {
+		return nil, executionErrorf("executing sops failed: executable not found")
+	}
+	encryptedRegex := config.EncryptedRegex
+	if encryptedRegex == "" {
+		encryptedRegex = defaultSopsEncryptedRegex
+	}
+	args := []string{
+		"--encrypt",
+		"--age", strings.Join(config.Age, ","),
+		"--encrypted-regex", encryptedRegex,
+		"--input-type", "yaml",
+		"--output-type", "yaml",
+		"/dev/stdin",
+	}
+
+	contents, err := loadExistingResources(ctx.Dir)
+	if err != nil {
+		return nil, err
+	}
+	existing := sopsSecretsByIdentity(contents)
+
+	done := ctx.Phase("encrypting secrets")
+	defer done()
+	return mapGeneratorResources(result, func(resource GeneratorResource) (GeneratorResource, error) {
+		if resource.ApiVersion != "v1" || resource.Kind != "Secret" {
+			return resource, nil
+		}
+		if id, err := identifyResource(resource); err == nil {
+			if content, ok := existing[id.String()]; ok && config.matchesEncryption(content, encryptedRegex) {
+				plaintext, err := decryptSops(ctx, sopsPath, content)
+				if err != nil {
+					ctx.log().Warn("decrypting existing secret failed, encrypting again", "file", resource.File, "error", err)
+				} else if sameYamlContent(plaintext, resource.Content) {
+					if reused, ok := reuseEncryptedSecret(content, resource.File); ok {
+						return *reused, nil
+					}
+				}
+			}
+		}
+		cmd := exec.Command(sopsPath, args...)
+		cmd.Stdin = bytes.NewReader([]byte(resource.Content))
+		stdout, stderr, err := ctx.runCommand(*cmd)
+		if err != nil {
+			return resource, executionErrorf("executing sops for %s failed: %v\n%s", resource.File, err, string(stderr))
+		}
+		resource.Content = string(stdout)
+		return resource, nil
+	})
+}
+
+func (config SopsConfig) matchesEncryption(content string, encryptedRegex string) bool {
+	encrypted := struct {
+		Sops struct {
+			EncryptedRegex string `yaml:"encrypted_regex"`
+			Age            []struct {
+				Recipient string `yaml:"recipient"`
+			} `yaml:"age"`
+		} `yaml:"sops"`
+	}{}
+	if yaml.Unmarshal([]byte(content), &encrypted) != nil || encrypted.Sops.EncryptedRegex != encryptedRegex {
+		return false
+	}
+	recipients := []string{}
+	for _, age := range encrypted.Sops.Age {
+		recipients = append(recipients, age.Recipient)
+	}
+	expected := append([]string{}, config.Age...)
+	sort.Strings(recipients)
+	sort.Strings(expected)
+	return strings.Join(recipients, ",") == strings.Join(expected, ",")
+}
+
+func decryptSops(ctx GeneratorContext, sopsPath string, content string) (string, error) {
+	cmd := exec.Command(sopsPath, "--decrypt", "--input-type", "yaml", "--output-type", "yaml", "/dev/stdin")
+	cmd.Stdin = bytes.NewReader([]byte(content))
+	stdout, stderr, err := ctx.runCommand(*cmd)
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(string(stderr)))
+	}
+	return string(stdout), nil
+}
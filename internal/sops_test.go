@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fakeSopsScript = `#!/bin/sh
+if [ "$1" = --decrypt ]; then
+  if [ -e "$(dirname "$0")/decrypt-fail" ]; then echo "no age identity found" >&2; exit 1; fi
+  sed '/^sops:/,$d'
+  exit 0
+fi
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --age) age="$2"; shift ;;
+    --encrypted-regex) regex="$2"; shift ;;
+  esac
+  shift
+done
+cat
+echo "sops:"
+echo "  age:"
+for recipient in $(echo "$age" | tr ',' ' '); do echo "  - recipient: $recipient"; done
+echo "  encrypted_regex: $regex"
+echo "  lastmodified: \"$$\""
+`
+
+func TestEncryptSecretsWithSops(t *testing.T) {
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	script := "#!/bin/sh\necho \"$*\" > \"" + argsFile + "\"\ncat\necho \"sops:\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "sops"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "app-secret.yaml", Content: mockResource("Secret", "app")},
+			{ApiVersion: "v1", Kind: "ConfigMap", File: "app-configmap.yaml", Content: mockResource("ConfigMap", "app")},
+		},
+	}
+	encrypted, err := encryptSecretsWithSops(GeneratorContext{}, result, SopsConfig{Age: []string{"age1a", "age1b"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "app-secret.yaml", encrypted.Resources[0].File)
+		assert.Equal(t, mockResource("Secret", "app")+"sops:\n", encrypted.Resources[0].Content)
+		args, _ := os.ReadFile(argsFile)
+		assert.Equal(t, "--encrypt --age age1a,age1b --encrypted-regex ^(data|stringData)$ --input-type yaml --output-type yaml /dev/stdin", strings.TrimSpace(string(args)))
+		assert.Equal(t, result.Resources[1], encrypted.Resources[1])
+	}
+
+	_, err = encryptSecretsWithSops(GeneratorContext{}, result, SopsConfig{})
+	assert.Error(t, err)
+}
+
+func TestEncryptSecretsWithSopsReusesUnchangedSecrets(t *testing.T) {
+	bin := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "sops"), []byte(fakeSopsScript), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	ctx := GeneratorContext{Dir: dir}
+	config := SopsConfig{Age: []string{"age1a"}}
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			{ApiVersion: "v1", Kind: "Secret", File: "app-secret.yaml", Content: mockResource("Secret", "app") + "stringData:\n  password: a\n"},
+		},
+	}
+	encrypted, err := encryptSecretsWithSops(ctx, result, config)
+	assert.NoError(t, err)
+	assert.NotContains(t, encrypted.Resources[0].Content, "annotations")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app-secret.yaml"), []byte(encrypted.Resources[0].Content), 0o644))
+
+	reencrypted, err := encryptSecretsWithSops(ctx, result, config)
+	if assert.NoError(t, err) {
+		assert.Equal(t, encrypted.Resources[0], reencrypted.Resources[0])
+	}
+
+	changedPlaintext := GeneratorResult{Resources: []GeneratorResource{result.Resources[0]}}
+	changedPlaintext.Resources[0].Content = strings.Replace(result.Resources[0].Content, "password: a", "password: b", 1)
+	changed, err := encryptSecretsWithSops(ctx, changedPlaintext, config)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, encrypted.Resources[0].Content, changed.Resources[0].Content)
+	}
+
+	changed, err = encryptSecretsWithSops(ctx, result, SopsConfig{Age: []string{"age1a", "age1b"}})
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, encrypted.Resources[0].Content, changed.Resources[0].Content)
+	}
+
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "decrypt-fail"), nil, 0o644))
+	changed, err = encryptSecretsWithSops(ctx, result, config)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, encrypted.Resources[0].Content, changed.Resources[0].Content)
+	}
+}
+
+func TestSealAndSopsAreMutuallyExclusive(t *testing.T) {
+	_, err := parseConfig([]byte("type: helm\nseal:\n  cert: cert.pem\nsops:\n  age: [age1a]\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "mutually exclusive")
+	}
+}
@@ -0,0 +1,21 @@
+package internal
+
+type SortOptions struct {
+	Order             string             `yaml:"order"`
+	LegacySortOptions *LegacySortOptions `yaml:"legacySortOptions,omitempty"`
+}
+
+type LegacySortOptions struct {
+	OrderFirst []string `yaml:"orderFirst,omitempty"`
+	OrderLast  []string `yaml:"orderLast,omitempty"`
+}
+
+func validateSortOptions(opts SortOptions) error {
+	if opts.Order != "legacy" && opts.Order != "fifo" {
+		return configErrorf("unsupported sortOptions order %s (expected legacy or fifo)", opts.Order)
+	}
+	if opts.LegacySortOptions != nil && opts.Order != "legacy" {
+		return configErrorf("sortOptions legacySortOptions require order legacy")
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigSortOptions(t *testing.T) {
+	config, err := LoadConfigFromReader(strings.NewReader("type: download\nurl: https://domain.com/manifest.yaml\nsortOptions:\n  order: legacy\n  legacySortOptions:\n    orderFirst: [Namespace]\n    orderLast: [ValidatingWebhookConfiguration]\n"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, &SortOptions{Order: "legacy", LegacySortOptions: &LegacySortOptions{OrderFirst: []string{"Namespace"}, OrderLast: []string{"ValidatingWebhookConfiguration"}}}, config.SortOptions)
+	}
+
+	_, err = LoadConfigFromReader(strings.NewReader("type: download\nurl: https://domain.com/manifest.yaml\nsortOptions:\n  order: random\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unsupported sortOptions order random")
+	}
+
+	_, err = LoadConfigFromReader(strings.NewReader("type: download\nurl: https://domain.com/manifest.yaml\nsortOptions:\n  order: fifo\n  legacySortOptions:\n    orderFirst: [Namespace]\n"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "require order legacy")
+	}
+}
+
+func TestWriteSortOptions(t *testing.T) {
+	fsys := NewMemoryFS()
+	_, err := write(fsys, GeneratorResult{}, writeOptions{SortOptions: &SortOptions{Order: "fifo"}})
+	assert.NoError(t, err)
+	content, err := fs.ReadFile(fsys, "kustomization.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "resources:\n  - crds\n  - namespaces\n  - resources\nsortOptions:\n  order: fifo\n", string(content))
+}
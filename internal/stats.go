@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+type ChartStats struct {
+	Dir       string
+	Chart     string
+	Version   string
+	Resources int
+	Kinds     map[string]int
+	Size      int
+	Added     int
+	Removed   int
+	Changed   int
+}
+
+type OutputStats struct {
+	mu     sync.Mutex
+	Charts []ChartStats
+}
+
+func NewOutputStats() *OutputStats {
+	return &OutputStats{Charts: []ChartStats{}}
+}
+
+func (s *OutputStats) record(dir string, charts []ChartStats) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, chart := range charts {
+		chart.Dir = path.Join(dir, chart.Dir)
+		s.Charts = append(s.Charts, chart)
+	}
+}
+
+func collectChartStats(fsys fs.FS, result GeneratorResult, files map[string][]byte) ([]ChartStats, error) {
+	charts := []ChartStats{}
+	index := map[string]int{}
+	var collect func(dir string, result GeneratorResult)
+	collect = func(dir string, result GeneratorResult) {
+		if len(result.Resources) > 0 || result.Source != nil {
+			chart := ChartStats{Dir: dir, Kinds: map[string]int{}}
+			if result.Source != nil {
+				chart.Chart = result.Source.Chart
+				chart.Version = result.Source.Version
+			}
+			for _, resource := range result.Resources {
+				chart.Resources++
+				chart.Kinds[resource.Kind]++
+				chart.Size += len(resource.Content)
+			}
+			index[dir] = len(charts)
+			charts = append(charts, chart)
+		}
+		for _, child := range result.Children {
+			collect(path.Join(dir, child.Dir), child.Result)
+		}
+	}
+	collect(".", result)
+
+	changes, err := compareOutput(fsys, files)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		if path.Base(change.File) == "kustomization.yaml" {
+			continue
+		}
+		bucket := path.Dir(change.File)
+		if name := path.Base(bucket); name != "crds" && name != "namespaces" && name != "resources" {
+			continue
+		}
+		dir := path.Dir(bucket)
+		i, ok := index[dir]
+		if !ok {
+			i = len(charts)
+			index[dir] = i
+			charts = append(charts, ChartStats{Dir: dir, Kinds: map[string]int{}})
+		}
+		switch change.Change {
+		case "added":
+			charts[i].Added++
+		case "removed":
+			charts[i].Removed++
+		default:
+			charts[i].Changed++
+		}
+	}
+	return charts, nil
+}
+
+func (s *OutputStats) Write(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIR\tCHART\tRESOURCES\tSIZE\tADDED\tREMOVED\tCHANGED\tKINDS")
+	for _, chart := range s.Charts {
+		name := chart.Chart
+		if chart.Version != "" {
+			name = name + " " + chart.Version
+		}
+		if name == "" {
+			name = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\t%d\t%d\t%s\n", chart.Dir, name, chart.Resources, formatByteSize(chart.Size), chart.Added, chart.Removed, chart.Changed, formatKindCounts(chart.Kinds))
+	}
+	return tw.Flush()
+}
+
+func formatKindCounts(kinds map[string]int) string {
+	names := []string{}
+	for kind := range kinds {
+		names = append(names, kind)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if kinds[names[i]] != kinds[names[j]] {
+			return kinds[names[i]] > kinds[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := []string{}
+	for _, kind := range names {
+		parts = append(parts, fmt.Sprintf("%s %d", kind, kinds[kind]))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatByteSize(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectChartStats(t *testing.T) {
+	chart := func(resources ...GeneratorResource) GeneratorResult {
+		return GeneratorResult{
+			Children: []GeneratorResultChild{
+				{Dir: "app", Result: GeneratorResult{Source: &GeneratorSource{Chart: "app", Version: "1.0.0"}, Resources: resources}},
+			},
+		}
+	}
+	a := GeneratorResource{ApiVersion: "v1", Kind: "ConfigMap", File: "a-configmap.yaml", Content: mockResource("ConfigMap", "a")}
+	b := GeneratorResource{ApiVersion: "v1", Kind: "ConfigMap", File: "b-configmap.yaml", Content: mockResource("ConfigMap", "b")}
+	c := GeneratorResource{ApiVersion: "v1", Kind: "Secret", File: "c-secret.yaml", Content: mockResource("Secret", "c")}
+
+	fsys := NewMemoryFS()
+	_, err := write(fsys, chart(a, b), writeOptions{})
+	assert.NoError(t, err)
+
+	b.Content = b.Content + "data:\n  key: value\n"
+	result := chart(b, c)
+	files, err := renderOutput(result, writeOptions{})
+	assert.NoError(t, err)
+	charts, err := collectChartStats(fsys, result, files)
+	assert.NoError(t, err)
+	assert.Equal(t, []ChartStats{{
+		Dir:       "app",
+		Chart:     "app",
+		Version:   "1.0.0",
+		Resources: 2,
+		Kinds:     map[string]int{"ConfigMap": 1, "Secret": 1},
+		Size:      len(b.Content) + len(c.Content),
+		Added:     1,
+		Removed:   1,
+		Changed:   1,
+	}}, charts)
+
+	stats := NewOutputStats()
+	stats.record("vendors", charts)
+	output := bytes.Buffer{}
+	assert.NoError(t, stats.Write(&output))
+	assert.Contains(t, output.String(), "DIR")
+	assert.Contains(t, output.String(), "vendors/app")
+	assert.Contains(t, output.String(), "app 1.0.0")
+	assert.Contains(t, output.String(), "ConfigMap 1, Secret 1")
+}
+
+func TestFormatByteSize(t *testing.T) {
+	assert.Equal(t, "512 B", formatByteSize(512))
+	assert.Equal(t, "1.5 KiB", formatByteSize(1536))
+	assert.Equal(t, "2.0 MiB", formatByteSize(2<<20))
+}
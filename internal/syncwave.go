@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	argoCDSyncWaveAnnotation = "argocd.argoproj.io/sync-wave"
+	argoCDHookAnnotation     = "argocd.argoproj.io/hook"
+)
+
+var argoCDHookPhases = map[string]int{
+	"PreSync":  -1,
+	"Sync":     0,
+	"Skip":     0,
+	"PostSync": 1,
+	"SyncFail": 2,
+}
+
+type syncOrder struct {
+	Phase int
+	Wave  int
+}
+
+func resourceSyncOrder(resource GeneratorResource) syncOrder {
+	kubernetesResource := KubernetesResource{}
+	err := yaml.Unmarshal([]byte(resource.Content), &kubernetesResource)
+	if err != nil {
+		return syncOrder{}
+	}
+	annotations := kubernetesResource.Metadata.Annotations
+	result := syncOrder{}
+	if wave, err := strconv.Atoi(strings.TrimSpace(annotations[argoCDSyncWaveAnnotation])); err == nil {
+		result.Wave = wave
+	}
+	found := false
+	for _, hook := range strings.Split(annotations[argoCDHookAnnotation], ",") {
+		if phase, ok := argoCDHookPhases[strings.TrimSpace(hook)]; ok && (!found || phase < result.Phase) {
+			result.Phase = phase
+			found = true
+		}
+	}
+	return result
+}
+
+func sortResourcesBySyncOrder(resources []GeneratorResource) []GeneratorResource {
+	orders := make([]syncOrder, len(resources))
+	indexes := make([]int, len(resources))
+	for i, resource := range resources {
+		orders[i] = resourceSyncOrder(resource)
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		x, y := orders[indexes[a]], orders[indexes[b]]
+		if x.Phase != y.Phase {
+			return x.Phase < y.Phase
+		}
+		return x.Wave < y.Wave
+	})
+	result := make([]GeneratorResource, len(resources))
+	for i, index := range indexes {
+		result[i] = resources[index]
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func syncWaveResource(name string, annotations string) GeneratorResource {
+	return GeneratorResource{
+		ApiVersion: "v1",
+		Kind:       "ConfigMap",
+		File:       name + "-configmap.yaml",
+		Content:    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n  annotations:\n" + annotations,
+	}
+}
+
+func TestSortResourcesBySyncOrder(t *testing.T) {
+	plain := syncWaveResource("plain", "    foo: bar\n")
+	late := syncWaveResource("late", "    argocd.argoproj.io/sync-wave: \"5\"\n")
+	early := syncWaveResource("early", "    argocd.argoproj.io/sync-wave: \"-1\"\n")
+	preSync := syncWaveResource("pre-sync", "    argocd.argoproj.io/hook: PreSync\n    argocd.argoproj.io/sync-wave: \"10\"\n")
+	postSync := syncWaveResource("post-sync", "    argocd.argoproj.io/hook: PostSync\n")
+	both := syncWaveResource("both", "    argocd.argoproj.io/hook: Sync,PostSync\n")
+	invalid := syncWaveResource("invalid", "    argocd.argoproj.io/sync-wave: abc\n")
+
+	sorted := sortResourcesBySyncOrder([]GeneratorResource{postSync, late, plain, preSync, invalid, early, both})
+	names := []string{}
+	for _, resource := range sorted {
+		names = append(names, resource.File)
+	}
+	assert.Equal(t, []string{
+		"pre-sync-configmap.yaml",
+		"early-configmap.yaml",
+		"plain-configmap.yaml",
+		"invalid-configmap.yaml",
+		"both-configmap.yaml",
+		"late-configmap.yaml",
+		"post-sync-configmap.yaml",
+	}, names)
+}
+
+func TestRenderFilesOrdersBySyncWave(t *testing.T) {
+	files := map[string][]byte{}
+	result := GeneratorResult{
+		Resources: []GeneratorResource{
+			syncWaveResource("second", "    argocd.argoproj.io/sync-wave: \"2\"\n"),
+			syncWaveResource("first", "    argocd.argoproj.io/sync-wave: \"1\"\n"),
+		},
+	}
+	_, err := renderFiles("", result, files)
+	assert.NoError(t, err)
+	assert.Equal(t, "resources:\n  - first-configmap.yaml\n  - second-configmap.yaml\n", string(files["resources/kustomization.yaml"]))
+}
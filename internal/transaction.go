@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+type transactionEntry struct {
+	name    string
+	existed bool
+	dir     bool
+	content []byte
+	mode    fs.FileMode
+}
+
+type transactionFS struct {
+	OutputFS
+	entries     []transactionEntry
+	touched     map[string]bool
+	createdDirs []string
+}
+
+func newTransactionFS(fsys OutputFS) *transactionFS {
+	return &transactionFS{OutputFS: fsys, touched: map[string]bool{}}
+}
+
+func (t *transactionFS) touch(name string) error {
+	if t.touched[name] {
+		return nil
+	}
+	entry := transactionEntry{name: name}
+	info, err := fs.Stat(t.OutputFS, name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	case info.IsDir():
+		entry.existed = true
+		entry.dir = true
+		entry.mode = info.Mode()
+	default:
+		entry.existed = true
+		entry.mode = info.Mode()
+		entry.content, err = fs.ReadFile(t.OutputFS, name)
+		if err != nil {
+			return err
+		}
+	}
+	t.touched[name] = true
+	t.entries = append(t.entries, entry)
+	return nil
+}
+
+func (t *transactionFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	err := t.touch(name)
+	if err != nil {
+		return err
+	}
+	return t.OutputFS.WriteFile(name, data, perm)
+}
+
+func (t *transactionFS) Remove(name string) error {
+	err := t.touch(name)
+	if err != nil {
+		return err
+	}
+	return t.OutputFS.Remove(name)
+}
+
+func (t *transactionFS) Chmod(name string, mode fs.FileMode) error {
+	err := t.touch(name)
+	if err != nil {
+		return err
+	}
+	return t.OutputFS.Chmod(name, mode)
+}
+
+func (t *transactionFS) MkdirAll(name string, perm fs.FileMode) error {
+	missing := []string{}
+	for dir := name; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if _, err := fs.Stat(t.OutputFS, dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+	}
+	err := t.OutputFS.MkdirAll(name, perm)
+	for _, dir := range missing {
+		if _, statErr := fs.Stat(t.OutputFS, dir); statErr == nil {
+			t.createdDirs = append(t.createdDirs, dir)
+		}
+	}
+	return err
+}
+
+func (t *transactionFS) rollback() error {
+	errs := []string{}
+	for i := len(t.entries) - 1; i >= 0; i-- {
+		entry := t.entries[i]
+		var err error
+		switch {
+		case !entry.existed:
+			err = t.OutputFS.Remove(entry.name)
+			if errors.Is(err, fs.ErrNotExist) {
+				err = nil
+			}
+		case entry.dir:
+			err = t.OutputFS.MkdirAll(entry.name, entry.mode.Perm())
+			if err == nil {
+				err = t.OutputFS.Chmod(entry.name, entry.mode.Perm())
+			}
+		default:
+			err = t.OutputFS.MkdirAll(path.Dir(entry.name), 0o755)
+			if err == nil {
+				err = t.OutputFS.WriteFile(entry.name, entry.content, entry.mode.Perm())
+			}
+			if err == nil {
+				err = t.OutputFS.Chmod(entry.name, entry.mode.Perm())
+			}
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	dirs := append([]string{}, t.createdDirs...)
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], "/") > strings.Count(dirs[j], "/")
+	})
+	for _, dir := range dirs {
+		err := t.OutputFS.Remove(dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingOutputFS struct {
+	*MemoryFS
+	failOn string
+}
+
+func (f failingOutputFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if name == f.failOn {
+		return fmt.Errorf("disk full")
+	}
+	return f.MemoryFS.WriteFile(name, data, perm)
+}
+
+func TestSyncFilesRollsBackOnError(t *testing.T) {
+	memory := NewMemoryFS()
+	_, err := syncFiles(memory, map[string][]byte{
+		"kustomization.yaml":     []byte("old\n"),
+		"resources/a.yaml":       []byte("a\n"),
+		"resources/stale.yaml":   []byte("stale\n"),
+		"resources/unchanged.md": []byte("same\n"),
+	}, outputModes{})
+	assert.NoError(t, err)
+	before := memory.Files()
+
+	_, err = syncFiles(failingOutputFS{MemoryFS: memory, failOn: "resources/z.yaml"}, map[string][]byte{
+		"kustomization.yaml":     []byte("new\n"),
+		"crds/crd.yaml":          []byte("crd\n"),
+		"resources/a.yaml":       []byte("a2\n"),
+		"resources/unchanged.md": []byte("same\n"),
+		"resources/z.yaml":       []byte("z\n"),
+	}, outputModes{})
+	if assert.Error(t, err) {
+		assert.Equal(t, "disk full", err.Error())
+	}
+	assert.Equal(t, before, memory.Files())
+	_, err = fs.Stat(memory, "crds")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestTransactionFSRestoresRemovedFiles(t *testing.T) {
+	memory := NewMemoryFS()
+	assert.NoError(t, memory.MkdirAll("dir", 0o755))
+	assert.NoError(t, memory.WriteFile("dir/file.yaml", []byte("content\n"), 0o600))
+
+	tx := newTransactionFS(memory)
+	assert.NoError(t, tx.Remove("dir/file.yaml"))
+	assert.NoError(t, tx.Remove("dir"))
+	assert.NoError(t, tx.rollback())
+	assert.Equal(t, map[string][]byte{"dir/file.yaml": []byte("content\n")}, memory.Files())
+	info, err := fs.Stat(memory, "dir/file.yaml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, fs.FileMode(0o600), info.Mode().Perm())
+	}
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+func transformGeneratorResult(result GeneratorResult, fn func(document *yaml.Node) (bool, error)) (*GeneratorResult, error) {
+	transformed := result
+	transformed.Resources = nil
+	transformed.Children = nil
+	for _, resource := range result.Resources {
+		document := yaml.Node{}
+		err := yaml.Unmarshal([]byte(resource.Content), &document)
+		if err != nil {
+			return nil, fmt.Errorf("transforming resource %s failed: %v", resource.File, err)
+		}
+		changed, err := fn(&document)
+		if err != nil {
+			return nil, fmt.Errorf("transforming resource %s failed: %w", resource.File, err)
+		}
+		if changed {
+			content, err := writeYaml(&document)
+			if err != nil {
+				return nil, fmt.Errorf("transforming resource %s failed: %v", resource.File, err)
+			}
+			resource.Content = string(content)
+		}
+		transformed.Resources = append(transformed.Resources, resource)
+	}
+	for _, child := range result.Children {
+		childResult, err := transformGeneratorResult(child.Result, fn)
+		if err != nil {
+			return nil, err
+		}
+		child.Result = *childResult
+		transformed.Children = append(transformed.Children, child)
+	}
+	return &transformed, nil
+}
+
+func yamlDocumentRoot(document *yaml.Node) *yaml.Node {
+	if document.Kind == yaml.DocumentNode && len(document.Content) > 0 {
+		return document.Content[0]
+	}
+	return document
+}
+
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func yamlMappingEnsure(node *yaml.Node, key string) *yaml.Node {
+	value := yamlMappingValue(node, key)
+	if value == nil {
+		value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	}
+	return value
+}
+
+func yamlMappingSet(node *yaml.Node, key string, value string) bool {
+	existing := yamlMappingValue(node, key)
+	if existing != nil {
+		if existing.Kind == yaml.ScalarNode && existing.Value == value && existing.Tag == "!!str" {
+			return false
+		}
+		*existing = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+		return true
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+	return true
+}
+
+func yamlMappingRemove(node *yaml.Node, key string) bool {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func walkYamlMetadata(node *yaml.Node, fn func(metadata *yaml.Node) bool) bool {
+	changed := false
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, item := range node.Content {
+			changed = walkYamlMetadata(item, fn) || changed
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value := node.Content[i+1]
+			if node.Content[i].Value == "metadata" && value.Kind == yaml.MappingNode {
+				changed = fn(value) || changed
+			}
+			changed = walkYamlMetadata(value, fn) || changed
+		}
+	}
+	return changed
+}
+
+func mapGeneratorResources(result GeneratorResult, fn func(resource GeneratorResource) (GeneratorResource, error)) (*GeneratorResult, error) {
+	mapped := result
+	mapped.Resources = nil
+	mapped.Children = nil
+	for _, resource := range result.Resources {
+		resource, err := fn(resource)
+		if err != nil {
+			return nil, err
+		}
+		mapped.Resources = append(mapped.Resources, resource)
+	}
+	for _, child := range result.Children {
+		childResult, err := mapGeneratorResources(child.Result, fn)
+		if err != nil {
+			return nil, err
+		}
+		child.Result = *childResult
+		mapped.Children = append(mapped.Children, child)
+	}
+	return &mapped, nil
+}
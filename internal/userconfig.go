@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+type UserConfig struct {
+	CacheDir     string                `yaml:"cacheDir"`
+	TempDir      string                `yaml:"tempDir"`
+	HelmBin      string                `yaml:"helmBin"`
+	Proxy        string                `yaml:"proxy"`
+	CaBundle     string                `yaml:"caBundle"`
+	HelmArgs     []string              `yaml:"helmArgs"`
+	Repositories map[string]Repository `yaml:"repositories"`
+}
+
+func DefaultUserConfigFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "kustomization-helm", "config.yaml"), nil
+}
+
+func LoadUserConfig(file string) (*UserConfig, error) {
+	bytes, err := readConfigFile(file)
+	if os.IsNotExist(err) {
+		return &UserConfig{}, nil
+	}
+	if err != nil {
+		return nil, ClassifiedError{Class: ErrorClassConfig, File: file, Err: fmt.Errorf("unable to load user configuration: %v", err)}
+	}
+	result := UserConfig{}
+	err = readYaml(bytes, &result)
+	if err != nil {
+		return nil, ClassifiedError{Class: ErrorClassConfig, File: file, Err: fmt.Errorf("unable to load user configuration: %v", err)}
+	}
+	if result.Proxy != "" {
+		if _, err := url.Parse(result.Proxy); err != nil {
+			return nil, ClassifiedError{Class: ErrorClassConfig, File: file, Err: fmt.Errorf("invalid proxy %s: %v", result.Proxy, err)}
+		}
+	}
+	return &result, nil
+}
+
+func (c UserConfig) httpTransport() (http.RoundTripper, error) {
+	if c.Proxy == "" && c.CaBundle == "" {
+		return nil, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.Proxy != "" {
+		proxy, err := url.Parse(c.Proxy)
+		if err != nil {
+			return nil, configErrorf("invalid proxy %s: %v", c.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if c.CaBundle != "" {
+		bundle, err := os.ReadFile(c.CaBundle)
+		if err != nil {
+			return nil, configErrorf("reading ca bundle %s failed: %v", c.CaBundle, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, configErrorf("ca bundle %s contains no certificates", c.CaBundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return transport, nil
+}
+
+func mergeRepositories(user map[string]Repository, project map[string]Repository) map[string]Repository {
+	if len(user) == 0 {
+		return project
+	}
+	result := map[string]Repository{}
+	for name, repository := range user {
+		result[name] = repository
+	}
+	for name, repository := range project {
+		if fallback, ok := user[name]; ok && repository.Username == "" && repository.Password == "" {
+			repository.Username = fallback.Username
+			repository.Password = fallback.Password
+		}
+		result[name] = repository
+	}
+	return result
+}
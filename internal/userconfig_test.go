@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadUserConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	config, err := LoadUserConfig(filepath.Join(dir, "missing.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, UserConfig{}, *config)
+
+	t.Setenv("CHARTS_PASSWORD", "secret")
+	file := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("cacheDir: /tmp/cache\nproxy: http://proxy:3128\nhelmArgs: [--skip-tests]\nrepositories:\n  internal:\n    url: https://charts.example.com\n    username: ci\n    password: ${CHARTS_PASSWORD}\n"), 0o644))
+	config, err = LoadUserConfig(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/cache", config.CacheDir)
+	assert.Equal(t, []string{"--skip-tests"}, config.HelmArgs)
+	assert.Equal(t, "secret", config.Repositories["internal"].Password)
+
+	transport, err := config.httpTransport()
+	assert.NoError(t, err)
+	proxy, err := transport.(*http.Transport).Proxy(&http.Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://proxy:3128", proxy.String())
+
+	assert.NoError(t, os.WriteFile(file, []byte("helmArgs: nope: nope"), 0o644))
+	_, err = LoadUserConfig(file)
+	if assert.Error(t, err) {
+		assert.Equal(t, 2, ExitCode(err))
+	}
+}
+
+func TestMergeRepositories(t *testing.T) {
+	user := map[string]Repository{
+		"internal": {Url: "https://charts.example.com", Username: "ci", Password: "secret"},
+		"personal": {Url: "https://charts.personal.dev"},
+	}
+	project := map[string]Repository{
+		"internal": {Url: "https://charts.example.com"},
+		"bitnami":  {Url: "https://charts.bitnami.com/bitnami"},
+	}
+	assert.Equal(t, map[string]Repository{
+		"internal": {Url: "https://charts.example.com", Username: "ci", Password: "secret"},
+		"personal": {Url: "https://charts.personal.dev"},
+		"bitnami":  {Url: "https://charts.bitnami.com/bitnami"},
+	}, mergeRepositories(user, project))
+	assert.Equal(t, project, mergeRepositories(nil, project))
+}
+
+func TestDefaultUserConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	file, err := DefaultUserConfigFile()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".config", "kustomization-helm", "config.yaml"), file)
+}
@@ -3,7 +3,6 @@ package internal
 import (
 	"bytes"
 	"os"
-	"os/exec"
 
 	"gopkg.in/yaml.v3"
 )
@@ -43,12 +42,3 @@ func writeYamlFile(file string, v interface{}) error {
 	}
 	return nil
 }
-
-func runCommand(cmd exec.Cmd) ([]byte, []byte, error) {
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	err := cmd.Run()
-	return stdout.Bytes(), stderr.Bytes(), err
-}
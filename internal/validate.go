@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type ValidationConfig struct {
+	KubernetesVersion    string   `yaml:"kubernetesVersion"`
+	SchemaLocations      []string `yaml:"schemaLocations"`
+	Strict               bool     `yaml:"strict"`
+	IgnoreMissingSchemas bool     `yaml:"ignoreMissingSchemas"`
+	SkipKinds            []string `yaml:"skipKinds"`
+}
+
+type kubeconformOutput struct {
+	Resources []struct {
+		Kind    string `json:"kind"`
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Status  string `json:"status"`
+		Msg     string `json:"msg"`
+	} `json:"resources"`
+}
+
+func (config ValidationConfig) args(dir string) []string {
+	args := []string{
+		"-output", "json",
+	}
+	if config.KubernetesVersion != "" {
+		args = append(args, "-kubernetes-version", strings.TrimPrefix(config.KubernetesVersion, "v"))
+	}
+	for _, schemaLocation := range config.SchemaLocations {
+		if schemaLocation != "default" && !strings.Contains(schemaLocation, "://") {
+			schemaLocation = resolvePath(dir, schemaLocation)
+		}
+		args = append(args, "-schema-location", schemaLocation)
+	}
+	if config.Strict {
+		args = append(args, "-strict")
+	}
+	if config.IgnoreMissingSchemas {
+		args = append(args, "-ignore-missing-schemas")
+	}
+	if len(config.SkipKinds) > 0 {
+		args = append(args, "-skip", strings.Join(config.SkipKinds, ","))
+	}
+	return append(args, "-")
+}
+
+func validateGeneratorResult(ctx GeneratorContext, result GeneratorResult, config ValidationConfig) error {
+	resources := result.AllResources()
+	if len(resources) == 0 {
+		return nil
+	}
+	kubeconformPath, err := exec.LookPath("kubeconform")
+	if err != nil {
+		return executionErrorf("executing kubeconform failed: executable not found")
+	}
+
+	contents := []string{}
+	for _, resource := range resources {
+		contents = append(contents, resource.Content)
+	}
+	cmd := exec.Command(kubeconformPath, config.args(ctx.Dir)...)
+	cmd.Stdin = bytes.NewBufferString(strings.Join(contents, "---\n"))
+	kubeconformStdout, kubeconformStderr, err := ctx.runCommand(*cmd)
+
+	output := kubeconformOutput{}
+	if jsonErr := json.Unmarshal(kubeconformStdout, &output); jsonErr != nil {
+		if err != nil {
+			return executionErrorf("executing kubeconform failed: %v\n%s", err, string(kubeconformStderr))
+		}
+		return fmt.Errorf("parsing kubeconform output failed: %v", jsonErr)
+	}
+	messages := []string{}
+	findings := []ValidationFinding{}
+	files := collectResourceFiles(result)
+	for _, resource := range output.Resources {
+		if resource.Status == "statusInvalid" || resource.Status == "statusError" {
+			message := fmt.Sprintf("%s %s (%s): %s", resource.Kind, resource.Name, resource.Version, resource.Msg)
+			messages = append(messages, message)
+			finding := ValidationFinding{Rule: "validation", Message: message}
+			if file, ok := files[resource.Kind+"/"+resource.Name]; ok {
+				finding.File = findingPath(ctx.Dir, file)
+			} else {
+				finding.File = findingPath(ctx.Dir, configFile)
+				finding.Line = configKeyLine(ctx.Dir, "validate")
+			}
+			findings = append(findings, finding)
+		}
+	}
+	ctx.Findings.record(findings...)
+	if len(messages) > 0 {
+		return validationErrorf("validating resources failed:\n%s", strings.Join(messages, "\n"))
+	}
+	if err != nil {
+		return executionErrorf("executing kubeconform failed: %v\n%s", err, string(kubeconformStderr))
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationConfigArgs(t *testing.T) {
+	assert.Equal(t, []string{"-output", "json", "-"}, ValidationConfig{}.args(""))
+	assert.Equal(t, []string{
+		"-output", "json",
+		"-kubernetes-version", "1.28.0",
+		"-schema-location", "default",
+		"-schema-location", "https://raw.githubusercontent.com/datreeio/CRDs-catalog/main/{{.Group}}/{{.ResourceKind}}_{{.ResourceAPIVersion}}.json",
+		"-schema-location", "/config/schemas/{{.ResourceKind}}.json",
+		"-strict",
+		"-ignore-missing-schemas",
+		"-skip", "CustomResourceDefinition,Secret",
+		"-",
+	}, ValidationConfig{
+		KubernetesVersion:    "v1.28.0",
+		SchemaLocations:      []string{"default", "https://raw.githubusercontent.com/datreeio/CRDs-catalog/main/{{.Group}}/{{.ResourceKind}}_{{.ResourceAPIVersion}}.json", "schemas/{{.ResourceKind}}.json"},
+		Strict:               true,
+		IgnoreMissingSchemas: true,
+		SkipKinds:            []string{"CustomResourceDefinition", "Secret"},
+	}.args("/config"))
+}
+
+func TestValidateGeneratorResult(t *testing.T) {
+	testCases := []struct {
+		name      string
+		stdout    string
+		stderr    string
+		exitCode  int
+		err       string
+		exitClass int
+		findings  []string
+	}{
+		{
+			name: "valid",
+			stdout: `{
+  "resources": [],
+  "summary": {"valid": 2, "invalid": 0, "errors": 0, "skipped": 0}
+}
+`,
+		},
+		{
+			name: "skipped",
+			stdout: `{
+  "resources": [
+    {
+      "filename": "stdin",
+      "kind": "Certificate",
+      "name": "app",
+      "version": "cert-manager.io/v1",
+      "status": "statusSkipped",
+      "msg": ""
+    }
+  ],
+  "summary": {"valid": 1, "invalid": 0, "errors": 0, "skipped": 1}
+}
+`,
+		},
+		{
+			name: "invalid and error",
+			stdout: `{
+  "resources": [
+    {
+      "filename": "stdin",
+      "kind": "Deployment",
+      "name": "app",
+      "version": "apps/v1",
+      "status": "statusInvalid",
+      "msg": "problem validating schema. Check JSON formatting: jsonschema: '/spec/replicas' does not validate with https://raw.githubusercontent.com/yannh/kubernetes-json-schema/master/v1.28.0-standalone-strict/deployment-apps-v1.json#/properties/spec/properties/replicas/type: expected integer or null, but got string"
+    },
+    {
+      "filename": "stdin",
+      "kind": "Certificate",
+      "name": "app",
+      "version": "cert-manager.io/v1",
+      "status": "statusError",
+      "msg": "could not find schema for Certificate"
+    }
+  ],
+  "summary": {"valid": 0, "invalid": 1, "errors": 1, "skipped": 0}
+}
+`,
+			exitCode:  1,
+			err:       "validating resources failed:\nDeployment app (apps/v1): problem validating schema.",
+			exitClass: 5,
+			findings: []string{
+				"Deployment app (apps/v1): problem validating schema. Check JSON formatting: jsonschema: '/spec/replicas' does not validate with https://raw.githubusercontent.com/yannh/kubernetes-json-schema/master/v1.28.0-standalone-strict/deployment-apps-v1.json#/properties/spec/properties/replicas/type: expected integer or null, but got string",
+				"Certificate app (cert-manager.io/v1): could not find schema for Certificate",
+			},
+		},
+		{
+			name:      "invalid flag",
+			stderr:    "flag provided but not defined: -kubernetes-versio\nUsage: kubeconform [OPTION]... [FILE OR FOLDER]...\n",
+			exitCode:  1,
+			err:       "executing kubeconform failed: exit status 1\nflag provided but not defined",
+			exitClass: 4,
+		},
+		{
+			name:   "unexpected output",
+			stdout: "stdin - Deployment app is valid\n",
+			err:    "parsing kubeconform output failed",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			bin := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(bin, "stdout"), []byte(testCase.stdout), 0o644))
+			assert.NoError(t, os.WriteFile(filepath.Join(bin, "stderr"), []byte(testCase.stderr), 0o644))
+			assert.NoError(t, os.WriteFile(filepath.Join(bin, "kubeconform"), []byte("#!/bin/sh\ncat > /dev/null\ncat \""+bin+"/stdout\"\ncat \""+bin+"/stderr\" >&2\nexit "+strconv.Itoa(testCase.exitCode)+"\n"), 0o755))
+			t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			findings := NewValidationFindings()
+			ctx := GeneratorContext{Context: context.Background(), Findings: findings}
+			result := GeneratorResult{Resources: []GeneratorResource{{ApiVersion: "apps/v1", Kind: "Deployment", File: "app-deployment.yaml", Content: mockResource("Deployment", "app")}}}
+			err := validateGeneratorResult(ctx, result, ValidationConfig{})
+			if testCase.err == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), testCase.err)
+				if testCase.exitClass != 0 {
+					assert.Equal(t, testCase.exitClass, ExitCode(err))
+				}
+			}
+			messages := []string{}
+			for _, finding := range findings.Findings {
+				messages = append(messages, finding.Message)
+			}
+			assert.Equal(t, append([]string{}, testCase.findings...), messages)
+		})
+	}
+}
+
+func TestValidateGeneratorResultPassesArgs(t *testing.T) {
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	stdinFile := filepath.Join(bin, "stdin")
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "kubeconform"), []byte("#!/bin/sh\necho \"$@\" > \""+argsFile+"\"\ncat > \""+stdinFile+"\"\necho '{\"resources\":[]}'\n"), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result := GeneratorResult{Resources: []GeneratorResource{
+		{Kind: "ConfigMap", File: "a-configmap.yaml", Content: mockResource("ConfigMap", "a")},
+		{Kind: "ConfigMap", File: "b-configmap.yaml", Content: mockResource("ConfigMap", "b")},
+	}}
+	assert.NoError(t, validateGeneratorResult(GeneratorContext{Context: context.Background()}, result, ValidationConfig{KubernetesVersion: "1.28.0", Strict: true}))
+	args, _ := os.ReadFile(argsFile)
+	assert.Equal(t, "-output json -kubernetes-version 1.28.0 -strict -", strings.TrimSpace(string(args)))
+	stdin, _ := os.ReadFile(stdinFile)
+	assert.Equal(t, mockResource("ConfigMap", "a")+"---\n"+mockResource("ConfigMap", "b"), string(stdin))
+}
@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"os/exec"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func checkHelmValues(ctx GeneratorContext, helmPath string, chartArgs []string, values map[string]interface{}) ([]string, error) {
+	defaultsStdout, defaultsStderr, err := ctx.runCommand(*exec.Command(helmPath, append([]string{"show", "values"}, chartArgs...)...))
+	if err != nil {
+		return nil, executionErrorf("executing helm failed: %v\n%s", err, string(defaultsStderr))
+	}
+	defaults := map[string]interface{}{}
+	err = yaml.Unmarshal(defaultsStdout, &defaults)
+	if err != nil {
+		return nil, executionErrorf("parsing chart default values failed: %v", err)
+	}
+
+	chartStdout, chartStderr, err := ctx.runCommand(*exec.Command(helmPath, append([]string{"show", "chart"}, chartArgs...)...))
+	if err != nil {
+		return nil, executionErrorf("executing helm failed: %v\n%s", err, string(chartStderr))
+	}
+	chart := struct {
+		Dependencies []struct {
+			Name  string `yaml:"name"`
+			Alias string `yaml:"alias"`
+		} `yaml:"dependencies"`
+	}{}
+	err = yaml.Unmarshal(chartStdout, &chart)
+	if err != nil {
+		return nil, executionErrorf("parsing chart metadata failed: %v", err)
+	}
+	subcharts := []string{}
+	for _, dependency := range chart.Dependencies {
+		if dependency.Alias != "" {
+			subcharts = append(subcharts, dependency.Alias)
+		} else {
+			subcharts = append(subcharts, dependency.Name)
+		}
+	}
+
+	return findUnknownValueKeys(values, defaults, subcharts), nil
+}
+
+func findUnknownValueKeys(values map[string]interface{}, defaults map[string]interface{}, subcharts []string) []string {
+	result := []string{}
+	for key, value := range values {
+		if key == "global" {
+			continue
+		}
+		isSubchart := false
+		for _, subchart := range subcharts {
+			if key == subchart {
+				isSubchart = true
+			}
+		}
+		if isSubchart {
+			continue
+		}
+		defaultValue, ok := defaults[key]
+		if !ok {
+			result = append(result, key)
+			continue
+		}
+		result = append(result, prefixValueKeys(key, findUnknownNestedValueKeys(value, defaultValue))...)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func findUnknownNestedValueKeys(value interface{}, defaultValue interface{}) []string {
+	valueMap, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	defaultMap, ok := defaultValue.(map[string]interface{})
+	if !ok || len(defaultMap) == 0 {
+		return nil
+	}
+	result := []string{}
+	for key, nestedValue := range valueMap {
+		nestedDefaultValue, ok := defaultMap[key]
+		if !ok {
+			result = append(result, key)
+			continue
+		}
+		result = append(result, prefixValueKeys(key, findUnknownNestedValueKeys(nestedValue, nestedDefaultValue))...)
+	}
+	return result
+}
+
+func mergeStringValues(values map[string]interface{}, stringValues map[string]string) map[string]interface{} {
+	if len(stringValues) == 0 {
+		return values
+	}
+	result := copyValues(values)
+	for key, value := range stringValues {
+		segments := splitValuePath(key)
+		current := result
+		for _, segment := range segments[:len(segments)-1] {
+			next, ok := current[segment].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				current[segment] = next
+			}
+			current = next
+		}
+		current[segments[len(segments)-1]] = value
+	}
+	return result
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range values {
+		if nested, ok := value.(map[string]interface{}); ok {
+			result[key] = copyValues(nested)
+		} else {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func splitValuePath(key string) []string {
+	result := []string{}
+	current := strings.Builder{}
+	escaped := false
+	for _, r := range key {
+		if escaped {
+			current.WriteRune(r)
+			escaped = false
+		} else if r == '\\' {
+			escaped = true
+		} else if r == '.' {
+			result = append(result, current.String())
+			current.Reset()
+		} else {
+			current.WriteRune(r)
+		}
+	}
+	return append(result, current.String())
+}
+
+func prefixValueKeys(prefix string, keys []string) []string {
+	result := []string{}
+	for _, key := range keys {
+		result = append(result, strings.Join([]string{prefix, key}, "."))
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	ValueDiffChanged   = "changed"
+	ValueDiffUnchanged = "unchanged"
+	ValueDiffAdded     = "added"
+	ValueDiffUnknown   = "unknown"
+)
+
+type ValueDiff struct {
+	Key     string
+	Status  string
+	Value   string
+	Default string
+}
+
+type ChartValuesDiff struct {
+	Chart   string
+	Version string
+	Values  []ValueDiff
+}
+
+func ValuesDiff(dir string, opts RunOptions) ([]ChartValuesDiff, error) {
+	config, err := loadRunConfig(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	generators := collectHelmGenerators(config.Generator)
+	if len(generators) == 0 {
+		return nil, configErrorf("diffing values is only supported for helm charts")
+	}
+	ctx, err := newGeneratorContext(dir, *config, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := []ChartValuesDiff{}
+	for _, generator := range generators {
+		err := generator.withChartDir(ctx, func(chartDir string, source GeneratorSource) error {
+			defaults := map[string]interface{}{}
+			err := readYamlFile(filepath.Join(chartDir, "values.yaml"), &defaults)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("reading chart values failed: %v", err)
+			}
+			chart, err := readHelmLocalChart(chartDir)
+			if err != nil {
+				return fmt.Errorf("reading chart metadata failed: %v", err)
+			}
+			subcharts := []string{}
+			for _, dependency := range chart.Dependencies {
+				if dependency.Alias != "" {
+					subcharts = append(subcharts, dependency.Alias)
+				} else {
+					subcharts = append(subcharts, dependency.Name)
+				}
+			}
+			result = append(result, ChartValuesDiff{
+				Chart:   source.Chart,
+				Version: source.Version,
+				Values:  diffChartValues(generator.Values, defaults, subcharts),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func diffChartValues(values map[string]interface{}, defaults map[string]interface{}, subcharts []string) []ValueDiff {
+	result := []ValueDiff{}
+	for key, value := range values {
+		missing := ValueDiffUnknown
+		if key == "global" {
+			missing = ValueDiffAdded
+		}
+		for _, subchart := range subcharts {
+			if key == subchart {
+				missing = ValueDiffAdded
+			}
+		}
+		defaultValue, exists := defaults[key]
+		result = append(result, diffValue(escapeHelmSetKey(key), value, defaultValue, exists, missing)...)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+func diffValue(path string, value interface{}, defaultValue interface{}, exists bool, missing string) []ValueDiff {
+	valueMap, isMap := value.(map[string]interface{})
+	defaultMap, isDefaultMap := defaultValue.(map[string]interface{})
+	if isMap && len(valueMap) > 0 && (!exists || isDefaultMap) {
+		nestedMissing := missing
+		if exists {
+			nestedMissing = ValueDiffUnknown
+			if len(defaultMap) == 0 {
+				nestedMissing = ValueDiffAdded
+			}
+		}
+		result := []ValueDiff{}
+		for key, nestedValue := range valueMap {
+			nestedDefaultValue, nestedExists := defaultMap[key]
+			result = append(result, diffValue(path+"."+escapeHelmSetKey(key), nestedValue, nestedDefaultValue, exists && nestedExists, nestedMissing)...)
+		}
+		return result
+	}
+	diff := ValueDiff{Key: path, Value: formatExplainedValue(value), Status: missing, Default: "-"}
+	if exists {
+		diff.Default = formatExplainedValue(defaultValue)
+		diff.Status = ValueDiffChanged
+		if diff.Default == diff.Value {
+			diff.Status = ValueDiffUnchanged
+		}
+	}
+	return []ValueDiff{diff}
+}
+
+func FormatValuesDiff(charts []ChartValuesDiff) string {
+	result := strings.Builder{}
+	for i, chart := range charts {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+		result.WriteString(fmt.Sprintf("# %s %s\n", chart.Chart, chart.Version))
+		w := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tSTATUS\tVALUE\tDEFAULT")
+		for _, value := range chart.Values {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", value.Key, value.Status, value.Value, value.Default)
+		}
+		w.Flush()
+	}
+	return result.String()
+}
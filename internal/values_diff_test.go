@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffChartValues(t *testing.T) {
+	defaults := map[string]interface{}{
+		"replicaCount":   1,
+		"image":          map[string]interface{}{"repository": "nginx", "tag": ""},
+		"podAnnotations": map[string]interface{}{},
+		"resources":      map[string]interface{}{"limits": map[string]interface{}{"cpu": "100m"}},
+	}
+	values := map[string]interface{}{
+		"replicaCount":   1,
+		"image":          map[string]interface{}{"tag": "1.25", "pullSecret": "registry"},
+		"podAnnotations": map[string]interface{}{"team": "platform"},
+		"resources":      map[string]interface{}{},
+		"ingres":         map[string]interface{}{"enabled": true},
+		"global":         map[string]interface{}{"domain": "example.com"},
+		"redis":          map[string]interface{}{"enabled": false},
+	}
+	assert.Equal(t, []ValueDiff{
+		{Key: "global.domain", Status: ValueDiffAdded, Value: `"example.com"`, Default: "-"},
+		{Key: "image.pullSecret", Status: ValueDiffUnknown, Value: `"registry"`, Default: "-"},
+		{Key: "image.tag", Status: ValueDiffChanged, Value: `"1.25"`, Default: `""`},
+		{Key: "ingres.enabled", Status: ValueDiffUnknown, Value: "true", Default: "-"},
+		{Key: "podAnnotations.team", Status: ValueDiffAdded, Value: `"platform"`, Default: "-"},
+		{Key: "redis.enabled", Status: ValueDiffAdded, Value: "false", Default: "-"},
+		{Key: "replicaCount", Status: ValueDiffUnchanged, Value: "1", Default: "1"},
+		{Key: "resources", Status: ValueDiffChanged, Value: "{}", Default: `{"limits":{"cpu":"100m"}}`},
+	}, diffChartValues(values, defaults, []string{"redis"}))
+}
+
+func TestFormatValuesDiff(t *testing.T) {
+	output := FormatValuesDiff([]ChartValuesDiff{{Chart: "app", Version: "1.2.3", Values: []ValueDiff{{Key: "image.tag", Status: ValueDiffChanged, Value: `"1.25"`, Default: `""`}}}})
+	assert.Equal(t, "# app 1.2.3\nKEY        STATUS   VALUE   DEFAULT\nimage.tag  changed  \"1.25\"  \"\"\n", output)
+}
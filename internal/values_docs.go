@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const valuesDocsFile = "VALUES.md"
+
+type ChartValuesDocs struct {
+	Chart   string
+	Version string
+	Values  string
+	Table   string
+}
+
+func ValuesDocs(dir string, opts RunOptions) ([]ChartValuesDocs, error) {
+	config, err := loadRunConfig(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	generators := collectHelmGenerators(config.Generator)
+	if len(generators) == 0 {
+		return nil, configErrorf("values docs are only supported for helm charts")
+	}
+	ctx, err := newGeneratorContext(dir, *config, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := []ChartValuesDocs{}
+	for _, generator := range generators {
+		docs, err := generator.valuesDocs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *docs)
+	}
+	return result, nil
+}
+
+func (g HelmGenerator) valuesDocs(ctx GeneratorContext) (*ChartValuesDocs, error) {
+	var docs *ChartValuesDocs
+	err := g.withChartDir(ctx, func(chartDir string, source GeneratorSource) error {
+		values, err := readOptionalFile(filepath.Join(chartDir, "values.yaml"))
+		if err != nil {
+			return fmt.Errorf("reading chart values failed: %v", err)
+		}
+		readme, err := readOptionalFile(filepath.Join(chartDir, "README.md"))
+		if err != nil {
+			return fmt.Errorf("reading chart readme failed: %v", err)
+		}
+		docs = &ChartValuesDocs{
+			Chart:   source.Chart,
+			Version: source.Version,
+			Values:  values,
+			Table:   extractValuesTable(readme),
+		}
+		return nil
+	})
+	return docs, err
+}
+
+func (g HelmGenerator) withChartDir(ctx GeneratorContext, fn func(chartDir string, source GeneratorSource) error) error {
+	helmPath, err := g.resolveHelm(ctx)
+	if err != nil {
+		return err
+	}
+	chart, err := g.resolveChart(ctx, helmPath)
+	if err != nil {
+		return err
+	}
+	defer chart.Cleanup()
+	chartDir := chart.LocalDir
+	if chartDir == "" {
+		done := ctx.Phase("chart pull")
+		pulledChartDir, cleanup, err := pullHelmChart(ctx, helmPath, chart.Args)
+		done()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		chartDir = pulledChartDir
+	}
+	return fn(chartDir, chart.Source)
+}
+
+func readOptionalFile(file string) (string, error) {
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return string(content), err
+}
+
+func extractValuesTable(readme string) string {
+	tables := []string{}
+	lines := strings.Split(strings.ReplaceAll(readme, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		if !isValuesTableHeader(lines[i]) || i+1 >= len(lines) || !strings.HasPrefix(strings.TrimSpace(lines[i+1]), "|") {
+			continue
+		}
+		table := []string{}
+		for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+			table = append(table, strings.TrimSpace(lines[i]))
+		}
+		tables = append(tables, strings.Join(table, "\n"))
+	}
+	return strings.Join(tables, "\n\n")
+}
+
+func isValuesTableHeader(line string) bool {
+	line = strings.ToLower(strings.TrimSpace(line))
+	if !strings.HasPrefix(line, "|") || !strings.Contains(line, "default") {
+		return false
+	}
+	return strings.Contains(line, "key") || strings.Contains(line, "parameter") || strings.Contains(line, "value")
+}
+
+func FormatValuesDocs(docs []ChartValuesDocs) string {
+	result := strings.Builder{}
+	for i, doc := range docs {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+		result.WriteString(fmt.Sprintf("# %s %s\n", doc.Chart, doc.Version))
+		if doc.Table != "" {
+			result.WriteString("\n## Parameters\n\n")
+			result.WriteString(doc.Table + "\n")
+		}
+		result.WriteString("\n## Default values\n\n```yaml\n")
+		if doc.Values != "" {
+			result.WriteString(strings.TrimRight(doc.Values, "\n") + "\n")
+		}
+		result.WriteString("```\n")
+	}
+	return result.String()
+}
+
+func WriteValuesDocs(dir string, docs []ChartValuesDocs) (string, error) {
+	file := filepath.Join(dir, valuesDocsFile)
+	err := os.WriteFile(file, []byte(FormatValuesDocs(docs)), 0o644)
+	if err != nil {
+		return "", fmt.Errorf("writing values docs failed: %v", err)
+	}
+	return file, nil
+}
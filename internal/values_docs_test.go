@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValuesDocs(t *testing.T) {
+	helm := fakeHelm(t, "")
+	chartDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.yaml"), []byte("name: app\nversion: 1.2.3\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "values.yaml"), []byte("replicaCount: 1\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, "README.md"), []byte(`# app
+
+Some introduction.
+
+| Name | Description |
+|------|-------------|
+| app  | not a values table |
+
+## Parameters
+
+| Key | Type | Default | Description |
+|-----|------|---------|-------------|
+| replicaCount | int | `+"`1`"+` | Number of replicas |
+
+Trailing text.
+`), 0o644))
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte("type: helm\nchart: "+chartDir+"\n"), 0o644))
+	docs, err := ValuesDocs(dir, RunOptions{HelmBin: helm})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []ChartValuesDocs{{
+			Chart:   "app",
+			Version: "1.2.3",
+			Values:  "replicaCount: 1\n",
+			Table:   "| Key | Type | Default | Description |\n|-----|------|---------|-------------|\n| replicaCount | int | `1` | Number of replicas |",
+		}}, docs)
+
+		file, err := WriteValuesDocs(dir, docs)
+		assert.NoError(t, err)
+		content, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.Equal(t, "# app 1.2.3\n\n## Parameters\n\n| Key | Type | Default | Description |\n|-----|------|---------|-------------|\n| replicaCount | int | `1` | Number of replicas |\n\n## Default values\n\n```yaml\nreplicaCount: 1\n```\n", string(content))
+
+		files, _, err := listOutputFiles(os.DirFS(dir), nil)
+		assert.NoError(t, err)
+		assert.Empty(t, files)
+	}
+}
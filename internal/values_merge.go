@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"sort"
+)
+
+const (
+	listMergeReplace = "replace"
+	listMergeAppend  = "append"
+	listMergePrepend = "prepend"
+)
+
+func validateListMerge(listMerge map[string]string) error {
+	paths := []string{}
+	for path := range listMerge {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		strategy := listMerge[path]
+		if strategy != listMergeReplace && strategy != listMergeAppend && strategy != listMergePrepend {
+			return configErrorf("unsupported list merge strategy %s for %s (expected replace, append or prepend)", strategy, path)
+		}
+	}
+	return nil
+}
+
+func mergeValuesWithListMerge(defaults map[string]interface{}, values map[string]interface{}, listMerge map[string]string, prefix string) map[string]interface{} {
+	result := copyValues(defaults)
+	for key, value := range values {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		path := prefix + key
+		nested, ok := value.(map[string]interface{})
+		existing, existingOk := result[key].(map[string]interface{})
+		if ok && existingOk {
+			result[key] = mergeValuesWithListMerge(existing, nested, listMerge, path+".")
+			continue
+		}
+		list, ok := value.([]interface{})
+		existingList, existingListOk := result[key].([]interface{})
+		if ok && existingListOk {
+			switch listMerge[path] {
+			case listMergeAppend:
+				result[key] = append(append([]interface{}{}, existingList...), list...)
+				continue
+			case listMergePrepend:
+				result[key] = append(append([]interface{}{}, list...), existingList...)
+				continue
+			}
+		}
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+const valuesSchemaFile = "values.schema.json"
+
+func pullHelmChart(ctx GeneratorContext, helmPath string, chartArgs []string) (string, func(), error) {
+	chartDir, _, cleanup, err := pullHelmChartOutput(ctx, helmPath, chartArgs)
+	return chartDir, cleanup, err
+}
+
+func pullHelmChartOutput(ctx GeneratorContext, helmPath string, chartArgs []string) (string, []byte, func(), error) {
+	tempDir, err := os.MkdirTemp(ctx.TempDir, ".kustomization-generator-*-chart")
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("pulling chart failed: %v", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+	stdout, stderr, err := ctx.runCommand(*exec.Command(helmPath, append(append([]string{"pull"}, chartArgs...), "--untar", "--untardir", tempDir)...))
+	if err != nil {
+		cleanup()
+		return "", nil, nil, executionErrorf("executing helm failed: %v\n%s", err, string(stderr))
+	}
+	chartDir, err := findPulledChartDir(tempDir)
+	if err != nil {
+		cleanup()
+		return "", nil, nil, executionErrorf("pulling chart failed: %v", err)
+	}
+	return chartDir, append(stdout, stderr...), cleanup, nil
+}
+
+func findPulledChartDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	dirs := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	if len(dirs) != 1 {
+		return "", fmt.Errorf("expected a single chart directory but found %d", len(dirs))
+	}
+	return filepath.Join(dir, dirs[0]), nil
+}
+
+func checkHelmValuesSchema(chartDir string, values map[string]interface{}) error {
+	schemaBytes, err := os.ReadFile(filepath.Join(chartDir, valuesSchemaFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading values schema failed: %v", err)
+	}
+	defaults := map[string]interface{}{}
+	defaultsBytes, err := os.ReadFile(filepath.Join(chartDir, "values.yaml"))
+	if err == nil {
+		err = yaml.Unmarshal(defaultsBytes, &defaults)
+		if err != nil {
+			return executionErrorf("parsing chart default values failed: %v", err)
+		}
+	}
+
+	violations, err := validateValuesSchema(schemaBytes, mergeValues(defaults, values))
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return configErrorf("values do not match chart schema:\n%s", strings.Join(violations, "\n"))
+	}
+	return nil
+}
+
+func validateValuesSchema(schemaBytes []byte, values map[string]interface{}) ([]string, error) {
+	compiler := jsonschema.NewCompiler()
+	err := compiler.AddResource(valuesSchemaFile, bytes.NewReader(schemaBytes))
+	if err != nil {
+		return nil, configErrorf("reading values schema failed: %v", err)
+	}
+	schema, err := compiler.Compile(valuesSchemaFile)
+	if err != nil {
+		return nil, configErrorf("reading values schema failed: %v", err)
+	}
+
+	valuesJson, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("converting values failed: %v", err)
+	}
+	var instance interface{}
+	decoder := json.NewDecoder(bytes.NewReader(valuesJson))
+	decoder.UseNumber()
+	err = decoder.Decode(&instance)
+	if err != nil {
+		return nil, fmt.Errorf("converting values failed: %v", err)
+	}
+	err = schema.Validate(instance)
+	if err == nil {
+		return nil, nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, fmt.Errorf("validating values failed: %v", err)
+	}
+
+	violations := []string{}
+	var collect func(e *jsonschema.ValidationError)
+	collect = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := e.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			violations = append(violations, fmt.Sprintf("%s: %s", location, e.Message))
+			return
+		}
+		for _, cause := range e.Causes {
+			collect(cause)
+		}
+	}
+	collect(validationErr)
+	sort.Strings(violations)
+	return violations, nil
+}
+
+func (g HelmGenerator) applySetValues(ctx GeneratorContext, values map[string]interface{}) map[string]interface{} {
+	result := mergeValues(values, g.Set)
+	result = mergeValues(result, stringifyValues(g.SetString))
+	return mergeValues(result, readSetFiles(absolutizePaths(ctx.Dir, g.SetFile)))
+}
+
+func stringifyValues(values map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range values {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			result[key] = stringifyValues(v)
+		case nil:
+			result[key] = ""
+		default:
+			result[key] = fmt.Sprintf("%v", v)
+		}
+	}
+	return result
+}
+
+func readSetFiles(values map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range values {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			result[key] = readSetFiles(v)
+		case string:
+			if bytes, err := os.ReadFile(v); err == nil {
+				result[key] = string(bytes)
+			}
+		}
+	}
+	return result
+}
+
+func mergeValues(defaults map[string]interface{}, values map[string]interface{}) map[string]interface{} {
+	return mergeValuesWithListMerge(defaults, values, nil, "")
+}
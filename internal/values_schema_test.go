@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeValues(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.25"},
+		"replicas": 3,
+	}, mergeValues(map[string]interface{}{
+		"image":     map[string]interface{}{"repository": "nginx", "tag": "latest"},
+		"replicas":  1,
+		"resources": map[string]interface{}{},
+	}, map[string]interface{}{
+		"image":     map[string]interface{}{"tag": "1.25"},
+		"replicas":  3,
+		"resources": nil,
+	}))
+}
+
+func TestValidateValuesSchema(t *testing.T) {
+	schema := []byte(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["image"],
+  "properties": {
+    "image": {
+      "type": "object",
+      "properties": {
+        "tag": {"type": "string"}
+      }
+    },
+    "replicas": {"type": "integer", "minimum": 1}
+  }
+}`)
+	violations, err := validateValuesSchema(schema, map[string]interface{}{
+		"image":    map[string]interface{}{"tag": "1.25"},
+		"replicas": 2,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+
+	violations, err = validateValuesSchema(schema, map[string]interface{}{
+		"image":    map[string]interface{}{"tag": 1.25},
+		"replicas": 0,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/image/tag: expected string, but got number",
+		"/replicas: must be >= 1 but found 0",
+	}, violations)
+
+	violations, err = validateValuesSchema(schema, map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/: missing properties: 'image'"}, violations)
+}
+
+func TestFindPulledChartDir(t *testing.T) {
+	dir := t.TempDir()
+	_, err := findPulledChartDir(dir)
+	assert.EqualError(t, err, "expected a single chart directory but found 0")
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "actual-chart-name"), 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, ".cache"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), []byte(""), 0o644))
+	chartDir, err := findPulledChartDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "actual-chart-name"), chartDir)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "other"), 0o755))
+	_, err = findPulledChartDir(dir)
+	assert.EqualError(t, err, "expected a single chart directory but found 2")
+}
+
+func TestCheckHelmValuesSchemaWithSetValues(t *testing.T) {
+	chartDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(chartDir, valuesSchemaFile), []byte(`{
+  "type": "object",
+  "required": ["image", "token", "config"],
+  "properties": {
+    "image": {"type": "object", "required": ["tag"], "properties": {"tag": {"type": "string"}}},
+    "token": {"type": "string"},
+    "config": {"type": "string"}
+  }
+}`), 0o644))
+	configDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(configDir, "config.toml"), []byte("debug = true\n"), 0o644))
+	ctx := GeneratorContext{Dir: configDir}
+
+	g := HelmGenerator{
+		Set:       map[string]interface{}{"image": map[string]interface{}{"tag": "1.25"}},
+		SetString: map[string]interface{}{"token": 1234},
+		SetFile:   map[string]interface{}{"config": "config.toml"},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"image":    map[string]interface{}{"tag": "1.25"},
+		"replicas": 2,
+		"token":    "1234",
+		"config":   "debug = true\n",
+	}, g.applySetValues(ctx, map[string]interface{}{"replicas": 2}))
+	assert.NoError(t, checkHelmValuesSchema(chartDir, g.applySetValues(ctx, map[string]interface{}{})))
+
+	err := checkHelmValuesSchema(chartDir, HelmGenerator{}.applySetValues(ctx, map[string]interface{}{}))
+	if assert.Error(t, err) {
+		assert.Equal(t, 2, ExitCode(err))
+	}
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestFindUnknownValueKeys(t *testing.T) {
+	defaults := map[string]interface{}{
+		"ingress": map[string]interface{}{
+			"enabled": false,
+			"hosts":   []interface{}{},
+		},
+		"podAnnotations": map[string]interface{}{},
+		"postgresql": map[string]interface{}{
+			"enabled": true,
+		},
+	}
+	values := map[string]interface{}{
+		"ingres": map[string]interface{}{"enabled": true},
+		"ingress": map[string]interface{}{
+			"enabled": true,
+			"host":    "domain.com",
+		},
+		"podAnnotations": map[string]interface{}{"foo": "bar"},
+		"postgresql":     map[string]interface{}{"enabled": false, "auth": map[string]interface{}{}},
+		"redis":          map[string]interface{}{"enabled": true},
+		"global":         map[string]interface{}{"imageRegistry": "mirror"},
+	}
+	assert.Equal(t, []string{"ingres", "ingress.host", "postgresql.auth", "redis"}, findUnknownValueKeys(values, defaults, nil))
+	assert.Equal(t, []string{"ingres", "ingress.host"}, findUnknownValueKeys(values, defaults, []string{"postgresql", "redis"}))
+}
+
+func TestMergeStringValues(t *testing.T) {
+	values := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        1.2,
+		},
+	}
+	actual := mergeStringValues(values, map[string]string{
+		"image.tag":                              "1.20",
+		"podAnnotations.kubernetes\\.io/enabled": "on",
+	})
+	assert.Equal(t, map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.20",
+		},
+		"podAnnotations": map[string]interface{}{
+			"kubernetes.io/enabled": "on",
+		},
+	}, actual)
+	assert.Equal(t, 1.2, values["image"].(map[string]interface{})["tag"])
+
+	bytes, err := yaml.Marshal(actual)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), `tag: "1.20"`)
+		assert.Contains(t, string(bytes), `kubernetes.io/enabled: "on"`)
+	}
+}